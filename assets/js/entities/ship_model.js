@@ -23,6 +23,12 @@ export class ShipModel {
     /** @type {number} */
     cargo_mass = 0;
 
+    /** @type {number} */
+    shield_mass = 0;
+
+    /** @type {number} */
+    armour_mass = 0;
+
     /**
      * @param {Object} data
      * @returns {ShipModel}
@@ -36,6 +42,8 @@ export class ShipModel {
         this.defense_mass = data.defense_mass ?? 0;
         this.engine_mass = data.engine_mass ?? 0;
         this.cargo_mass = data.cargo_mass ?? 0;
+        this.shield_mass = data.shield_mass ?? 0;
+        this.armour_mass = data.armour_mass ?? 0;
         return this;
     }
 }
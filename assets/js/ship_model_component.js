@@ -22,7 +22,7 @@ export class ShipModelComponent {
     renderList(models) {
         const thead = document.createElement('thead');
         const headerRow = document.createElement('tr');
-        ['', 'ID', 'Name', 'Guns', 'Gun Mass', 'Defense Mass', 'Engine Mass', 'Cargo Mass'].forEach(label => {
+        ['', 'ID', 'Name', 'Guns', 'Gun Mass', 'Defense Mass', 'Engine Mass', 'Cargo Mass', 'Shield Mass', 'Armour Mass'].forEach(label => {
             const th = document.createElement('th');
             th.appendChild(document.createTextNode(label));
             headerRow.appendChild(th);
@@ -55,7 +55,7 @@ export class ShipModelComponent {
             tdName.appendChild(linkName);
             tr.appendChild(tdName);
 
-            ['guns', 'one_gun_mass', 'defense_mass', 'engine_mass', 'cargo_mass'].forEach(key => {
+            ['guns', 'one_gun_mass', 'defense_mass', 'engine_mass', 'cargo_mass', 'shield_mass', 'armour_mass'].forEach(key => {
                 const td = document.createElement('td');
                 td.appendChild(document.createTextNode(m[key]));
                 tr.appendChild(td);
@@ -85,6 +85,8 @@ export class ShipModelComponent {
             ['Defense Mass', m.defense_mass],
             ['Engine Mass', m.engine_mass],
             ['Cargo Mass', m.cargo_mass],
+            ['Shield Mass', m.shield_mass],
+            ['Armour Mass', m.armour_mass],
             ['Owner ID', m.owner_id],
         ].forEach(([label, value]) => {
             const tr = document.createElement('tr');
@@ -117,6 +119,8 @@ export class ShipModelComponent {
             ['Defense Mass', 'defense_mass', 'number', m.defense_mass],
             ['Engine Mass', 'engine_mass', 'number', m.engine_mass],
             ['Cargo Mass', 'cargo_mass', 'number', m.cargo_mass],
+            ['Shield Mass', 'shield_mass', 'number', m.shield_mass],
+            ['Armour Mass', 'armour_mass', 'number', m.armour_mass],
         ].forEach(([label, name, type, value]) => {
             const tr = document.createElement('tr');
             const th = document.createElement('th');
@@ -146,7 +150,7 @@ export class ShipModelComponent {
             e.preventDefault();
             const data = Object.fromEntries(new FormData(form));
             data.guns = parseInt(data.guns);
-            ['one_gun_mass', 'defense_mass', 'engine_mass', 'cargo_mass'].forEach(k => data[k] = parseFloat(data[k]));
+            ['one_gun_mass', 'defense_mass', 'engine_mass', 'cargo_mass', 'shield_mass', 'armour_mass'].forEach(k => data[k] = parseFloat(data[k]));
             await this.apiClient.updateShipModel(shipModelId, data);
             location.href = `/ship-model/${shipModelId}/details.html`;
         });
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/util"
+)
+
+// Sweeps the tuned numbers of the game and fights the AI archetypes against each other with every combination, e.g.
+//
+//	go run ./cmd/balance -tech-costs 50,100,200 -defense-exponents 0.4,0.5,0.6 -battles 100 > balance.csv
+//	go run ./cmd/balance -destruction-curves "0.25:1,1:0.5,4:0;0.5:1,2:0" -budgets 300,1000 -format json
+//
+// The rule sets where one archetype wins above the threshold are reported to stderr.
+func main() {
+	techCosts := flag.String("tech-costs", "", "comma separated resources of one technology level")
+	defenseExponents := flag.String("defense-exponents", "", "comma separated exponents of the ship mass in the defense formula")
+	destructionCurves := flag.String("destruction-curves", "", "semicolon separated destruction curves of comma separated ratio:chance points")
+	budgets := flag.String("budgets", "", "comma separated resources of the fleets")
+	battles := flag.Int("battles", 50, "battles of every pair of the archetypes")
+	seed := flag.Uint64("seed", 1, "seed of the battles")
+	threshold := flag.Float64("threshold", game.BALANCE_THRESHOLD, "win rate of an archetype which makes a rule set unbalanced")
+	format := flag.String("format", "csv", "csv or json")
+	out := flag.String("out", "", "output file, stdout when not set")
+	flag.Parse()
+
+	sweep := game.BalanceSweep{}
+	var err error
+	sweep.TechCosts, err = parseFloats(*techCosts)
+	exitOnError(err)
+	sweep.DefenseExponents, err = parseFloats(*defenseExponents)
+	exitOnError(err)
+	sweep.DestructionCurves, err = parseCurves(*destructionCurves)
+	exitOnError(err)
+	sweep.Budgets, err = parseInts(*budgets)
+	exitOnError(err)
+	if *format != "csv" && *format != "json" {
+		exitOnError(fmt.Errorf("unknown format %q", *format))
+	}
+
+	analyzer := game.NewBalanceAnalyzer(game.DefaultBattleLimits(), &util.SimpleIdGenerator{}, *battles, *seed, *threshold)
+	reports, err := analyzer.Sweep(sweep, func(done int, total int) {
+		fmt.Fprintf(os.Stderr, "\rrule set %d/%d", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	exitOnError(err)
+
+	writer := io.Writer(os.Stdout)
+	if *out != "" {
+		file, err := os.Create(*out)
+		exitOnError(err)
+		defer file.Close()
+		writer = file
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		exitOnError(encoder.Encode(reports))
+	} else {
+		exitOnError(writeCSV(writer, reports))
+	}
+
+	printSummary(reports, *threshold)
+}
+
+// writeCSV writes the win rate matrices of the rule sets one under another, a row per archetype.
+func writeCSV(writer io.Writer, reports []*game.BalanceReport) error {
+	csvWriter := csv.NewWriter(writer)
+	for i, report := range reports {
+		if i == 0 {
+			header := []string{"tech_cost", "defense_exponent", "destruction", "budget", "archetype"}
+			for _, opponent := range report.Archetypes {
+				header = append(header, "vs_"+opponent)
+			}
+			header = append(header, "win_rate", "dominant", "unbalanced")
+			if err := csvWriter.Write(header); err != nil {
+				return err
+			}
+		}
+
+		for row, archetype := range report.Archetypes {
+			record := []string{
+				formatFloat(report.Rules.TechCost),
+				formatFloat(report.Rules.DefenseExponent),
+				formatCurve(report.Rules.Destruction),
+				strconv.Itoa(report.Rules.Budget),
+				archetype,
+			}
+			for _, winRate := range report.WinRates[row] {
+				record = append(record, formatFloat(winRate))
+			}
+			record = append(record,
+				formatFloat(report.Strategies[row].WinRate),
+				strconv.FormatBool(report.Dominant == archetype),
+				strconv.FormatBool(report.Unbalanced && report.Dominant == archetype))
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// printSummary reports the unbalanced rule sets and how often every archetype dominates.
+func printSummary(reports []*game.BalanceReport, threshold float64) {
+	dominant := map[string]int{}
+	unbalanced := 0
+	for _, report := range reports {
+		dominant[report.Dominant]++
+		if !report.Unbalanced {
+			continue
+		}
+
+		unbalanced++
+		for _, strategy := range report.Strategies {
+			if strategy.Archetype == report.Dominant {
+				fmt.Fprintf(os.Stderr, "UNBALANCED tech_cost=%g defense_exponent=%g destruction=%s budget=%d: %s wins %.0f%%\n",
+					report.Rules.TechCost, report.Rules.DefenseExponent, formatCurve(report.Rules.Destruction), report.Rules.Budget,
+					strategy.Archetype, strategy.WinRate*100)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d of %d rule sets have an archetype winning above %.0f%%\n", unbalanced, len(reports), threshold*100)
+	if len(reports) > 0 {
+		for _, archetype := range reports[0].Archetypes {
+			fmt.Fprintf(os.Stderr, "%s dominates %d rule sets\n", archetype, dominant[archetype])
+		}
+	}
+}
+
+func parseFloats(value string) ([]float64, error) {
+	var values []float64
+	for _, field := range splitList(value, ",") {
+		number, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, number)
+	}
+
+	return values, nil
+}
+
+func parseInts(value string) ([]int, error) {
+	var values []int
+	for _, field := range splitList(value, ",") {
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, number)
+	}
+
+	return values, nil
+}
+
+// parseCurves parses curves like "0.25:1,1:0.5,4:0;0.5:1,2:0".
+func parseCurves(value string) ([]game.DestructionCurve, error) {
+	var curves []game.DestructionCurve
+	for _, curveValue := range splitList(value, ";") {
+		curve := game.DestructionCurve{}
+		for _, point := range splitList(curveValue, ",") {
+			ratio, chance, found := strings.Cut(point, ":")
+			if !found {
+				return nil, fmt.Errorf("destruction curve point %q is not ratio:chance", point)
+			}
+			values, err := parseFloats(ratio + "," + chance)
+			if err != nil {
+				return nil, err
+			}
+			curve.Ratios = append(curve.Ratios, values[0])
+			curve.Chances = append(curve.Chances, values[1])
+		}
+		curves = append(curves, curve)
+	}
+
+	return curves, nil
+}
+
+func splitList(value string, separator string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, separator) {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', 4, 64)
+}
+
+func formatCurve(curve game.DestructionCurve) string {
+	points := make([]string, len(curve.Ratios))
+	for i := range curve.Ratios {
+		points[i] = formatFloat(curve.Ratios[i]) + ":" + formatFloat(curve.Chances[i])
+	}
+
+	return strings.Join(points, " ")
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
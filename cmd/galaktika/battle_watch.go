@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"glaktika.eu/galaktika/pkg/galaxy"
+)
+
+// WatchOptions set the pace of the battle watch.
+type WatchOptions struct {
+	// delay between the replayed shots
+	Interval time.Duration
+	// delay between the requests while the battle is not fought yet
+	Poll time.Duration
+	// time to wait for the battle
+	Timeout time.Duration
+}
+
+// WatchedShot is a replayed shot with the ships left on both sides, written as a JSON line by the json output.
+type WatchedShot struct {
+	Number      int    `json:"number"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Destroyed   bool   `json:"destroyed"`
+	AliveA      int    `json:"alive_a"`
+	AliveB      int    `json:"alive_b"`
+}
+
+// watchBattle waits until the battle is fought, e.g. by the next turn or a queued match,
+// and replays its shots one by one.
+func watchBattle(app *App, id string, options WatchOptions) error {
+	battle, err := waitForBattle(app.client, id, options)
+	if err != nil {
+		return err
+	}
+
+	sideA := map[string]bool{}
+	for _, ship := range battle.SideA.Ships {
+		sideA[ship.ID] = true
+	}
+	aliveA, aliveB := fleetShips(battle.SideA), fleetShips(battle.SideB)
+
+	encoder := json.NewEncoder(app.out)
+	if app.printer.format != OUTPUT_JSON {
+		fmt.Fprintf(app.out, "Battle %s: %s (%d ships) vs %s (%d ships), %d shots\n",
+			battle.ID, fleetOwner(battle.SideA), aliveA, fleetOwner(battle.SideB), aliveB, len(battle.Shots))
+	}
+
+	for i, shot := range battle.Shots {
+		if shot.Result {
+			if sideA[shot.Destination] {
+				aliveA--
+			} else {
+				aliveB--
+			}
+		}
+
+		watched := WatchedShot{Number: i + 1, Source: shot.Source, Destination: shot.Destination, Destroyed: shot.Result, AliveA: aliveA, AliveB: aliveB}
+		if app.printer.format == OUTPUT_JSON {
+			if err := encoder.Encode(watched); err != nil {
+				return err
+			}
+		} else {
+			result := "missed"
+			if shot.Result {
+				result = "destroyed"
+			}
+			fmt.Fprintf(app.out, "%5d  %s -> %s  %-9s  A %d | B %d\n", watched.Number, shot.Source, shot.Destination, result, aliveA, aliveB)
+		}
+
+		if options.Interval > 0 && i < len(battle.Shots)-1 {
+			time.Sleep(options.Interval)
+		}
+	}
+
+	if app.printer.format == OUTPUT_JSON {
+		return nil
+	}
+
+	survivorsA, survivorsB := battle.Survivors()
+	switch galaxy.BattleScore(battle) {
+	case galaxy.SCORE_WIN:
+		fmt.Fprintf(app.out, "%s wins with %d ships\n", fleetOwner(battle.SideA), survivorsA)
+	case galaxy.SCORE_LOSS:
+		fmt.Fprintf(app.out, "%s wins with %d ships\n", fleetOwner(battle.SideB), survivorsB)
+	default:
+		fmt.Fprintf(app.out, "Draw, %d:%d ships survived\n", survivorsA, survivorsB)
+	}
+
+	return nil
+}
+
+// waitForBattle polls the battle until it is stored or the timeout passes.
+func waitForBattle(client *Client, id string, options WatchOptions) (*galaxy.Battle, error) {
+	deadline := time.Now().Add(options.Timeout)
+	waiting := false
+
+	for {
+		battle := &galaxy.Battle{}
+		err := client.get("/battle?id="+url.QueryEscape(id), battle)
+
+		var apiError *APIError
+		switch {
+		case err == nil:
+			if battle.SideA == nil || battle.SideB == nil {
+				return nil, fmt.Errorf("battle %s has no sides", id)
+			}
+			return battle, nil
+		case !errors.As(err, &apiError) || apiError.Status != http.StatusNotFound:
+			return nil, err
+		case time.Now().Add(options.Poll).After(deadline):
+			return nil, fmt.Errorf("battle %s was not fought within %s", id, options.Timeout)
+		}
+
+		if !waiting {
+			fmt.Fprintf(os.Stderr, "Waiting for battle %s...\n", id)
+			waiting = true
+		}
+		time.Sleep(options.Poll)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIError is an error response of the server.
+type APIError struct {
+	Status  int
+	Message string
+	// violations of a ship model or a scenario validation
+	Violations []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+	// archived ids already stored by the server
+	Conflicts []string
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("%d %s", e.Status, e.Message)
+	for _, violation := range e.Violations {
+		message += fmt.Sprintf("\n  %s: %s", violation.Field, violation.Message)
+	}
+	for _, conflict := range e.Conflicts {
+		message += "\n  " + conflict
+	}
+
+	return message
+}
+
+// Client calls the REST API of the server with the token of the profile.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL string, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends the body as JSON and decodes the response into out, out may be nil.
+func (client *Client) do(method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, client.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if client.token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 300 {
+		apiError := &APIError{Status: response.StatusCode}
+		var errorBody struct {
+			Error      string          `json:"error"`
+			Violations json.RawMessage `json:"violations"`
+			Conflicts  []string        `json:"conflicts"`
+		}
+		if json.Unmarshal(data, &errorBody) == nil && errorBody.Error != "" {
+			apiError.Message = errorBody.Error
+			_ = json.Unmarshal(errorBody.Violations, &apiError.Violations)
+			apiError.Conflicts = errorBody.Conflicts
+		} else {
+			apiError.Message = strings.TrimSpace(string(data))
+		}
+		return apiError
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func (client *Client) get(path string, out any) error {
+	return client.do(http.MethodGet, path, nil, out)
+}
+
+func (client *Client) post(path string, body any, out any) error {
+	return client.do(http.MethodPost, path, body, out)
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"glaktika.eu/galaktika/pkg/galaxy"
+)
+
+var commands = map[string]map[string]Command{
+	"config": {
+		"set":  {usage: "<profile> [-url URL] [-token TOKEN] [-use]", run: configSet},
+		"use":  {usage: "<profile>", run: configUse},
+		"show": {usage: "", run: configShow},
+	},
+	"division": {
+		"list":   {usage: "", run: divisionList},
+		"get":    {usage: "<id>", run: divisionGet},
+		"export": {usage: "<id> [-out FILE]", run: divisionExport},
+		"import": {usage: "-file <archive.json|-> [-division ID] [-conflict fail|rename|overwrite] [-race ARCHIVED:STORED]...", run: divisionImport},
+	},
+	"ship-model": {
+		"list":   {usage: "", run: shipModelList},
+		"get":    {usage: "<id>", run: shipModelGet},
+		"create": {usage: "-file <ship-model.json|->", run: shipModelCreate},
+	},
+	"fleet-build": {
+		"list":       {usage: "", run: fleetBuildList},
+		"get":        {usage: "<id>", run: fleetBuildGet},
+		"create":     {usage: "-division ID -race ID [-id ID] [-attack N] [-defense N] [-engine N] [-cargo N]", run: fleetBuildCreate},
+		"assign":     {usage: "<id> -ship-model ID -amount N [-version N]", run: fleetBuildAssign},
+		"statistics": {usage: "<id>", run: fleetBuildStatistics},
+		"build":      {usage: "<id>", run: fleetBuildBuild},
+	},
+	"fleet": {
+		"get":     {usage: "<id>", run: fleetGet},
+		"history": {usage: "<id>", run: fleetHistory},
+	},
+	"battle": {
+		"list":  {usage: "-division ID", run: battleList},
+		"get":   {usage: "<id>", run: battleGet},
+		"watch": {usage: "<id> [-interval DURATION] [-poll DURATION] [-timeout DURATION]", run: battleWatch},
+	},
+}
+
+func configSet(app *App, args []string) error {
+	flags := flag.NewFlagSet("config set", flag.ContinueOnError)
+	apiURL := flags.String("url", "", "API URL")
+	token := flags.String("token", "", "authentication token")
+	use := flags.Bool("use", false, "make the profile current")
+	name, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+
+	profile := app.config.profile(name)
+	if *apiURL != "" {
+		profile.URL = *apiURL
+	}
+	if *token != "" {
+		profile.Token = *token
+	}
+	app.config.Profiles[name] = &profile
+	if *use {
+		app.config.Current = name
+	}
+
+	return app.config.save()
+}
+
+func configUse(app *App, args []string) error {
+	name, err := parseID(flag.NewFlagSet("config use", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if _, ok := app.config.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	app.config.Current = name
+
+	return app.config.save()
+}
+
+func configShow(app *App, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	names := make([]string, 0, len(app.config.Profiles))
+	for name := range app.config.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	current := app.config.profileName(app.profileName)
+	rows := [][]string{}
+	profiles := map[string]Profile{}
+	for _, name := range names {
+		profile := *app.config.Profiles[name]
+		// the tokens are not shown
+		if profile.Token != "" {
+			profile.Token = "***"
+		}
+		profiles[name] = profile
+
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		rows = append(rows, []string{marker, name, profile.URL, profile.Token})
+	}
+
+	return app.printer.print(map[string]any{"current": current, "profiles": profiles}, []string{"", "PROFILE", "URL", "TOKEN"}, rows)
+}
+
+func divisionList(app *App, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	var divisions []*galaxy.Division
+	if err := app.client.get("/divisions", &divisions); err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, division := range divisions {
+		rows = append(rows, []string{division.ID, strconv.Itoa(division.ResourcesAmount),
+			fmt.Sprintf("%d/%d/%d/%d", division.TechAttack, division.TechDefense, division.TechEngines, division.TechCargo)})
+	}
+
+	return app.printer.print(divisions, []string{"ID", "RESOURCES", "TECH A/D/E/C"}, rows)
+}
+
+func divisionGet(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("division get", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var division galaxy.Division
+	if err := app.client.get("/divisions/"+url.PathEscape(id), &division); err != nil {
+		return err
+	}
+
+	return app.printer.printFields(division, [][2]string{
+		{"id", division.ID},
+		{"resources", strconv.Itoa(division.ResourcesAmount)},
+		{"tech attack", strconv.Itoa(division.TechAttack)},
+		{"tech defense", strconv.Itoa(division.TechDefense)},
+		{"tech engines", strconv.Itoa(division.TechEngines)},
+		{"tech cargo", strconv.Itoa(division.TechCargo)},
+	})
+}
+
+func divisionExport(app *App, args []string) error {
+	flags := flag.NewFlagSet("division export", flag.ContinueOnError)
+	out := flags.String("out", "", "archive file, stdout when not set")
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var archive json.RawMessage
+	if err := app.client.get("/divisions/"+url.PathEscape(id)+"/export", &archive); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := fmt.Fprintln(app.out, string(archive))
+		return err
+	}
+	if err := os.WriteFile(*out, archive, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(app.out, "Division %s exported to %s\n", id, *out)
+
+	return nil
+}
+
+// raceMapping collects the repeated -race flags.
+type raceMapping map[string]string
+
+func (mapping raceMapping) String() string {
+	return fmt.Sprint(map[string]string(mapping))
+}
+
+func (mapping raceMapping) Set(value string) error {
+	from, to, found := strings.Cut(value, ":")
+	if !found || from == "" || to == "" {
+		return fmt.Errorf("race mapping %q is not ARCHIVED:STORED", value)
+	}
+	mapping[from] = to
+
+	return nil
+}
+
+func divisionImport(app *App, args []string) error {
+	flags := flag.NewFlagSet("division import", flag.ContinueOnError)
+	file := flags.String("file", "", "archive file, - reads stdin")
+	divisionId := flags.String("division", "", "id of the imported division, the archived id when not set")
+	conflict := flags.String("conflict", galaxy.ARCHIVE_CONFLICT_FAIL, "handling of the stored ids: fail, rename or overwrite")
+	races := raceMapping{}
+	flags.Var(races, "race", "maps an archived race to a stored race, ARCHIVED:STORED, repeatable")
+	if positional, err := parseFlags(flags, args); err != nil || len(positional) != 0 || *file == "" {
+		return errUsage
+	}
+
+	data, err := readInput(*file)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("conflict", *conflict)
+	if *divisionId != "" {
+		query.Set("division_id", *divisionId)
+	}
+	for from, to := range races {
+		query.Add("race", from+":"+to)
+	}
+
+	var mapping galaxy.ArchiveMapping
+	if err := app.client.post("/divisions/import?"+query.Encode(), json.RawMessage(data), &mapping); err != nil {
+		return err
+	}
+
+	rows := [][]string{{"division", "", mapping.Division}}
+	for _, kind := range []struct {
+		name string
+		ids  map[string]string
+	}{
+		{"race", mapping.Races}, {"ship model", mapping.ShipModels}, {"fleet build", mapping.FleetBuilds},
+		{"planet", mapping.Planets}, {"fleet", mapping.Fleets}, {"battle", mapping.Battles},
+	} {
+		for _, archived := range slices.Sorted(maps.Keys(kind.ids)) {
+			rows = append(rows, []string{kind.name, archived, kind.ids[archived]})
+		}
+	}
+	for _, archived := range slices.Sorted(maps.Keys(mapping.ShipModelVersions)) {
+		versions := mapping.ShipModelVersions[archived]
+		for _, version := range slices.Sorted(maps.Keys(versions)) {
+			rows = append(rows, []string{"ship model version", fmt.Sprintf("%s v%d", archived, version), fmt.Sprintf("v%d", versions[version])})
+		}
+	}
+
+	return app.printer.print(mapping, []string{"KIND", "ARCHIVED", "STORED"}, rows)
+}
+
+// readInput reads the file, or stdin for -.
+func readInput(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(file)
+}
+
+func shipModelRows(shipModels []*galaxy.ShipModel) [][]string {
+	rows := [][]string{}
+	for _, shipModel := range shipModels {
+		modules := []string{}
+		for _, module := range shipModel.EffectiveModules() {
+			if module.Type == galaxy.MODULE_WEAPON {
+				modules = append(modules, fmt.Sprintf("%s %dx%s", module.Type, module.Count, formatNumber(module.Mass)))
+			} else {
+				modules = append(modules, fmt.Sprintf("%s %s", module.Type, formatNumber(module.Mass)))
+			}
+		}
+		rows = append(rows, []string{shipModel.ID, strconv.Itoa(shipModel.Version), shipModel.Name, shipModel.OwnerId,
+			formatNumber(shipModel.CalculateTotalMass()), strings.Join(modules, ", ")})
+	}
+
+	return rows
+}
+
+var shipModelHeaders = []string{"ID", "VERSION", "NAME", "OWNER", "MASS", "MODULES"}
+
+func shipModelList(app *App, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	var shipModels []*galaxy.ShipModel
+	if err := app.client.get("/ship-models", &shipModels); err != nil {
+		return err
+	}
+
+	return app.printer.print(shipModels, shipModelHeaders, shipModelRows(shipModels))
+}
+
+func shipModelGet(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("ship-model get", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var shipModel galaxy.ShipModel
+	if err := app.client.get("/ship-models/"+url.PathEscape(id), &shipModel); err != nil {
+		return err
+	}
+
+	return app.printer.print(shipModel, shipModelHeaders, shipModelRows([]*galaxy.ShipModel{&shipModel}))
+}
+
+func shipModelCreate(app *App, args []string) error {
+	flags := flag.NewFlagSet("ship-model create", flag.ContinueOnError)
+	file := flags.String("file", "", "JSON file of the ship model, - reads stdin")
+	if positional, err := parseFlags(flags, args); err != nil || len(positional) != 0 || *file == "" {
+		return errUsage
+	}
+
+	data, err := readInput(*file)
+	if err != nil {
+		return err
+	}
+
+	var shipModel galaxy.ShipModel
+	if err := json.Unmarshal(data, &shipModel); err != nil {
+		return fmt.Errorf("ship model %s: %w", *file, err)
+	}
+
+	var created galaxy.ShipModel
+	if err := app.client.post("/ship-models", shipModel, &created); err != nil {
+		return err
+	}
+
+	return app.printer.print(created, shipModelHeaders, shipModelRows([]*galaxy.ShipModel{&created}))
+}
+
+var fleetBuildHeaders = []string{"ID", "DIVISION", "RACE", "ATTACK", "DEFENSE", "ENGINE", "CARGO", "RESEARCHED"}
+
+func fleetBuildRows(fleetBuilds []*galaxy.FleetBuild) [][]string {
+	rows := [][]string{}
+	for _, fleetBuild := range fleetBuilds {
+		rows = append(rows, []string{fleetBuild.ID, fleetBuild.DivisionId, fleetBuild.RaceId,
+			formatNumber(fleetBuild.AttackResources), formatNumber(fleetBuild.DefenseResources),
+			formatNumber(fleetBuild.EngineResources), formatNumber(fleetBuild.CargoResources),
+			strconv.Itoa(len(fleetBuild.ResearchedNodes))})
+	}
+
+	return rows
+}
+
+func fleetBuildList(app *App, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	var fleetBuilds []*galaxy.FleetBuild
+	if err := app.client.get("/fleet-builds", &fleetBuilds); err != nil {
+		return err
+	}
+
+	return app.printer.print(fleetBuilds, fleetBuildHeaders, fleetBuildRows(fleetBuilds))
+}
+
+func fleetBuildGet(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("fleet-build get", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var fleetBuild galaxy.FleetBuild
+	if err := app.client.get("/fleet-builds/"+url.PathEscape(id), &fleetBuild); err != nil {
+		return err
+	}
+	var assignments []*galaxy.FleetBuildToShipModel
+	if err := app.client.get("/fleet-builds/"+url.PathEscape(id)+"/ship-models", &assignments); err != nil {
+		return err
+	}
+
+	if app.printer.format == OUTPUT_JSON {
+		return app.printer.print(map[string]any{"fleet_build": fleetBuild, "ship_models": assignments}, nil, nil)
+	}
+	if err := app.printer.print(nil, fleetBuildHeaders, fleetBuildRows([]*galaxy.FleetBuild{&fleetBuild})); err != nil {
+		return err
+	}
+	fmt.Fprintln(app.out)
+
+	return app.printer.print(nil, assignmentHeaders, assignmentRows(assignments))
+}
+
+var assignmentHeaders = []string{"SHIP MODEL", "VERSION", "AMOUNT", "MASS"}
+
+func assignmentRows(assignments []*galaxy.FleetBuildToShipModel) [][]string {
+	rows := [][]string{}
+	for _, assignment := range assignments {
+		rows = append(rows, []string{assignment.ShipModelID, strconv.Itoa(assignment.ShipModelVersion),
+			strconv.Itoa(assignment.Amount), formatNumber(assignment.ResultMass)})
+	}
+
+	return rows
+}
+
+func fleetBuildCreate(app *App, args []string) error {
+	flags := flag.NewFlagSet("fleet-build create", flag.ContinueOnError)
+	id := flags.String("id", "", "id of the fleet build")
+	divisionId := flags.String("division", "", "division id")
+	raceId := flags.String("race", "", "race id")
+	attack := flags.Float64("attack", 0, "attack research resources")
+	defense := flags.Float64("defense", 0, "defense research resources")
+	engine := flags.Float64("engine", 0, "engine research resources")
+	cargo := flags.Float64("cargo", 0, "cargo research resources")
+	if positional, err := parseFlags(flags, args); err != nil || len(positional) != 0 || *divisionId == "" || *raceId == "" {
+		return errUsage
+	}
+
+	fleetBuild := galaxy.FleetBuild{
+		ID:               *id,
+		DivisionId:       *divisionId,
+		RaceId:           *raceId,
+		AttackResources:  *attack,
+		DefenseResources: *defense,
+		EngineResources:  *engine,
+		CargoResources:   *cargo,
+		ResearchedNodes:  []string{},
+	}
+	var created galaxy.FleetBuild
+	if err := app.client.post("/fleet-builds", fleetBuild, &created); err != nil {
+		return err
+	}
+
+	return app.printer.print(created, fleetBuildHeaders, fleetBuildRows([]*galaxy.FleetBuild{&created}))
+}
+
+func fleetBuildAssign(app *App, args []string) error {
+	flags := flag.NewFlagSet("fleet-build assign", flag.ContinueOnError)
+	shipModelId := flags.String("ship-model", "", "ship model id")
+	amount := flags.Int("amount", 0, "number of ships")
+	version := flags.Int("version", 0, "ship model version, the latest when 0")
+	id, err := parseID(flags, args)
+	if err != nil || *shipModelId == "" {
+		return errUsage
+	}
+
+	assignment := galaxy.FleetBuildToShipModel{FleetBuildID: id, ShipModelID: *shipModelId, ShipModelVersion: *version, Amount: *amount}
+	var assigned galaxy.FleetBuildToShipModel
+	if err := app.client.post("/fleet-builds/"+url.PathEscape(id)+"/ship-models", assignment, &assigned); err != nil {
+		return err
+	}
+
+	return app.printer.print(assigned, assignmentHeaders, assignmentRows([]*galaxy.FleetBuildToShipModel{&assigned}))
+}
+
+func fleetBuildStatistics(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("fleet-build statistics", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var statistics galaxy.FleetBuildStatistics
+	if err := app.client.get("/fleet-builds/"+url.PathEscape(id)+"/statistics", &statistics); err != nil {
+		return err
+	}
+
+	fields := [][2]string{
+		{"max resources", strconv.Itoa(statistics.MaxResources)},
+		{"used resources", strconv.Itoa(statistics.UsedResources)},
+		{"used for ships", strconv.Itoa(statistics.UsedResourcesForShips)},
+		{"used for technologies", strconv.Itoa(statistics.UsedResourcesForTechnologies)},
+		{"remaining", strconv.Itoa(statistics.RemainingResources)},
+		{"exceeding", strconv.Itoa(statistics.ExceedingResources)},
+	}
+	if tech := statistics.Technologies; tech != nil {
+		fields = append(fields, [2]string{"technologies", fmt.Sprintf("attack %s, defense %s, engine %s, cargo %s",
+			formatDecimal(tech.Attack), formatDecimal(tech.Defense), formatDecimal(tech.Engine), formatDecimal(tech.Cargo))})
+	}
+
+	return app.printer.printFields(statistics, fields)
+}
+
+func fleetBuildBuild(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("fleet-build build", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var fleet galaxy.Fleet
+	if err := app.client.post("/fleet-builds/"+url.PathEscape(id)+"/build", nil, &fleet); err != nil {
+		return err
+	}
+
+	return printFleet(app, &fleet)
+}
+
+func fleetGet(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("fleet get", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var fleet galaxy.Fleet
+	if err := app.client.get("/fleets/"+url.PathEscape(id), &fleet); err != nil {
+		return err
+	}
+
+	return printFleet(app, &fleet)
+}
+
+// printFleet writes the fleet summary and a row per ship.
+func printFleet(app *App, fleet *galaxy.Fleet) error {
+	if app.printer.format == OUTPUT_JSON {
+		return app.printer.print(fleet, nil, nil)
+	}
+
+	location := fleet.Location
+	if fleet.Movement != nil {
+		location = fmt.Sprintf("%s -> %s (arrives %s)", fleet.Movement.From, fleet.Movement.To, formatDecimal(fleet.Movement.ArrivalTime))
+	}
+	err := app.printer.printFields(fleet, [][2]string{
+		{"id", fleet.ID},
+		{"owner", fleet.Owner},
+		{"division", fleet.DivisionId},
+		{"location", location},
+		{"ships", strconv.Itoa(len(fleet.Ships))},
+		{"wrecks", strconv.Itoa(len(fleet.Wrecks))},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(app.out)
+
+	rows := [][]string{}
+	for _, ship := range fleet.Ships {
+		rows = append(rows, []string{ship.ID, ship.Name, strconv.Itoa(ship.Tech.Guns), formatDecimal(ship.Tech.Attack),
+			formatDecimal(ship.Tech.Defense), formatDecimal(ship.Tech.Speed), formatNumber(ship.Tech.Mass)})
+	}
+
+	return app.printer.print(nil, []string{"SHIP", "NAME", "GUNS", "ATTACK", "DEFENSE", "SPEED", "MASS"}, rows)
+}
+
+func fleetHistory(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("fleet history", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var events []*galaxy.FleetEvent
+	if err := app.client.get("/fleets/"+url.PathEscape(id)+"/history", &events); err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, event := range events {
+		rows = append(rows, []string{formatDecimal(event.MapTime), event.Type, strconv.Itoa(event.Ships), strconv.Itoa(event.Added),
+			strconv.Itoa(event.Lost), event.PlanetId, event.BattleId, event.Description})
+	}
+
+	return app.printer.print(events, []string{"MAP TIME", "EVENT", "SHIPS", "ADDED", "LOST", "PLANET", "BATTLE", "DESCRIPTION"}, rows)
+}
+
+var battleHeaders = []string{"ID", "DIVISION", "LOCATION", "TIME", "SIDE A", "SIDE B", "SHIPS", "SURVIVORS", "SHOTS"}
+
+func battleRows(battles []*galaxy.Battle) [][]string {
+	rows := [][]string{}
+	for _, battle := range battles {
+		survivorsA, survivorsB := battle.Survivors()
+		rows = append(rows, []string{battle.ID, battle.DivisionId, battle.Location, formatDecimal(battle.Time),
+			fleetOwner(battle.SideA), fleetOwner(battle.SideB),
+			fmt.Sprintf("%d:%d", fleetShips(battle.SideA), fleetShips(battle.SideB)),
+			fmt.Sprintf("%d:%d", survivorsA, survivorsB), strconv.Itoa(len(battle.Shots))})
+	}
+
+	return rows
+}
+
+func fleetOwner(fleet *galaxy.Fleet) string {
+	if fleet == nil {
+		return ""
+	}
+
+	return fleet.Owner
+}
+
+func fleetShips(fleet *galaxy.Fleet) int {
+	if fleet == nil {
+		return 0
+	}
+
+	return len(fleet.Ships)
+}
+
+func battleList(app *App, args []string) error {
+	flags := flag.NewFlagSet("battle list", flag.ContinueOnError)
+	divisionId := flags.String("division", "", "division id")
+	if positional, err := parseFlags(flags, args); err != nil || len(positional) != 0 || *divisionId == "" {
+		return errUsage
+	}
+
+	var battles []*galaxy.Battle
+	if err := app.client.get("/divisions/"+url.PathEscape(*divisionId)+"/battles", &battles); err != nil {
+		return err
+	}
+
+	return app.printer.print(battles, battleHeaders, battleRows(battles))
+}
+
+func battleGet(app *App, args []string) error {
+	id, err := parseID(flag.NewFlagSet("battle get", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	var battle galaxy.Battle
+	if err := app.client.get("/battle?id="+url.QueryEscape(id), &battle); err != nil {
+		return err
+	}
+
+	return app.printer.print(battle, battleHeaders, battleRows([]*galaxy.Battle{&battle}))
+}
+
+func battleWatch(app *App, args []string) error {
+	flags := flag.NewFlagSet("battle watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", 200*time.Millisecond, "delay between the replayed shots")
+	poll := flags.Duration("poll", 2*time.Second, "delay between the requests while the battle is not fought yet")
+	timeout := flags.Duration("timeout", 10*time.Minute, "time to wait for the battle")
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+
+	return watchBattle(app, id, WatchOptions{Interval: *interval, Poll: *poll, Timeout: *timeout})
+}
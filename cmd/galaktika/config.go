@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DEFAULT_PROFILE = "default"
+	DEFAULT_URL     = "http://localhost:8080/api"
+)
+
+// Profile is a server the client talks to and the token it authenticates with.
+type Profile struct {
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"`
+}
+
+// Config holds the profiles of the client, stored as JSON in the user config directory.
+type Config struct {
+	// profile used when no profile is given
+	Current  string              `json:"current,omitempty"`
+	Profiles map[string]*Profile `json:"profiles"`
+
+	path string
+}
+
+// configPath returns the path of the config file, GALAKTIKA_CONFIG overrides the default path.
+func configPath() (string, error) {
+	if path := os.Getenv("GALAKTIKA_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "galaktika", "config.json"), nil
+}
+
+// loadConfig reads the config file, an empty config is returned when the file does not exist yet.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: map[string]*Profile{}, path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+
+	return config, nil
+}
+
+// save writes the config readable only by the user, as it holds the tokens.
+func (config *Config) save() error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(config.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(config.path, data, 0o600)
+}
+
+// profileName returns the given name, or the current profile when empty.
+func (config *Config) profileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if config.Current != "" {
+		return config.Current
+	}
+
+	return DEFAULT_PROFILE
+}
+
+// profile returns a copy of the profile with the name, the local server is used for unknown profiles.
+func (config *Config) profile(name string) Profile {
+	profile, ok := config.Profiles[config.profileName(name)]
+	if !ok {
+		return Profile{URL: DEFAULT_URL}
+	}
+
+	return *profile
+}
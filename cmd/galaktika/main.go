@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Command line client of the REST API, e.g.
+//
+//	galaktika config set local -url http://localhost:8080/api -token token-rex-001 -use
+//	galaktika division list
+//	galaktika fleet-build assign alpha-build-1 -ship-model m1 -amount 5
+//	galaktika -output json fleet-build statistics alpha-build-1
+//	galaktika battle watch 42
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// App is the state shared by the commands.
+type App struct {
+	config      *Config
+	profileName string
+	client      *Client
+	printer     *Printer
+	out         io.Writer
+}
+
+// Command runs a subcommand with its arguments.
+type Command struct {
+	usage string
+	run   func(app *App, args []string) error
+}
+
+var errUsage = errors.New("invalid usage")
+
+func run(args []string, out io.Writer) error {
+	global := flag.NewFlagSet("galaktika", flag.ContinueOnError)
+	global.SetOutput(out)
+	profileName := global.String("profile", "", "profile of the config, the current profile when not set")
+	output := global.String("output", OUTPUT_TABLE, "output format: table or json")
+	url := global.String("url", "", "API URL, overrides the profile")
+	token := global.String("token", "", "authentication token, overrides the profile")
+	global.Usage = func() { printUsage(global, out) }
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if *output != OUTPUT_TABLE && *output != OUTPUT_JSON {
+		return fmt.Errorf("unknown output %q", *output)
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+
+	profile := config.profile(*profileName)
+	if *url != "" {
+		profile.URL = *url
+	}
+	if *token != "" {
+		profile.Token = *token
+	}
+
+	app := &App{
+		config:      config,
+		profileName: *profileName,
+		client:      NewClient(profile.URL, profile.Token),
+		printer:     &Printer{out: out, format: *output},
+		out:         out,
+	}
+
+	rest := global.Args()
+	if len(rest) < 2 {
+		global.Usage()
+		return errUsage
+	}
+	group, ok := commands[rest[0]]
+	if !ok {
+		global.Usage()
+		return fmt.Errorf("unknown command %q", rest[0])
+	}
+	command, ok := group[rest[1]]
+	if !ok {
+		global.Usage()
+		return fmt.Errorf("unknown command %q %q", rest[0], rest[1])
+	}
+
+	err = command.run(app, rest[2:])
+	if errors.Is(err, errUsage) {
+		return fmt.Errorf("usage: galaktika %s %s %s", rest[0], rest[1], command.usage)
+	}
+
+	return err
+}
+
+func printUsage(global *flag.FlagSet, out io.Writer) {
+	fmt.Fprintln(out, "Usage: galaktika [flags] <command> <subcommand> [arguments]")
+	fmt.Fprintln(out, "\nFlags:")
+	global.PrintDefaults()
+	fmt.Fprintln(out, "\nCommands:")
+
+	groups := make([]string, 0, len(commands))
+	for name := range commands {
+		groups = append(groups, name)
+	}
+	slices.Sort(groups)
+	for _, group := range groups {
+		names := make([]string, 0, len(commands[group]))
+		for name := range commands[group] {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %s\n", strings.TrimSpace(group+" "+name+" "+commands[group][name].usage))
+		}
+	}
+}
+
+// parseFlags parses the flags placed anywhere between the positional arguments and returns the positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseID parses the flags and the single id argument of a command.
+func parseID(flags *flag.FlagSet, args []string) (string, error) {
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return "", err
+	}
+	if len(positional) != 1 {
+		return "", errUsage
+	}
+
+	return positional[0], nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+)
+
+// Printer writes the results as tables or as the JSON returned by the server.
+type Printer struct {
+	out    io.Writer
+	format string
+}
+
+// print writes the value as JSON, or the rows as a table with the headers.
+func (printer *Printer) print(value any, headers []string, rows [][]string) error {
+	if printer.format == OUTPUT_JSON {
+		encoder := json.NewEncoder(printer.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(printer.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+// printFields writes the value as JSON, or the name and value pairs as a table.
+func (printer *Printer) printFields(value any, fields [][2]string) error {
+	if printer.format == OUTPUT_JSON {
+		return printer.print(value, nil, nil)
+	}
+
+	writer := tabwriter.NewWriter(printer.out, 0, 4, 1, ' ', 0)
+	for _, field := range fields {
+		fmt.Fprintf(writer, "%s:\t%s\n", strings.ToUpper(field[0]), field[1])
+	}
+
+	return writer.Flush()
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
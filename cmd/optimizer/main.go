@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+)
+
+// Searches for the fleet builds beating an opponent fleet, e.g.
+//
+//	go run ./cmd/optimizer -resources 500 -opponent-archetype tank -iterations 50
+//	go run ./cmd/optimizer -division division.json -opponent fleet.json -json
+func main() {
+	divisionFile := flag.String("division", "", "JSON file of the division, a default division is used when not set")
+	resources := flag.Int("resources", 300, "resources of the default division")
+	opponentFile := flag.String("opponent", "", "JSON file of the opponent fleet")
+	opponentArchetype := flag.String("opponent-archetype", galaxy.ARCHETYPE_BALANCED, "archetype of the generated opponent when no opponent file is set")
+	budget := flag.Int("budget", 0, "resources of the fleet builds, at most the division resources which are used when 0")
+	iterations := flag.Int("iterations", galaxy.OPTIMIZATION_ITERATIONS, "mutations tried from every starting archetype")
+	battles := flag.Int("battles", galaxy.OPTIMIZATION_BATTLES, "seeded battles per candidate")
+	results := flag.Int("results", galaxy.OPTIMIZATION_RESULTS, "number of the best fleet builds printed")
+	seed := flag.Uint64("seed", 1, "seed of the mutations and the battles")
+	jsonOutput := flag.Bool("json", false, "print the results as JSON")
+	flag.Parse()
+
+	division := &galaxy.Division{ID: "optimizer", ResourcesAmount: *resources}
+	if *divisionFile != "" {
+		division = &galaxy.Division{}
+		exitOnError(readJSON(*divisionFile, division))
+	}
+
+	idGenerator := &util.SimpleIdGenerator{}
+	opponent, err := loadOpponent(*opponentFile, *opponentArchetype, division, idGenerator)
+	exitOnError(err)
+
+	options := galaxy.OptimizationOptions{Budget: *budget, Iterations: *iterations, Battles: *battles, Results: *results, Seed: *seed}
+	found, err := game.NewFleetOptimizer(game.DefaultBattleLimits(), idGenerator).Optimize(context.Background(), division, "optimizer", opponent, options, func(iteration int) {
+		fmt.Fprintf(os.Stderr, "\riteration %d/%d", iteration, *iterations)
+	})
+	fmt.Fprintln(os.Stderr)
+	exitOnError(err)
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		exitOnError(encoder.Encode(found))
+		return
+	}
+	printResults(found)
+}
+
+func loadOpponent(file string, archetypeName string, division *galaxy.Division, idGenerator *util.SimpleIdGenerator) (*galaxy.Fleet, error) {
+	if file != "" {
+		opponent := &galaxy.Fleet{}
+		if err := readJSON(file, opponent); err != nil {
+			return nil, err
+		}
+		return opponent, nil
+	}
+
+	archetype := galaxy.GetArchetype(archetypeName)
+	if archetype == nil {
+		return nil, fmt.Errorf("unknown archetype %q", archetypeName)
+	}
+	fleetBuild, err := galaxy.GenerateFleetBuild(division, archetype, 0, idGenerator)
+	if err != nil {
+		return nil, err
+	}
+
+	return game.GenerateFleet(fleetBuild, idGenerator), nil
+}
+
+func readJSON(file string, value any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+func printResults(found []*galaxy.OptimizedFleetBuild) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "#\tWIN RATE\tSCORE\tW/D/L\tSHIPS\tSHIP MODULES\tATTACK\tDEFENSE\tENGINE\tCARGO")
+	for i, result := range found {
+		fleetBuild := result.FleetBuild
+		for _, assignment := range fleetBuild.AssignedShipModels {
+			modules := ""
+			for _, module := range assignment.ShipModel.Modules {
+				modules += fmt.Sprintf("%s %gx%d ", module.Type, module.Mass, max(module.Count, 1))
+			}
+			fmt.Fprintf(writer, "%d\t%.0f%%\t%.2f\t%d/%d/%d\t%d\t%s\t%g\t%g\t%g\t%g\n", i+1, result.WinRate*100, result.Score,
+				result.Wins, result.Draws, result.Losses, assignment.Amount, modules,
+				fleetBuild.AttackResources, fleetBuild.DefenseResources, fleetBuild.EngineResources, fleetBuild.CargoResources)
+		}
+	}
+	_ = writer.Flush()
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -1,11 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "glaktika.eu/galaktika/docs"
+	"glaktika.eu/galaktika/internal/api"
+	"glaktika.eu/galaktika/internal/config"
 	"glaktika.eu/galaktika/internal/di"
+	"glaktika.eu/galaktika/internal/logging"
+	"glaktika.eu/galaktika/internal/metrics"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 )
 
 // @title Galaktika API
@@ -14,29 +30,130 @@ import (
 // @host localhost:8080
 // @BasePath /api
 func main() {
-	router := gin.Default()
-
-	router.Static("/assets", "./assets")
-	router.StaticFile("/", "./pages/index.html")
-	router.StaticFile("/dummy_login.html", "./pages/dummy_login.html")
-	router.StaticFile("/divisions.html", "./pages/divisions.html")
-	router.GET("/division/:divisionId/main.html", func(c *gin.Context) { c.File("./pages/division/main.html") })
-	router.GET("/division/:divisionId/fleet-builds.html", func(c *gin.Context) { c.File("./pages/division/fleet-builds.html") })
-	router.GET("/fleet-build/:id/main.html", func(c *gin.Context) { c.File("./pages/division/fleet-build/main.html") })
-	router.StaticFile("/ship-model/list.html", "./pages/ship-model/list.html")
-	router.GET("/ship-model/:id/details.html", func(c *gin.Context) { c.File("./pages/ship-model/details.html") })
-	router.GET("/ship-model/:id/edit.html", func(c *gin.Context) { c.File("./pages/ship-model/edit.html") })
-	router.StaticFile("/test-ship-designs", "./pages/test_ship_designs.html")
-	router.StaticFile("/test-ship-group-designs", "./pages/test_ship_group_designs.html")
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fatal("reading the config failed", "error", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid config", "error", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fatal("creating the logger failed", "error", err)
+	}
+	slog.SetDefault(logger)
+
+	if err := di.CreateSingletons(cfg); err != nil {
+		fatal("creating the services failed", "error", err)
+	}
+	if cfg.Scenario != "" {
+		loadScenario(cfg.Scenario)
+	}
+
+	if cfg.LogLevel == "debug" {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	// the recovery is innermost, the recovered panics are logged and counted as 500
+	router.Use(api.RequestLogger(logger, di.AuthenticationManagerInstance, &util.UUIDGenerator{}), api.RequestMetrics(metrics.Default), gin.Recovery())
+	if len(cfg.CORSOrigins) > 0 {
+		router.Use(api.CORS(cfg.CORSOrigins))
+	}
+
+	pages := func(path string) string { return filepath.Join(cfg.PagesDir, path) }
+	router.Static("/assets", cfg.AssetsDir)
+	router.StaticFile("/", pages("index.html"))
+	router.StaticFile("/dummy_login.html", pages("dummy_login.html"))
+	router.StaticFile("/divisions.html", pages("divisions.html"))
+	router.GET("/division/:divisionId/main.html", func(c *gin.Context) { c.File(pages("division/main.html")) })
+	router.GET("/division/:divisionId/fleet-builds.html", func(c *gin.Context) { c.File(pages("division/fleet-builds.html")) })
+	router.GET("/fleet-build/:id/main.html", func(c *gin.Context) { c.File(pages("division/fleet-build/main.html")) })
+	router.StaticFile("/ship-model/list.html", pages("ship-model/list.html"))
+	router.GET("/ship-model/:id/details.html", func(c *gin.Context) { c.File(pages("ship-model/details.html")) })
+	router.GET("/ship-model/:id/edit.html", func(c *gin.Context) { c.File(pages("ship-model/edit.html")) })
+	router.StaticFile("/test-ship-designs", pages("test_ship_designs.html"))
+	router.StaticFile("/test-ship-group-designs", pages("test_ship_group_designs.html"))
 
 	// Swagger UI
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// API endpoints
 	apiRoute := router.Group("/api")
-
-	di.CreateSingletons("dev")
 	di.RegisterRoutes(apiRoute)
+	di.RegisterHealthRoutes(router)
+
+	di.TurnSchedulerInstance.Start()
+	di.MatchmakingSchedulerInstance.Start()
+
+	server := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+	go func() {
+		slog.Info("server listening", "environment", cfg.Environment, "listen", cfg.Listen, "tls", cfg.TLS())
+		var err error
+		if cfg.TLS() {
+			err = server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			fatal("server stopped", "error", err)
+		}
+	}()
+	di.HealthControllerInstance.SetReady(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	shutdown(server, time.Duration(cfg.ShutdownSeconds)*time.Second)
+}
+
+// fatal logs the error and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// shutdown fails the readiness probe, drains the requests in progress and stops the background workers.
+// A second signal is not caught anymore and kills the server at once.
+func shutdown(server *http.Server, timeout time.Duration) {
+	slog.Info("shutting down", "timeout", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	di.HealthControllerInstance.SetReady(false)
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("draining the requests failed", "error", err)
+	}
+	if err := di.Shutdown(ctx); err != nil {
+		slog.Error("stopping the workers failed", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// loadScenario stores the scenario file or stops the server listing all the problems of the scenario.
+func loadScenario(path string) {
+	summary, err := di.ScenarioLoaderInstance.LoadFile(path)
+	var validationError *galaxy.ValidationError
+	if errors.As(err, &validationError) {
+		for _, violation := range validationError.Violations {
+			slog.Error("scenario violation", "scenario", path, "field", violation.Field, "message", violation.Message)
+		}
+		fatal("scenario is invalid", "scenario", path)
+	}
+	if err != nil {
+		fatal("loading the scenario failed", "scenario", path, "error", err)
+	}
 
-	_ = router.Run(":8080")
+	slog.Info("scenario loaded", "scenario", summary.Name, "divisions", summary.Divisions, "races", summary.Races,
+		"ship_models", summary.ShipModels, "fleet_builds", summary.FleetBuilds, "assignments", summary.Assignments)
 }
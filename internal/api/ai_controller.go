@@ -0,0 +1,85 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"net/http"
+	"strconv"
+)
+
+// GeneratedFleetBuild is a fleet build planned by the AI with its statistics
+type GeneratedFleetBuild struct {
+	Archetype  string                      `json:"archetype"`
+	FleetBuild *galaxy.FleetBuild          `json:"fleet_build"`
+	Statistics galaxy.FleetBuildStatistics `json:"statistics"`
+}
+
+type AIController struct {
+	divisionRepository *dao.DivisionRepository
+	idGenerator        util.IdGenerator
+}
+
+func NewAIController(divisionRepository *dao.DivisionRepository, idGenerator util.IdGenerator) *AIController {
+	return &AIController{divisionRepository: divisionRepository, idGenerator: idGenerator}
+}
+
+// GetArchetypes godoc
+// @Summary List the archetypes of the AI fleets
+// @Tags ai
+// @Produce json
+// @Success 200 {array} galaxy.Archetype
+// @Router /ai/archetypes [get]
+func (controller *AIController) GetArchetypes(c *gin.Context) {
+	c.JSON(http.StatusOK, galaxy.Archetypes)
+}
+
+// GenerateFleetBuild godoc
+// @Summary Generate the fleet build of an AI opponent in a division, the fleet build is not stored
+// @Tags ai
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param archetype query string false "swarm, glass_cannon, tank or balanced" default(balanced)
+// @Param budget query int false "Resources of the fleet build, the division resources when not set"
+// @Success 200 {object} GeneratedFleetBuild
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/ai-fleet-build [get]
+func (controller *AIController) GenerateFleetBuild(c *gin.Context) {
+	division := controller.divisionRepository.Get(c.Param("id"))
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+
+	archetype := galaxy.GetArchetype(c.DefaultQuery("archetype", galaxy.ARCHETYPE_BALANCED))
+	if archetype == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown archetype"})
+		return
+	}
+
+	budget := 0
+	if value := c.Query("budget"); value != "" {
+		var err error
+		if budget, err = strconv.Atoi(value); err != nil || budget < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "budget must be a non-negative number"})
+			return
+		}
+	}
+
+	fleetBuild, err := galaxy.GenerateFleetBuild(division, archetype, budget, controller.idGenerator)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if budget == 0 {
+		budget = division.ResourcesAmount
+	}
+
+	c.JSON(http.StatusOK, GeneratedFleetBuild{
+		Archetype:  archetype.Name,
+		FleetBuild: fleetBuild,
+		Statistics: fleetBuild.CalculateStatistics(budget),
+	})
+}
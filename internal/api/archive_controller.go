@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+	"strings"
+)
+
+type ArchiveController struct {
+	authenticationManager AuthenticationManager
+	archiveService        *game.ArchiveService
+}
+
+func NewArchiveController(authenticationManager AuthenticationManager, archiveService *game.ArchiveService) *ArchiveController {
+	return &ArchiveController{
+		authenticationManager: authenticationManager,
+		archiveService:        archiveService,
+	}
+}
+
+// archiveError responds with the status matching the archive service error.
+func archiveError(c *gin.Context, err error) {
+	var conflictError *galaxy.ArchiveConflictError
+	switch {
+	case errors.Is(err, game.ErrDivisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &conflictError):
+		c.JSON(http.StatusConflict, gin.H{"error": "Archived ids are already stored", "conflicts": conflictError.Conflicts})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// authorizeAdmin responds with 401 or 403 unless the bearer token is a game master's.
+func (controller *ArchiveController) authorizeAdmin(c *gin.Context) bool {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return false
+	}
+	if !controller.authenticationManager.Authenticate(token).IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return false
+	}
+
+	return true
+}
+
+// ExportDivision godoc
+// @Summary Export the complete state of a division
+// @Description Races, ship models, fleet builds and their assignments, the map, fleets, battles, ratings and budgets. The tokens of the races are not exported.
+// @Tags archives
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {object} galaxy.DivisionArchive
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/export [get]
+func (controller *ArchiveController) ExportDivision(c *gin.Context) {
+	if !controller.authorizeAdmin(c) {
+		return
+	}
+
+	archive, err := controller.archiveService.Export(c.Param("id"))
+	if err != nil {
+		archiveError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="division-`+archive.Division.ID+`.json"`)
+	c.JSON(http.StatusOK, archive)
+}
+
+// ImportDivision godoc
+// @Summary Import a division archive
+// @Description The archived ids which are already stored fail the import, get new ids or replace the stored objects, by the conflict parameter.
+// @Tags archives
+// @Accept json
+// @Produce json
+// @Param archive body galaxy.DivisionArchive true "Division archive"
+// @Param division_id query string false "ID of the imported division, the archived ID when empty"
+// @Param conflict query string false "fail (default), rename or overwrite"
+// @Param race query []string false "Race mapping archived:stored, e.g. rex:red" collectionFormat(multi)
+// @Success 201 {object} galaxy.ArchiveMapping
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /divisions/import [post]
+func (controller *ArchiveController) ImportDivision(c *gin.Context) {
+	if !controller.authorizeAdmin(c) {
+		return
+	}
+
+	options := galaxy.ArchiveImportOptions{DivisionId: c.Query("division_id"), Conflict: c.Query("conflict"), Races: map[string]string{}}
+	for _, race := range c.QueryArray("race") {
+		from, to, found := strings.Cut(race, ":")
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "race mapping " + race + " is not archived:stored"})
+			return
+		}
+		options.Races[from] = to
+	}
+
+	var archive galaxy.DivisionArchive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := controller.archiveService.Import(&archive, options)
+	if err != nil {
+		archiveError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, mapping)
+}
@@ -10,6 +10,7 @@ type AuthenticationManager interface {
 	AuthenticateFromContext(c *gin.Context) *galaxy.Race
 	TokenValid(token string) bool
 	AddToken(token string, race *galaxy.Race)
+	FindRace(id string) *galaxy.Race
 }
 
 type MemoryAuthenticationManager struct {
@@ -38,3 +39,14 @@ func (am *MemoryAuthenticationManager) TokenValid(token string) bool {
 func (am *MemoryAuthenticationManager) AddToken(token string, race *galaxy.Race) {
 	am.tokenToRace[token] = race
 }
+
+// FindRace returns the race signed in by any of the tokens.
+func (am *MemoryAuthenticationManager) FindRace(id string) *galaxy.Race {
+	for _, race := range am.tokenToRace {
+		if race.ID == id {
+			return race
+		}
+	}
+
+	return nil
+}
@@ -18,10 +18,16 @@ func NewBattleController(repository *dao.BattleRepository) *BattleController {
 // @Summary Get battle
 // @Tags battles
 // @Produce json
+// @Param id query string false "Battle ID, the example battle when empty"
 // @Success 200 {object} galaxy.Battle
+// @Failure 404 {object} map[string]string
 // @Router /battle [get]
 func (controller *BattleController) GetBattle(c *gin.Context) {
-	// TODO id from request query
-	id := "1"
-	c.JSON(http.StatusOK, controller.battleRepository.GetBattle(id))
+	id := c.DefaultQuery("id", "1")
+	battle := controller.battleRepository.GetBattle(id)
+	if battle == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Battle not found"})
+		return
+	}
+	c.JSON(http.StatusOK, battle)
 }
@@ -5,6 +5,7 @@ import (
 	"glaktika.eu/galaktika/internal/dao"
 	"glaktika.eu/galaktika/pkg/galaxy"
 	"net/http"
+	"strconv"
 )
 
 type DivisionController struct {
@@ -58,6 +59,10 @@ func (controller *DivisionController) CreateDivision(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := division.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	controller.divisionRepository.Upsert(&division)
 	c.JSON(http.StatusCreated, division)
 }
@@ -88,6 +93,10 @@ func (controller *DivisionController) UpdateDivision(c *gin.Context) {
 	}
 
 	division.ID = id
+	if err := division.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	controller.divisionRepository.Upsert(&division)
 	c.JSON(http.StatusOK, division)
 }
@@ -111,3 +120,37 @@ func (controller *DivisionController) DeleteDivision(c *gin.Context) {
 	controller.divisionRepository.Delete(id)
 	c.JSON(http.StatusOK, gin.H{"message": "Division deleted successfully"})
 }
+
+// GetResearchCost godoc
+// @Summary Resources needed to reach a technology level in the division
+// @Tags divisions
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param technology query string true "Technology: attack, defense, engine or cargo"
+// @Param level query number true "Target technology level"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/research-cost [get]
+func (controller *DivisionController) GetResearchCost(c *gin.Context) {
+	division := controller.divisionRepository.Get(c.Param("id"))
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+
+	technology := c.Query("technology")
+	level, err := strconv.ParseFloat(c.Query("level"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level must be a number"})
+		return
+	}
+
+	resources, err := division.ResourcesToReachLevel(technology, level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"technology": technology, "level": level, "resources": resources})
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"net/http"
+)
+
+type EconomyController struct {
+	authenticationManager AuthenticationManager
+	divisionRepository    *dao.DivisionRepository
+	economyService        *game.EconomyService
+}
+
+func NewEconomyController(
+	authenticationManager AuthenticationManager,
+	divisionRepository *dao.DivisionRepository,
+	economyService *game.EconomyService,
+) *EconomyController {
+	return &EconomyController{
+		authenticationManager: authenticationManager,
+		divisionRepository:    divisionRepository,
+		economyService:        economyService,
+	}
+}
+
+// GetEconomyReport godoc
+// @Summary Get the balance, the income and the spending history of the race in a division
+// @Tags economy
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {object} galaxy.EconomyReport
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/economy [get]
+func (controller *EconomyController) GetEconomyReport(c *gin.Context) {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+	race := controller.authenticationManager.Authenticate(token)
+
+	division := controller.divisionRepository.Get(c.Param("id"))
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, controller.economyService.Report(division, race.ID))
+}
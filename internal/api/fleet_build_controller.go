@@ -1,12 +1,13 @@
 package api
 
 import (
-	"fmt"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
 	"glaktika.eu/galaktika/pkg/galaxy"
 	"net/http"
+	"strconv"
 )
 
 type FleetBuildController struct {
@@ -15,6 +16,9 @@ type FleetBuildController struct {
 	fleetRepository       *dao.FleetRepository
 	shipModelRepository   *dao.ShipModelRepository
 	divisionRepository    *dao.DivisionRepository
+	fleetBuilder          *game.FleetBuilder
+	turnService           *game.TurnService
+	economyService        *game.EconomyService
 }
 
 func NewFleetBuildController(
@@ -23,6 +27,9 @@ func NewFleetBuildController(
 	fleetRepository *dao.FleetRepository,
 	shipModelRepository *dao.ShipModelRepository,
 	divisionRepository *dao.DivisionRepository,
+	fleetBuilder *game.FleetBuilder,
+	turnService *game.TurnService,
+	economyService *game.EconomyService,
 ) *FleetBuildController {
 	return &FleetBuildController{
 		authenticationManager: authenticationManager,
@@ -30,6 +37,9 @@ func NewFleetBuildController(
 		fleetRepository:       fleetRepository,
 		shipModelRepository:   shipModelRepository,
 		divisionRepository:    divisionRepository,
+		fleetBuilder:          fleetBuilder,
+		turnService:           turnService,
+		economyService:        economyService,
 	}
 }
 
@@ -70,13 +80,14 @@ func (controller *FleetBuildController) GetAllFleetBuilds(c *gin.Context) {
 }
 
 // CreateFleetBuild godoc
-// @Summary Create a fleet build
+// @Summary Create a fleet build, its research is paid from the race budget
 // @Tags fleet-builds
 // @Accept json
 // @Produce json
 // @Param fleetBuild body galaxy.FleetBuild true "FleetBuild data"
 // @Success 201 {object} galaxy.FleetBuild
 // @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /fleet-builds [post]
 func (controller *FleetBuildController) CreateFleetBuild(c *gin.Context) {
 	var fleetBuild galaxy.FleetBuild
@@ -88,12 +99,34 @@ func (controller *FleetBuildController) CreateFleetBuild(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Division not found"})
 		return
 	}
+	if err := fleetBuild.ValidateResearchedNodes(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !controller.payResearch(c, nil, &fleetBuild) {
+		return
+	}
 	controller.fleetBuildRepository.Upsert(&fleetBuild)
 	c.JSON(http.StatusCreated, fleetBuild)
 }
 
+// payResearch pays the research added by the fleet build, the response is written when it can not be paid.
+func (controller *FleetBuildController) payResearch(c *gin.Context, previous *galaxy.FleetBuild, fleetBuild *galaxy.FleetBuild) bool {
+	err := controller.fleetBuilder.PayResearch(previous, fleetBuild)
+	switch {
+	case errors.Is(err, galaxy.ErrInsufficientResources):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return false
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
+
 // UpdateFleetBuild godoc
-// @Summary Update a fleet build
+// @Summary Update a fleet build, the added research is paid from the race budget
 // @Tags fleet-builds
 // @Accept json
 // @Produce json
@@ -102,6 +135,7 @@ func (controller *FleetBuildController) CreateFleetBuild(c *gin.Context) {
 // @Success 200 {object} galaxy.FleetBuild
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /fleet-builds/{id} [put]
 func (controller *FleetBuildController) UpdateFleetBuild(c *gin.Context) {
 	id := c.Param("id")
@@ -118,6 +152,14 @@ func (controller *FleetBuildController) UpdateFleetBuild(c *gin.Context) {
 	}
 
 	fleetBuild.ID = id
+	fleetBuild.TechTree = existing.TechTree
+	if err := fleetBuild.ValidateResearchedNodes(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !controller.payResearch(c, existing, &fleetBuild) {
+		return
+	}
 	controller.fleetBuildRepository.Upsert(&fleetBuild)
 	c.JSON(http.StatusOK, fleetBuild)
 }
@@ -173,6 +215,7 @@ func (controller *FleetBuildController) GetAssignedShipModels(c *gin.Context) {
 // @Success 200 {object} galaxy.FleetBuildToShipModel
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Router /fleet-builds/{id}/ship-models [post]
 func (controller *FleetBuildController) AssignShipModel(c *gin.Context) {
 	fleetBuildId := c.Param("id")
@@ -190,6 +233,27 @@ func (controller *FleetBuildController) AssignShipModel(c *gin.Context) {
 
 	assignment.FleetBuildID = fleetBuildId
 
+	division := controller.divisionRepository.Get(existing.DivisionId)
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+	existing.ApplyDivision(division)
+
+	// ship models are resolved lazily, an unknown model is skipped when building the fleet
+	if controller.shipModelRepository.Get(assignment.ShipModelID) != nil {
+		shipModel := controller.shipModelRepository.GetVersion(assignment.ShipModelID, assignment.ShipModelVersion)
+		if shipModel == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ShipModel version not found"})
+			return
+		}
+		if err := existing.ValidateShipModel(shipModel); err != nil {
+			validationFailed(c, "ShipModel "+shipModel.ID+" validation failed", err)
+			return
+		}
+		assignment.ShipModelVersion = shipModel.Version
+	}
+
 	wasCreated := controller.fleetBuildRepository.AssignShipModel(&assignment)
 
 	if wasCreated {
@@ -250,10 +314,12 @@ func (controller *FleetBuildController) GetStatistics(c *gin.Context) {
 		return
 	}
 
+	fleetBuild.ApplyDivision(division)
+
 	assignments := controller.fleetBuildRepository.FindAssignedShipModels(fleetBuildId)
 	fleetBuild.AssignedShipModels = make([]galaxy.ShipModelAssignment, 0, len(assignments))
 	for _, a := range assignments {
-		shipModel := controller.shipModelRepository.Get(a.ShipModelID)
+		shipModel := controller.shipModelRepository.GetVersion(a.ShipModelID, a.ShipModelVersion)
 		if shipModel != nil {
 			fleetBuild.AssignedShipModels = append(fleetBuild.AssignedShipModels, galaxy.ShipModelAssignment{
 				ShipModel: *shipModel,
@@ -262,17 +328,21 @@ func (controller *FleetBuildController) GetStatistics(c *gin.Context) {
 		}
 	}
 
-	statistics := fleetBuild.CalculateStatistics(division.ResourcesAmount)
+	// the fleet build may use all the resources the race has received in the division
+	budget := controller.economyService.Budget(division, fleetBuild.RaceId)
+	statistics := fleetBuild.CalculateStatistics(budget.TotalIncome())
 	c.JSON(http.StatusOK, statistics)
 }
 
 // Build godoc
-// @Summary Build a fleet from a fleet build
+// @Summary Build a fleet from a fleet build, the ships are paid from the race budget
 // @Tags fleet-builds
 // @Produce json
 // @Param id path string true "FleetBuild ID"
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Router /fleet-builds/{id}/build [post]
 func (controller *FleetBuildController) Build(c *gin.Context) {
 	token := bearerToken(c)
@@ -282,43 +352,69 @@ func (controller *FleetBuildController) Build(c *gin.Context) {
 	}
 	race := controller.authenticationManager.Authenticate(token)
 
-	fleetBuildId := c.Param("id")
-	fleetBuild := controller.fleetBuildRepository.Get(fleetBuildId)
-	if fleetBuild == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "FleetBuild not found"})
+	fleet, err := controller.turnService.BuildFleet(c.Param("id"), race.ID)
+	var shipModelError *game.ShipModelError
+	switch {
+	case errors.Is(err, game.ErrFleetBuildNotFound), errors.Is(err, game.ErrDivisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, game.ErrFleetBuildNotOwned):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	case errors.As(err, &shipModelError):
+		validationFailed(c, "ShipModel "+shipModelError.ShipModelID+" validation failed", err)
+		return
+	case errors.Is(err, galaxy.ErrInsufficientResources):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	Logger(c).Info("fleet built", "fleet_id", fleet.ID, "ships", len(fleet.Ships))
 
-	assignments := controller.fleetBuildRepository.FindAssignedShipModels(fleetBuildId)
-	var ships []*galaxy.Ship
-	for _, a := range assignments {
-		shipModel := controller.shipModelRepository.Get(a.ShipModelID)
-		if shipModel == nil {
-			continue
-		}
-		shipTech := fleetBuild.CalculateShipTech(shipModel)
-		for i := 0; i < a.Amount; i++ {
-			ship := &galaxy.Ship{
-				ID:    uuid.New().String(),
-				Name:  shipModel.Name,
-				Tech:  shipTech,
-				Owner: race.ID,
-			}
-			ships = append(ships, ship)
-		}
-	}
+	c.JSON(http.StatusOK, fleet)
+}
 
-	fleet := galaxy.NewFleet(ships)
-	fleet.ID = uuid.New().String()
-	fleet.Owner = race.ID
-	fmt.Printf("Built fleet %s for user %s with %d ships\n", fleet.ID, race.ID, len(ships))
+// Reinforce godoc
+// @Summary Reinforce the existing fleet of the race with the ships of its fleet build, paid from the race budget
+// @Tags fleet-builds
+// @Produce json
+// @Param id path string true "FleetBuild ID"
+// @Success 200 {object} galaxy.Fleet
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Router /fleet-builds/{id}/reinforce [post]
+func (controller *FleetBuildController) Reinforce(c *gin.Context) {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+	race := controller.authenticationManager.Authenticate(token)
 
-	controller.fleetRepository.Upsert(fleet)
-	controller.fleetRepository.UpsertDivisionFleet(&galaxy.DivisionFleet{
-		DivisionId: fleetBuild.DivisionId,
-		UserId:     race.ID,
-		FleetId:    fleet.ID,
-	})
+	fleet, err := controller.turnService.ReinforceFleet(c.Param("id"), race.ID)
+	var shipModelError *game.ShipModelError
+	switch {
+	case errors.Is(err, game.ErrFleetBuildNotFound), errors.Is(err, game.ErrDivisionNotFound), errors.Is(err, game.ErrFleetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, game.ErrFleetBuildNotOwned):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	case errors.As(err, &shipModelError):
+		validationFailed(c, "ShipModel "+shipModelError.ShipModelID+" validation failed", err)
+		return
+	case errors.Is(err, galaxy.ErrInsufficientResources):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, fleet)
 }
@@ -388,12 +484,101 @@ func (controller *FleetBuildController) CalculateShipTech(c *gin.Context) {
 		return
 	}
 
-	shipModel := controller.shipModelRepository.Get(shipModelId)
+	shipModel := controller.shipModelRepository.GetVersion(shipModelId, assignment.ShipModelVersion)
 	if shipModel == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "ShipModel not found"})
 		return
 	}
 
+	division := controller.divisionRepository.Get(fleetBuild.DivisionId)
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+	fleetBuild.ApplyDivision(division)
+
 	shipTech := fleetBuild.CalculateShipTech(shipModel)
 	c.JSON(http.StatusOK, shipTech)
 }
+
+// PreviewResearch godoc
+// @Summary Preview the marginal research gains of the next resources
+// @Tags fleet-builds
+// @Produce json
+// @Param id path string true "FleetBuild ID"
+// @Param resources query number true "Extra resources spent on each technology"
+// @Success 200 {array} galaxy.ResearchPreview
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fleet-builds/{id}/research-preview [get]
+func (controller *FleetBuildController) PreviewResearch(c *gin.Context) {
+	resources, err := strconv.ParseFloat(c.Query("resources"), 64)
+	if err != nil || resources < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resources must be a non negative number"})
+		return
+	}
+
+	fleetBuild := controller.fleetBuildRepository.Get(c.Param("id"))
+	if fleetBuild == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "FleetBuild not found"})
+		return
+	}
+
+	division := controller.divisionRepository.Get(fleetBuild.DivisionId)
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+	fleetBuild.ApplyDivision(division)
+
+	c.JSON(http.StatusOK, fleetBuild.PreviewResearch(resources))
+}
+
+// UpgradeShipModels godoc
+// @Summary Upgrade the ship model assignments of a fleet build to the latest ship model versions
+// @Tags fleet-builds
+// @Produce json
+// @Param id path string true "FleetBuild ID"
+// @Success 200 {array} galaxy.FleetBuildToShipModel
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Router /fleet-builds/{id}/ship-models/upgrade [post]
+func (controller *FleetBuildController) UpgradeShipModels(c *gin.Context) {
+	fleetBuildId := c.Param("id")
+	fleetBuild := controller.fleetBuildRepository.Get(fleetBuildId)
+	if fleetBuild == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "FleetBuild not found"})
+		return
+	}
+
+	division := controller.divisionRepository.Get(fleetBuild.DivisionId)
+	if division == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+	fleetBuild.ApplyDivision(division)
+
+	assignments := controller.fleetBuildRepository.FindAssignedShipModels(fleetBuildId)
+	for _, a := range assignments {
+		latest := controller.shipModelRepository.Get(a.ShipModelID)
+		if latest == nil {
+			continue
+		}
+		if err := fleetBuild.ValidateShipModel(latest); err != nil {
+			validationFailed(c, "ShipModel "+latest.ID+" validation failed", err)
+			return
+		}
+	}
+
+	upgraded := make([]*galaxy.FleetBuildToShipModel, 0, len(assignments))
+	for _, a := range assignments {
+		assignment := *a
+		if latest := controller.shipModelRepository.Get(a.ShipModelID); latest != nil {
+			assignment.ShipModelVersion = latest.Version
+			controller.fleetBuildRepository.AssignShipModel(&assignment)
+		}
+		upgraded = append(upgraded, &assignment)
+	}
+
+	c.JSON(http.StatusOK, upgraded)
+}
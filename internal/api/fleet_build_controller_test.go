@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+)
+
+func TestFleetBuildController_UpgradeShipModels(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1"})
+
+	shipModelRepository := dao.NewShipModelRepository()
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter", Guns: 1, OneGunMass: 2, DefenseMass: 2, EngineMass: 4})
+
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	fleetBuildRepository.Upsert(&galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a"})
+	fleetBuildRepository.AssignShipModel(&galaxy.FleetBuildToShipModel{FleetBuildID: "fb1", ShipModelID: "sm1", ShipModelVersion: 1, Amount: 2})
+
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter II", Guns: 1, OneGunMass: 4, DefenseMass: 2, EngineMass: 4})
+
+	controller := NewFleetBuildController(nil, fleetBuildRepository, dao.NewFleetRepository(), shipModelRepository, divisionRepository, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/fleet-builds/:id/ship-models/upgrade", controller.UpgradeShipModels)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/fleet-builds/fb1/ship-models/upgrade", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var upgraded []*galaxy.FleetBuildToShipModel
+	if err := json.Unmarshal(w.Body.Bytes(), &upgraded); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(upgraded) != 1 || upgraded[0].ShipModelVersion != 2 || upgraded[0].Amount != 2 {
+		t.Errorf("unexpected upgraded assignments %+v", upgraded)
+	}
+	if assignment := fleetBuildRepository.FindAssignedShipModel("fb1", "sm1"); assignment.ShipModelVersion != 2 {
+		t.Errorf("expected the upgrade to be stored, got version %d", assignment.ShipModelVersion)
+	}
+}
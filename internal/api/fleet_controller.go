@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+type FleetController struct {
+	authenticationManager AuthenticationManager
+	fleetRepository       *dao.FleetRepository
+	shipyard              *game.Shipyard
+}
+
+func NewFleetController(authenticationManager AuthenticationManager, fleetRepository *dao.FleetRepository, shipyard *game.Shipyard) *FleetController {
+	return &FleetController{
+		authenticationManager: authenticationManager,
+		fleetRepository:       fleetRepository,
+		shipyard:              shipyard,
+	}
+}
+
+// GetFleet godoc
+// @Summary Get a fleet by ID
+// @Tags fleets
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Success 200 {object} galaxy.Fleet
+// @Failure 404 {object} map[string]string
+// @Router /fleets/{id} [get]
+func (controller *FleetController) GetFleet(c *gin.Context) {
+	fleet := controller.fleetRepository.Get(c.Param("id"))
+	if fleet == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fleet not found"})
+		return
+	}
+	c.JSON(http.StatusOK, fleet)
+}
+
+// GetFleetHistory godoc
+// @Summary Get the history log of a fleet: builds, reinforcements, moves and battles
+// @Tags fleets
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Success 200 {array} galaxy.FleetEvent
+// @Failure 404 {object} map[string]string
+// @Router /fleets/{id}/history [get]
+func (controller *FleetController) GetFleetHistory(c *gin.Context) {
+	history := controller.fleetRepository.GetHistory(c.Param("id"))
+	if len(history) == 0 && controller.fleetRepository.Get(c.Param("id")) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fleet not found"})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// PreviewRepair godoc
+// @Summary Preview the repair of the wrecks of a fleet and its cost
+// @Tags fleets
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Success 200 {object} galaxy.FleetWork
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fleets/{id}/repair [get]
+func (controller *FleetController) PreviewRepair(c *gin.Context) {
+	controller.fleetWork(c, func(raceId string) (*galaxy.FleetWork, error) {
+		return controller.shipyard.PreviewRepair(c.Param("id"), raceId)
+	})
+}
+
+// Repair godoc
+// @Summary Repair the wrecks of a fleet, the cost is paid from the race budget
+// @Tags fleets
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Success 200 {object} galaxy.FleetWork
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fleets/{id}/repair [post]
+func (controller *FleetController) Repair(c *gin.Context) {
+	controller.fleetWork(c, func(raceId string) (*galaxy.FleetWork, error) {
+		return controller.shipyard.Repair(c.Param("id"), raceId)
+	})
+}
+
+// PreviewRefit godoc
+// @Summary Preview the refit of the fleet ships to the latest ship model versions and the technologies of a fleet build
+// @Tags fleets
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Param fleet_build_id query string true "FleetBuild ID providing the technologies"
+// @Success 200 {object} galaxy.FleetWork
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Router /fleets/{id}/refit [get]
+func (controller *FleetController) PreviewRefit(c *gin.Context) {
+	controller.fleetWork(c, func(raceId string) (*galaxy.FleetWork, error) {
+		return controller.shipyard.PreviewRefit(c.Param("id"), raceId, c.Query("fleet_build_id"))
+	})
+}
+
+// Refit godoc
+// @Summary Refit the fleet ships, the cost is paid from the race budget
+// @Tags fleets
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Param fleet_build_id query string true "FleetBuild ID providing the technologies"
+// @Success 200 {object} galaxy.FleetWork
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Router /fleets/{id}/refit [post]
+func (controller *FleetController) Refit(c *gin.Context) {
+	controller.fleetWork(c, func(raceId string) (*galaxy.FleetWork, error) {
+		return controller.shipyard.Refit(c.Param("id"), raceId, c.Query("fleet_build_id"))
+	})
+}
+
+// fleetWork authenticates the race and responds with the work done for it.
+func (controller *FleetController) fleetWork(c *gin.Context, work func(raceId string) (*galaxy.FleetWork, error)) {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+	race := controller.authenticationManager.Authenticate(token)
+
+	fleetWork, err := work(race.ID)
+	var shipModelError *game.ShipModelError
+	switch {
+	case errors.Is(err, game.ErrFleetNotFound), errors.Is(err, game.ErrFleetBuildNotFound), errors.Is(err, game.ErrDivisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.As(err, &shipModelError):
+		validationFailed(c, "ShipModel "+shipModelError.ShipModelID+" validation failed", err)
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fleetWork)
+}
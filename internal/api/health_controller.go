@@ -0,0 +1,59 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"maps"
+	"net/http"
+	"slices"
+	"sync/atomic"
+)
+
+// HealthCheck returns nil when the checked part of the server works.
+type HealthCheck func() error
+
+// HealthController answers the liveness and readiness probes of a reverse proxy or a container orchestrator.
+// The endpoints are served outside of the API group and need no token.
+type HealthController struct {
+	checks map[string]HealthCheck
+	// set by the server once it listens, cleared when the shutdown starts
+	ready atomic.Bool
+}
+
+func NewHealthController(checks map[string]HealthCheck) *HealthController {
+	return &HealthController{checks: checks}
+}
+
+func (controller *HealthController) SetReady(ready bool) {
+	controller.ready.Store(ready)
+}
+
+// Healthz responds with 200 while the server process answers requests.
+func (controller *HealthController) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz responds with 200 when the server takes requests and all the checks pass, otherwise with 503
+// listing the result of each check.
+func (controller *HealthController) Readyz(c *gin.Context) {
+	if !controller.ready.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "Server is starting or shutting down"})
+		return
+	}
+
+	status := http.StatusOK
+	results := gin.H{}
+	for _, name := range slices.Sorted(maps.Keys(controller.checks)) {
+		if err := controller.checks[name](); err != nil {
+			status = http.StatusServiceUnavailable
+			results[name] = err.Error()
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	if status != http.StatusOK {
+		c.JSON(status, gin.H{"status": "unavailable", "checks": results})
+		return
+	}
+	c.JSON(status, gin.H{"status": "ok", "checks": results})
+}
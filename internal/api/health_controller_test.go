@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthController_Readyz(t *testing.T) {
+	tests := []struct {
+		name           string
+		ready          bool
+		checks         map[string]HealthCheck
+		expectedStatus int
+		expectedChecks map[string]string
+	}{
+		{
+			name:           "not ready",
+			checks:         map[string]HealthCheck{"storage": func() error { return nil }},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "all checks pass",
+			ready:          true,
+			checks:         map[string]HealthCheck{"storage": func() error { return nil }},
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"storage": "ok"},
+		},
+		{
+			name:  "failed check",
+			ready: true,
+			checks: map[string]HealthCheck{
+				"storage":   func() error { return nil },
+				"scheduler": func() error { return errors.New("scheduler is not running") },
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"storage": "ok", "scheduler": "scheduler is not running"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			controller := NewHealthController(tt.checks)
+			controller.SetReady(tt.ready)
+			router := gin.New()
+			router.GET("/healthz", controller.Healthz)
+			router.GET("/readyz", controller.Readyz)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("expected the liveness probe to pass, got %d", w.Code)
+			}
+
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			var response struct {
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Checks) != len(tt.expectedChecks) {
+				t.Fatalf("expected checks %v, got %v", tt.expectedChecks, response.Checks)
+			}
+			for name, expected := range tt.expectedChecks {
+				if response.Checks[name] != expected {
+					t.Errorf("expected check %s %q, got %q", name, expected, response.Checks[name])
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
 	"strings"
 )
 
@@ -9,3 +12,15 @@ func bearerToken(c *gin.Context) string {
 	header := c.GetHeader("Authorization")
 	return strings.TrimPrefix(header, "Bearer ")
 }
+
+// validationFailed responds with 422 and all the violations of a ship model or a scenario validation.
+// Other errors are responded with 400.
+func validationFailed(c *gin.Context, message string, err error) {
+	var validationError *galaxy.ValidationError
+	if !errors.As(err, &validationError) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": message, "violations": validationError.Violations})
+}
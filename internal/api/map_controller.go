@@ -0,0 +1,267 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+// FleetOrder sends a fleet to a planet
+type FleetOrder struct {
+	PlanetID string `json:"planet_id" binding:"required"`
+}
+
+// AdvanceTimeRequest moves the map clock forward
+type AdvanceTimeRequest struct {
+	Time float64 `json:"time"`
+}
+
+type MapController struct {
+	authenticationManager AuthenticationManager
+	mapRepository         *dao.MapRepository
+	fleetRepository       *dao.FleetRepository
+	divisionRepository    *dao.DivisionRepository
+	battleRepository      *dao.BattleRepository
+	turnService           *game.TurnService
+}
+
+func NewMapController(
+	authenticationManager AuthenticationManager,
+	mapRepository *dao.MapRepository,
+	fleetRepository *dao.FleetRepository,
+	divisionRepository *dao.DivisionRepository,
+	battleRepository *dao.BattleRepository,
+	turnService *game.TurnService,
+) *MapController {
+	return &MapController{
+		authenticationManager: authenticationManager,
+		mapRepository:         mapRepository,
+		fleetRepository:       fleetRepository,
+		divisionRepository:    divisionRepository,
+		battleRepository:      battleRepository,
+		turnService:           turnService,
+	}
+}
+
+// GetMap godoc
+// @Summary Get the map of a division with its planets and fleets
+// @Tags map
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {object} galaxy.DivisionMap
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/map [get]
+func (controller *MapController) GetMap(c *gin.Context) {
+	divisionId := c.Param("id")
+	if controller.divisionRepository.Get(divisionId) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, galaxy.DivisionMap{
+		DivisionId: divisionId,
+		Time:       controller.mapRepository.GetTime(divisionId),
+		Planets:    controller.mapRepository.GetPlanets(divisionId),
+		Fleets:     controller.fleetRepository.FindByDivision(divisionId),
+	})
+}
+
+// CreatePlanet godoc
+// @Summary Add a planet to the map of a division
+// @Tags map
+// @Accept json
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param planet body galaxy.Planet true "Planet data"
+// @Success 201 {object} galaxy.Planet
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/planets [post]
+func (controller *MapController) CreatePlanet(c *gin.Context) {
+	divisionId := c.Param("id")
+	if controller.divisionRepository.Get(divisionId) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+
+	var planet galaxy.Planet
+	if err := c.ShouldBindJSON(&planet); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	planet.DivisionId = divisionId
+	if err := planet.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if existing := controller.mapRepository.GetPlanet(planet.ID); existing != nil && existing.DivisionId != divisionId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Planet belongs to another division"})
+		return
+	}
+
+	controller.mapRepository.UpsertPlanet(&planet)
+	c.JSON(http.StatusCreated, planet)
+}
+
+// DeletePlanet godoc
+// @Summary Remove a planet from the map of a division
+// @Tags map
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param planetId path string true "Planet ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/planets/{planetId} [delete]
+func (controller *MapController) DeletePlanet(c *gin.Context) {
+	divisionId := c.Param("id")
+	planetId := c.Param("planetId")
+	planet := controller.mapRepository.GetPlanet(planetId)
+	if planet == nil || planet.DivisionId != divisionId {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Planet not found"})
+		return
+	}
+
+	for _, fleet := range controller.fleetRepository.FindByDivision(divisionId) {
+		if fleet.Location == planetId || (fleet.Movement != nil && fleet.Movement.To == planetId) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Planet has fleets"})
+			return
+		}
+	}
+
+	controller.mapRepository.DeletePlanet(planetId)
+	c.JSON(http.StatusOK, gin.H{"message": "Planet deleted successfully"})
+}
+
+// AdvanceTime godoc
+// @Summary Move the map clock of a division forward, moving fleets arrive and fight
+// @Tags map
+// @Accept json
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param request body AdvanceTimeRequest true "Time to advance"
+// @Success 200 {array} galaxy.Battle
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/map/advance [post]
+func (controller *MapController) AdvanceTime(c *gin.Context) {
+	divisionId := c.Param("id")
+	if controller.divisionRepository.Get(divisionId) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+
+	var request AdvanceTimeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	battles, err := controller.turnService.AdvanceMapTime(divisionId, request.Time)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, battles)
+}
+
+// GetBattles godoc
+// @Summary List the battles fought on the map of a division
+// @Tags map
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {array} galaxy.Battle
+// @Router /divisions/{id}/battles [get]
+func (controller *MapController) GetBattles(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.battleRepository.FindByDivision(c.Param("id")))
+}
+
+// ownFleet returns the fleet from the path when it belongs to the authenticated race.
+func (controller *MapController) ownFleet(c *gin.Context) *galaxy.Fleet {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil
+	}
+	race := controller.authenticationManager.Authenticate(token)
+
+	fleet := controller.fleetRepository.Get(c.Param("id"))
+	if fleet == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fleet not found"})
+		return nil
+	}
+	if fleet.Owner != race.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return nil
+	}
+
+	return fleet
+}
+
+// DeployFleet godoc
+// @Summary Position a fleet at a planet of its division, hostile fleets at the planet fight it
+// @Tags map
+// @Accept json
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Param order body FleetOrder true "Planet to deploy to"
+// @Success 200 {array} galaxy.Battle
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fleets/{id}/deploy [post]
+func (controller *MapController) DeployFleet(c *gin.Context) {
+	fleet := controller.ownFleet(c)
+	if fleet == nil {
+		return
+	}
+
+	var order FleetOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	battles, err := controller.turnService.DeployFleet(fleet.ID, order.PlanetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, battles)
+}
+
+// MoveFleet godoc
+// @Summary Order a fleet to fly to another planet of its division
+// @Tags map
+// @Accept json
+// @Produce json
+// @Param id path string true "Fleet ID"
+// @Param order body FleetOrder true "Destination planet"
+// @Success 200 {object} galaxy.FleetMovement
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fleets/{id}/move [post]
+func (controller *MapController) MoveFleet(c *gin.Context) {
+	fleet := controller.ownFleet(c)
+	if fleet == nil {
+		return
+	}
+
+	var order FleetOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movement, err := controller.turnService.MoveFleet(fleet.ID, order.PlanetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, movement)
+}
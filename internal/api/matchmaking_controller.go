@@ -0,0 +1,169 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+// EnqueueRequest puts the division fleet of the race into the matchmaking queue
+type EnqueueRequest struct {
+	// rating or resources, rating when empty
+	MatchBy string `json:"match_by" example:"rating"`
+	// fight a generated opponent when nobody is available until the timeout
+	AllowAI bool `json:"allow_ai"`
+}
+
+type MatchmakingController struct {
+	authenticationManager AuthenticationManager
+	matchmakingRepository *dao.MatchmakingRepository
+	matchmaker            *game.Matchmaker
+}
+
+func NewMatchmakingController(
+	authenticationManager AuthenticationManager,
+	matchmakingRepository *dao.MatchmakingRepository,
+	matchmaker *game.Matchmaker,
+) *MatchmakingController {
+	return &MatchmakingController{
+		authenticationManager: authenticationManager,
+		matchmakingRepository: matchmakingRepository,
+		matchmaker:            matchmaker,
+	}
+}
+
+func matchmakingError(c *gin.Context, err error) {
+	if errors.Is(err, game.ErrDivisionNotFound) || errors.Is(err, game.ErrFleetNotFound) || errors.Is(err, game.ErrQueueEntryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// authenticate returns the race of the bearer token or responds with 401.
+func (controller *MatchmakingController) authenticate(c *gin.Context) *galaxy.Race {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil
+	}
+
+	return controller.authenticationManager.Authenticate(token)
+}
+
+// Enqueue godoc
+// @Summary Put the division fleet of the authenticated race into the matchmaking queue
+// @Tags matchmaking
+// @Accept json
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param request body EnqueueRequest true "Pairing criterion and AI fallback"
+// @Success 201 {object} galaxy.QueueEntry
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/queue [post]
+func (controller *MatchmakingController) Enqueue(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	var request EnqueueRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := controller.matchmaker.Enqueue(c.Param("id"), race.ID, request.MatchBy, request.AllowAI)
+	if err != nil {
+		matchmakingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetQueueEntries godoc
+// @Summary List the matchmaking queue entries of the authenticated race with their status, the newest first
+// @Tags matchmaking
+// @Produce json
+// @Success 200 {array} galaxy.QueueEntry
+// @Failure 401 {object} map[string]string
+// @Router /queue [get]
+func (controller *MatchmakingController) GetQueueEntries(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+	c.JSON(http.StatusOK, controller.matchmakingRepository.FindEntries(race.ID))
+}
+
+// GetQueueEntry godoc
+// @Summary Get the status of a matchmaking queue entry of the authenticated race
+// @Tags matchmaking
+// @Produce json
+// @Param entryId path string true "Queue entry ID"
+// @Success 200 {object} galaxy.QueueEntry
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /queue/{entryId} [get]
+func (controller *MatchmakingController) GetQueueEntry(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	entry := controller.matchmakingRepository.Get(c.Param("entryId"))
+	if entry == nil || entry.RaceId != race.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": game.ErrQueueEntryNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// CancelQueueEntry godoc
+// @Summary Leave the matchmaking queue
+// @Tags matchmaking
+// @Produce json
+// @Param entryId path string true "Queue entry ID"
+// @Success 200 {object} galaxy.QueueEntry
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /queue/{entryId} [delete]
+func (controller *MatchmakingController) CancelQueueEntry(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	entry, err := controller.matchmaker.Cancel(c.Param("entryId"), race.ID)
+	if err != nil {
+		matchmakingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetNotifications godoc
+// @Summary List the notifications of the authenticated race, e.g. the results of its matches
+// @Tags matchmaking
+// @Produce json
+// @Success 200 {array} galaxy.Notification
+// @Failure 401 {object} map[string]string
+// @Router /notifications [get]
+func (controller *MatchmakingController) GetNotifications(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	notifications := controller.matchmakingRepository.GetNotifications(race.ID)
+	if notifications == nil {
+		notifications = []*galaxy.Notification{}
+	}
+	c.JSON(http.StatusOK, notifications)
+}
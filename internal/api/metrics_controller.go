@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestMetrics counts the requests and their latencies by the route, and the requests rejected
+// for a missing, invalid or insufficient token.
+func RequestMetrics(registry *metrics.Registry) gin.HandlerFunc {
+	requests := registry.Counter("galaktika_http_requests_total", "HTTP requests by the method, the route and the status.", "method", "route", "status")
+	durations := registry.Histogram("galaktika_http_request_duration_seconds", "Latency of the HTTP requests by the method and the route.",
+		metrics.DURATION_BUCKETS, "method", "route")
+	authFailures := registry.Counter("galaktika_auth_failures_total", "Requests rejected with 401 or 403 by the status.", "status")
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// the unmatched paths are counted together, the paths of the clients are unbounded
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		requests.Inc(c.Request.Method, route, status)
+		durations.Observe(time.Since(start).Seconds(), c.Request.Method, route)
+		if c.Writer.Status() == http.StatusUnauthorized || c.Writer.Status() == http.StatusForbidden {
+			authFailures.Inc(status)
+		}
+	}
+}
+
+type MetricsController struct {
+	registry *metrics.Registry
+}
+
+func NewMetricsController(registry *metrics.Registry) *MetricsController {
+	return &MetricsController{registry: registry}
+}
+
+// Metrics writes the metrics in the Prometheus text format. Like the probes, it is served outside
+// of the API group and needs no token.
+func (controller *MetricsController) Metrics(c *gin.Context) {
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", metrics.TEXT_CONTENT_TYPE)
+	if err := controller.registry.WriteText(c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+// OptimizationRequest starts the search for the fleet builds beating a fleet of the division
+type OptimizationRequest struct {
+	OpponentFleetId string `json:"opponent_fleet_id" binding:"required"`
+	galaxy.OptimizationOptions
+}
+
+type OptimizationController struct {
+	authenticationManager AuthenticationManager
+	optimizationService   *game.OptimizationService
+}
+
+func NewOptimizationController(authenticationManager AuthenticationManager, optimizationService *game.OptimizationService) *OptimizationController {
+	return &OptimizationController{
+		authenticationManager: authenticationManager,
+		optimizationService:   optimizationService,
+	}
+}
+
+func optimizationError(c *gin.Context, err error) {
+	if errors.Is(err, game.ErrDivisionNotFound) || errors.Is(err, game.ErrFleetNotFound) || errors.Is(err, game.ErrOptimizationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, game.ErrOptimizationsStopped) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	validationFailed(c, "Optimization options validation failed", err)
+}
+
+// authenticate returns the race of the bearer token or responds with 401.
+func (controller *OptimizationController) authenticate(c *gin.Context) *galaxy.Race {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil
+	}
+
+	return controller.authenticationManager.Authenticate(token)
+}
+
+// StartOptimization godoc
+// @Summary Start the search for the fleet builds of the authenticated race beating a fleet of the division
+// @Description The job runs in the background, its progress and results are polled by its id.
+// @Tags optimizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param request body OptimizationRequest true "Opponent fleet and the optimization options"
+// @Success 202 {object} galaxy.OptimizationJob
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Failure 503 {object} map[string]string
+// @Router /divisions/{id}/optimizations [post]
+func (controller *OptimizationController) StartOptimization(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	var request OptimizationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := controller.optimizationService.Start(c.Param("id"), race.ID, request.OpponentFleetId, request.OptimizationOptions)
+	if err != nil {
+		optimizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetOptimizations godoc
+// @Summary List the optimization jobs of the authenticated race, the newest first
+// @Tags optimizations
+// @Produce json
+// @Success 200 {array} galaxy.OptimizationJob
+// @Failure 401 {object} map[string]string
+// @Router /optimizations [get]
+func (controller *OptimizationController) GetOptimizations(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+	c.JSON(http.StatusOK, controller.optimizationService.FindByRace(race.ID))
+}
+
+// GetOptimization godoc
+// @Summary Get the progress and the results of an optimization job of the authenticated race
+// @Tags optimizations
+// @Produce json
+// @Param id path string true "Optimization job ID"
+// @Success 200 {object} galaxy.OptimizationJob
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /optimizations/{id} [get]
+func (controller *OptimizationController) GetOptimization(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	job, err := controller.optimizationService.Get(c.Param("id"), race.ID)
+	if err != nil {
+		optimizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+type RatingController struct {
+	authenticationManager AuthenticationManager
+	ratingRepository      *dao.RatingRepository
+	divisionRepository    *dao.DivisionRepository
+	ratingService         *game.RatingService
+}
+
+func NewRatingController(
+	authenticationManager AuthenticationManager,
+	ratingRepository *dao.RatingRepository,
+	divisionRepository *dao.DivisionRepository,
+	ratingService *game.RatingService,
+) *RatingController {
+	return &RatingController{
+		authenticationManager: authenticationManager,
+		ratingRepository:      ratingRepository,
+		divisionRepository:    divisionRepository,
+		ratingService:         ratingService,
+	}
+}
+
+// GetGlobalLeaderboard godoc
+// @Summary Get the leaderboard of the global race ratings
+// @Tags ratings
+// @Produce json
+// @Success 200 {array} galaxy.LeaderboardEntry
+// @Router /ratings [get]
+func (controller *RatingController) GetGlobalLeaderboard(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.ratingService.Leaderboard(""))
+}
+
+// GetDivisionLeaderboard godoc
+// @Summary Get the leaderboard of the race ratings in a division
+// @Tags ratings
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {array} galaxy.LeaderboardEntry
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/ratings [get]
+func (controller *RatingController) GetDivisionLeaderboard(c *gin.Context) {
+	if controller.divisionRepository.Get(c.Param("id")) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+	c.JSON(http.StatusOK, controller.ratingService.Leaderboard(c.Param("id")))
+}
+
+// GetRatingHistory godoc
+// @Summary Get the rating changes of a race after its ranked battles, in the divisions and global
+// @Tags ratings
+// @Produce json
+// @Param id path string true "Race ID"
+// @Success 200 {array} galaxy.RatingChange
+// @Router /races/{id}/ratings/history [get]
+func (controller *RatingController) GetRatingHistory(c *gin.Context) {
+	history := controller.ratingRepository.GetHistory(c.Param("id"))
+	if history == nil {
+		history = []*galaxy.RatingChange{}
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// FindOpponents godoc
+// @Summary Find the opponents for the authenticated race in a division, the closest rating first
+// @Tags ratings
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {array} galaxy.MatchCandidate
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/opponents [get]
+func (controller *RatingController) FindOpponents(c *gin.Context) {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+	race := controller.authenticationManager.Authenticate(token)
+
+	if controller.divisionRepository.Get(c.Param("id")) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+		return
+	}
+	c.JSON(http.StatusOK, controller.ratingService.FindOpponents(c.Param("id"), race.ID))
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/pkg/util"
+	"log/slog"
+	"time"
+)
+
+const (
+	REQUEST_ID_HEADER = "X-Request-ID"
+	// longer request ids of the clients are replaced
+	MAX_REQUEST_ID_LENGTH = 64
+
+	loggerKey = "logger"
+)
+
+// RequestLogger gives each request a logger with the request id, the route and the race of the bearer token,
+// and logs the request when it is answered. The request id of a reverse proxy is kept and sent back.
+func RequestLogger(logger *slog.Logger, authenticationManager AuthenticationManager, idGenerator util.IdGenerator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestId := c.GetHeader(REQUEST_ID_HEADER)
+		if requestId == "" || len(requestId) > MAX_REQUEST_ID_LENGTH {
+			requestId = idGenerator.NextId()
+		}
+		c.Header(REQUEST_ID_HEADER, requestId)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requestLogger := logger.With("request_id", requestId, "method", c.Request.Method, "route", route)
+		if token := bearerToken(c); token != "" && authenticationManager.TokenValid(token) {
+			requestLogger = requestLogger.With("race_id", authenticationManager.Authenticate(token).ID)
+		}
+		c.Set(loggerKey, requestLogger)
+
+		c.Next()
+
+		level := slog.LevelInfo
+		switch {
+		case c.Writer.Status() >= 500:
+			level = slog.LevelError
+		case c.Writer.Status() >= 400:
+			level = slog.LevelWarn
+		}
+		args := []any{"status", c.Writer.Status(), "duration", time.Since(start), "path", c.Request.URL.Path}
+		if len(c.Errors) > 0 {
+			args = append(args, "errors", c.Errors.String())
+		}
+		requestLogger.Log(c.Request.Context(), level, "request", args...)
+	}
+}
+
+// Logger returns the logger of the request, the default logger outside of the RequestLogger middleware.
+func Logger(c *gin.Context) *slog.Logger {
+	if logger, ok := c.Get(loggerKey); ok {
+		return logger.(*slog.Logger)
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+)
+
+func TestRequestLogger(t *testing.T) {
+	tests := []struct {
+		name              string
+		path              string
+		token             string
+		requestId         string
+		expectedRequestId string
+		expectedFields    map[string]any
+	}{
+		{
+			name:              "race of the token",
+			path:              "/api/fleets/f1",
+			token:             "token-1",
+			expectedRequestId: "1",
+			expectedFields:    map[string]any{"route": "/api/fleets/:id", "race_id": "race-1", "status": float64(http.StatusOK), "level": "INFO"},
+		},
+		{
+			name:              "request id of the proxy",
+			path:              "/api/fleets/f1",
+			requestId:         "proxy-42",
+			expectedRequestId: "proxy-42",
+			expectedFields:    map[string]any{"request_id": "proxy-42", "race_id": nil},
+		},
+		{
+			name:              "too long request id",
+			path:              "/api/fleets/f1",
+			requestId:         strings.Repeat("x", MAX_REQUEST_ID_LENGTH+1),
+			expectedRequestId: "1",
+		},
+		{
+			name:              "unknown route",
+			path:              "/api/unknown",
+			token:             "invalid",
+			expectedRequestId: "1",
+			expectedFields:    map[string]any{"route": "unmatched", "race_id": nil, "status": float64(http.StatusNotFound), "level": "WARN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticationManager := NewMemoryAuthenticationManager()
+			authenticationManager.AddToken("token-1", &galaxy.Race{ID: "race-1"})
+			var output bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&output, nil))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(RequestLogger(logger, authenticationManager, &util.SimpleIdGenerator{}))
+			router.GET("/api/fleets/:id", func(c *gin.Context) {
+				Logger(c).Info("handled")
+				c.Status(http.StatusOK)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.requestId != "" {
+				request.Header.Set(REQUEST_ID_HEADER, tt.requestId)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, request)
+
+			if requestId := w.Header().Get(REQUEST_ID_HEADER); requestId != tt.expectedRequestId {
+				t.Errorf("expected request id %q, got %q", tt.expectedRequestId, requestId)
+			}
+
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			var record map[string]any
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+				t.Fatal(err)
+			}
+			if record["msg"] != "request" || record["request_id"] != tt.expectedRequestId {
+				t.Errorf("unexpected request record %v", record)
+			}
+			for field, expected := range tt.expectedFields {
+				if record[field] != expected {
+					t.Errorf("expected %s %v, got %v", field, expected, record[field])
+				}
+			}
+			// the handlers log with the fields of the request
+			if w.Code == http.StatusOK && !strings.Contains(lines[0], `"msg":"handled","request_id":"`+tt.expectedRequestId+`"`) {
+				t.Errorf("expected the handler record with the request id, got %s", lines[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"io"
+	"net/http"
+)
+
+type ScenarioController struct {
+	authenticationManager AuthenticationManager
+	scenarioLoader        *game.ScenarioLoader
+}
+
+func NewScenarioController(authenticationManager AuthenticationManager, scenarioLoader *game.ScenarioLoader) *ScenarioController {
+	return &ScenarioController{
+		authenticationManager: authenticationManager,
+		scenarioLoader:        scenarioLoader,
+	}
+}
+
+// ImportScenario godoc
+// @Summary Import a scenario of divisions, races, ship models and fleet builds
+// @Description The scenario is written in YAML or JSON. Nothing is stored when any part of it is invalid.
+// @Tags scenarios
+// @Accept json
+// @Accept x-yaml
+// @Produce json
+// @Param scenario body galaxy.Scenario true "Scenario"
+// @Success 201 {object} galaxy.ScenarioSummary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Router /scenarios [post]
+func (controller *ScenarioController) ImportScenario(c *gin.Context) {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+	if !controller.authenticationManager.Authenticate(token).IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scenario, err := galaxy.ParseScenario(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary, err := controller.scenarioLoader.Load(scenario)
+	if err != nil {
+		validationFailed(c, "Scenario validation failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, summary)
+}
@@ -5,15 +5,47 @@ import (
 	"glaktika.eu/galaktika/internal/dao"
 	"glaktika.eu/galaktika/pkg/galaxy"
 	"net/http"
+	"strconv"
 )
 
 type ShipModelController struct {
 	authenticationManager AuthenticationManager
 	shipModelRepository   *dao.ShipModelRepository
+	divisionRepository    *dao.DivisionRepository
 }
 
-func NewShipModelController(authenticationManager AuthenticationManager, repository *dao.ShipModelRepository) *ShipModelController {
-	return &ShipModelController{authenticationManager: authenticationManager, shipModelRepository: repository}
+func NewShipModelController(
+	authenticationManager AuthenticationManager,
+	repository *dao.ShipModelRepository,
+	divisionRepository *dao.DivisionRepository,
+) *ShipModelController {
+	return &ShipModelController{
+		authenticationManager: authenticationManager,
+		shipModelRepository:   repository,
+		divisionRepository:    divisionRepository,
+	}
+}
+
+// validate checks the ship model against the rules of the division given by the division_id query parameter,
+// or against the default rules. Responds with an error and returns false when the ship model is invalid.
+func (controller *ShipModelController) validate(c *gin.Context, shipModel *galaxy.ShipModel) bool {
+	rules := galaxy.DefaultValidationRules()
+	if divisionId := c.Query("division_id"); divisionId != "" {
+		division := controller.divisionRepository.Get(divisionId)
+		if division == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Division not found"})
+			return false
+		}
+		rules = division.ShipModelRules()
+	}
+
+	shipModel.MigrateModules()
+	if err := shipModel.ValidateModel(rules, nil); err != nil {
+		validationFailed(c, "Validation failed", err)
+		return false
+	}
+
+	return true
 }
 
 // GetShipModel godoc
@@ -55,8 +87,11 @@ func (controller *ShipModelController) GetAllShipModels(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param shipModel body galaxy.ShipModel true "ShipModel data"
+// @Param division_id query string false "Division whose rules are checked, default rules when empty"
 // @Success 201 {object} galaxy.ShipModel
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Router /ship-models [post]
 func (controller *ShipModelController) CreateShipModel(c *gin.Context) {
 	var shipModel galaxy.ShipModel
@@ -64,8 +99,10 @@ func (controller *ShipModelController) CreateShipModel(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	controller.shipModelRepository.Upsert(&shipModel)
-	c.JSON(http.StatusCreated, shipModel)
+	if !controller.validate(c, &shipModel) {
+		return
+	}
+	c.JSON(http.StatusCreated, controller.shipModelRepository.Upsert(&shipModel))
 }
 
 // UpdateShipModel godoc
@@ -75,9 +112,11 @@ func (controller *ShipModelController) CreateShipModel(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ShipModel ID"
 // @Param shipModel body galaxy.ShipModel true "ShipModel data"
+// @Param division_id query string false "Division whose rules are checked, default rules when empty"
 // @Success 200 {object} galaxy.ShipModel
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Router /ship-models/{id} [put]
 func (controller *ShipModelController) UpdateShipModel(c *gin.Context) {
 	race := controller.authenticationManager.AuthenticateFromContext(c)
@@ -101,8 +140,10 @@ func (controller *ShipModelController) UpdateShipModel(c *gin.Context) {
 
 	shipModel.ID = id
 	shipModel.OwnerId = race.ID
-	controller.shipModelRepository.Upsert(&shipModel)
-	c.JSON(http.StatusOK, shipModel)
+	if !controller.validate(c, &shipModel) {
+		return
+	}
+	c.JSON(http.StatusOK, controller.shipModelRepository.Upsert(&shipModel))
 }
 
 // CalculateShipTech godoc
@@ -151,3 +192,82 @@ func (controller *ShipModelController) DeleteShipModel(c *gin.Context) {
 	controller.shipModelRepository.Delete(id)
 	c.JSON(http.StatusOK, gin.H{"message": "ShipModel deleted successfully"})
 }
+
+// GetShipModelVersions godoc
+// @Summary List all versions of a ship model
+// @Tags ship-models
+// @Produce json
+// @Param id path string true "ShipModel ID"
+// @Success 200 {array} galaxy.ShipModel
+// @Failure 404 {object} map[string]string
+// @Router /ship-models/{id}/versions [get]
+func (controller *ShipModelController) GetShipModelVersions(c *gin.Context) {
+	versions := controller.shipModelRepository.GetVersions(c.Param("id"))
+	if len(versions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ShipModel not found"})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetShipModelVersion godoc
+// @Summary Get a version of a ship model
+// @Tags ship-models
+// @Produce json
+// @Param id path string true "ShipModel ID"
+// @Param version path int true "ShipModel version"
+// @Success 200 {object} galaxy.ShipModel
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /ship-models/{id}/versions/{version} [get]
+func (controller *ShipModelController) GetShipModelVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive number"})
+		return
+	}
+
+	shipModel := controller.shipModelRepository.GetVersion(c.Param("id"), version)
+	if shipModel == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ShipModel version not found"})
+		return
+	}
+	c.JSON(http.StatusOK, shipModel)
+}
+
+// DiffShipModelVersions godoc
+// @Summary Show the changes between two versions of a ship model
+// @Tags ship-models
+// @Produce json
+// @Param id path string true "ShipModel ID"
+// @Param from query int true "Version to compare from"
+// @Param to query int false "Version to compare to, the latest version when omitted"
+// @Success 200 {array} galaxy.ShipModelChange
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /ship-models/{id}/diff [get]
+func (controller *ShipModelController) DiffShipModelVersions(c *gin.Context) {
+	id := c.Param("id")
+
+	fromVersion, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a number"})
+		return
+	}
+	toVersion := 0
+	if c.Query("to") != "" {
+		if toVersion, err = strconv.Atoi(c.Query("to")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a number"})
+			return
+		}
+	}
+
+	from := controller.shipModelRepository.GetVersion(id, fromVersion)
+	to := controller.shipModelRepository.GetVersion(id, toVersion)
+	if from == nil || to == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ShipModel version not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, galaxy.DiffShipModels(from, to))
+}
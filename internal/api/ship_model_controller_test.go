@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -17,7 +18,11 @@ func setupShipModelRouter(controller *ShipModelController) *gin.Engine {
 	router := gin.New()
 	apiGroup := router.Group("/api")
 	apiGroup.GET("/ship-models/:id", controller.GetShipModel)
+	apiGroup.POST("/ship-models", controller.CreateShipModel)
 	apiGroup.PUT("/ship-models/:id", controller.UpdateShipModel)
+	apiGroup.GET("/ship-models/:id/versions", controller.GetShipModelVersions)
+	apiGroup.GET("/ship-models/:id/versions/:version", controller.GetShipModelVersion)
+	apiGroup.GET("/ship-models/:id/diff", controller.DiffShipModelVersions)
 	return router
 }
 
@@ -48,8 +53,14 @@ func getShipModelTestCases() []getShipModelTestCase {
 			},
 			expectedStatus: http.StatusOK,
 			expectedShipModel: &galaxy.ShipModel{
-				ID:          "sm-1",
-				Name:        "Fighter",
+				ID:      "sm-1",
+				Version: 1,
+				Name:    "Fighter",
+				Modules: []galaxy.ShipModule{
+					{Type: galaxy.MODULE_WEAPON, Count: 4, Mass: 2},
+					{Type: galaxy.MODULE_DEFENSE, Mass: 8},
+					{Type: galaxy.MODULE_ENGINE, Mass: 16},
+				},
 				Guns:        4,
 				OneGunMass:  2,
 				DefenseMass: 8,
@@ -78,7 +89,7 @@ func TestGetShipModel(t *testing.T) {
 				repo.Upsert(stored)
 			}
 
-			controller := NewShipModelController(NewMemoryAuthenticationManager(), repo)
+			controller := NewShipModelController(NewMemoryAuthenticationManager(), repo, dao.NewDivisionRepository())
 			router := setupShipModelRouter(controller)
 
 			req := httptest.NewRequest(http.MethodGet, "/api/ship-models/"+id, nil)
@@ -94,7 +105,7 @@ func TestGetShipModel(t *testing.T) {
 				if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
 					t.Fatalf("failed to unmarshal response: %v", err)
 				}
-				if got != *expectedModel {
+				if !reflect.DeepEqual(got, *expectedModel) {
 					t.Errorf("expected %+v, got %+v", *expectedModel, got)
 				}
 			}
@@ -105,11 +116,11 @@ func TestGetShipModel(t *testing.T) {
 // --- UpdateShipModel ---
 
 type updateShipModelTestCase struct {
-	name            string
-	id              string
-	storedShipModel *galaxy.ShipModel
-	body            string
-	headers         map[string]string
+	name              string
+	id                string
+	storedShipModel   *galaxy.ShipModel
+	body              string
+	headers           map[string]string
 	expectedStatus    int
 	expectedShipModel *galaxy.ShipModel
 }
@@ -133,8 +144,14 @@ func buildUpdateShipModelTestCases() []updateShipModelTestCase {
 			headers:        map[string]string{"Authorization": "Bearer test-token"},
 			expectedStatus: http.StatusOK,
 			expectedShipModel: &galaxy.ShipModel{
-				ID:          "sm-1",
-				Name:        "Heavy Fighter",
+				ID:      "sm-1",
+				Version: 2,
+				Name:    "Heavy Fighter",
+				Modules: []galaxy.ShipModule{
+					{Type: galaxy.MODULE_WEAPON, Count: 8, Mass: 2},
+					{Type: galaxy.MODULE_DEFENSE, Mass: 16},
+					{Type: galaxy.MODULE_ENGINE, Mass: 16},
+				},
 				Guns:        8,
 				OneGunMass:  2,
 				DefenseMass: 16,
@@ -195,7 +212,7 @@ func TestUpdateShipModel(t *testing.T) {
 				repo.Upsert(stored)
 			}
 
-			controller := NewShipModelController(auth, repo)
+			controller := NewShipModelController(auth, repo, dao.NewDivisionRepository())
 			router := setupShipModelRouter(controller)
 
 			req := httptest.NewRequest(http.MethodPut, "/api/ship-models/"+id, strings.NewReader(body))
@@ -215,10 +232,172 @@ func TestUpdateShipModel(t *testing.T) {
 				if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
 					t.Fatalf("failed to unmarshal response: %v", err)
 				}
-				if got != *expectedModel {
+				if !reflect.DeepEqual(got, *expectedModel) {
 					t.Errorf("expected %+v, got %+v", *expectedModel, got)
 				}
 			}
 		})
 	}
 }
+
+// --- Versions ---
+
+func TestShipModelVersions(t *testing.T) {
+	repo := dao.NewShipModelRepository()
+	repo.Upsert(&galaxy.ShipModel{ID: "sm-1", Name: "Fighter", Guns: 4, OneGunMass: 2, EngineMass: 16, OwnerId: "race-1"})
+	repo.Upsert(&galaxy.ShipModel{ID: "sm-1", Name: "Fighter", Guns: 6, OneGunMass: 2, EngineMass: 16, CargoMass: 2, OwnerId: "race-1"})
+
+	controller := NewShipModelController(NewMemoryAuthenticationManager(), repo, dao.NewDivisionRepository())
+	router := setupShipModelRouter(controller)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		validate       func(t *testing.T, body []byte)
+	}{
+		{
+			name:           "lists versions",
+			path:           "/api/ship-models/sm-1/versions",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, body []byte) {
+				var versions []galaxy.ShipModel
+				if err := json.Unmarshal(body, &versions); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if len(versions) != 2 || versions[0].Version != 1 || versions[0].Guns != 4 || versions[1].Version != 2 || versions[1].Guns != 6 {
+					t.Errorf("unexpected versions %+v", versions)
+				}
+			},
+		},
+		{
+			name:           "gets old version",
+			path:           "/api/ship-models/sm-1/versions/1",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, body []byte) {
+				var shipModel galaxy.ShipModel
+				if err := json.Unmarshal(body, &shipModel); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if shipModel.Version != 1 || shipModel.Guns != 4 {
+					t.Errorf("unexpected ship model %+v", shipModel)
+				}
+			},
+		},
+		{
+			name:           "diffs versions",
+			path:           "/api/ship-models/sm-1/diff?from=1&to=2",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, body []byte) {
+				var changes []galaxy.ShipModelChange
+				if err := json.Unmarshal(body, &changes); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if len(changes) != 2 || changes[0].Field != "modules[0].count" || changes[1].Field != "modules[2]" {
+					t.Errorf("unexpected changes %+v", changes)
+				}
+			},
+		},
+		{
+			name:           "returns 404 for missing version",
+			path:           "/api/ship-models/sm-1/versions/3",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "returns 400 for invalid diff query",
+			path:           "/api/ship-models/sm-1/diff?from=first",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+			if tc.validate != nil {
+				tc.validate(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+// --- Validation ---
+
+func TestCreateShipModelValidation(t *testing.T) {
+	divisionRepo := dao.NewDivisionRepository()
+	divisionRepo.Upsert(&galaxy.Division{ID: "d1", ValidationRules: &galaxy.ValidationRules{MaxGuns: 2}})
+
+	controller := NewShipModelController(NewMemoryAuthenticationManager(), dao.NewShipModelRepository(), divisionRepo)
+	router := setupShipModelRouter(controller)
+
+	tests := []struct {
+		name               string
+		path               string
+		body               string
+		expectedStatus     int
+		expectedViolations []galaxy.Violation
+	}{
+		{
+			name:           "creates valid ship model",
+			path:           "/api/ship-models",
+			body:           `{"id":"sm-1","name":"Fighter","guns":4,"one_gun_mass":2,"engine_mass":4}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "returns all violations",
+			path:           "/api/ship-models",
+			body:           `{"id":"sm-2","modules":[{"type":"weapon","count":1,"mass":0.5},{"type":"engine","mass":-1}]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedViolations: []galaxy.Violation{
+				{Field: "modules[0].mass", Code: galaxy.VIOLATION_MASS_TOO_LOW, Message: "must be equal to 0 or not lower than 1"},
+				{Field: "modules[1].mass", Code: galaxy.VIOLATION_NEGATIVE_VALUE, Message: "must not be negative"},
+			},
+		},
+		{
+			name:           "applies division rules",
+			path:           "/api/ship-models?division_id=d1",
+			body:           `{"id":"sm-3","guns":4,"one_gun_mass":2,"engine_mass":4}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedViolations: []galaxy.Violation{
+				{Field: "guns", Code: galaxy.VIOLATION_TOO_MANY_GUNS, Message: "4 guns exceed the limit of 2"},
+			},
+		},
+		{
+			name:           "returns 404 for unknown division",
+			path:           "/api/ship-models?division_id=missing",
+			body:           `{"id":"sm-4","guns":1,"one_gun_mass":2}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedViolations != nil {
+				var payload struct {
+					Error      string             `json:"error"`
+					Violations []galaxy.Violation `json:"violations"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if payload.Error != "Validation failed" || !reflect.DeepEqual(payload.Violations, tc.expectedViolations) {
+					t.Errorf("unexpected payload %+v", payload)
+				}
+			}
+		})
+	}
+}
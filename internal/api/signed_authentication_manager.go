@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"strings"
+)
+
+// SignedAuthenticationManager accepts the tokens of the races known by the next manager and the tokens
+// signed by the signing keys. A signed token is the race id and the hex HMAC-SHA256 of the race id
+// joined by a dot. The first key signs, all the keys verify so the keys can be rotated.
+type SignedAuthenticationManager struct {
+	next AuthenticationManager
+	keys [][]byte
+}
+
+func NewSignedAuthenticationManager(next AuthenticationManager, keys []string) *SignedAuthenticationManager {
+	manager := &SignedAuthenticationManager{next: next}
+	for _, key := range keys {
+		manager.keys = append(manager.keys, []byte(key))
+	}
+
+	return manager
+}
+
+// Sign returns the token of the race signed by the first key.
+func (am *SignedAuthenticationManager) Sign(raceId string) string {
+	return raceId + "." + hex.EncodeToString(signature(am.keys[0], raceId))
+}
+
+func signature(key []byte, raceId string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(raceId))
+
+	return mac.Sum(nil)
+}
+
+// Authenticate returns the race of the token, the race of a signed token must be known by the next manager.
+func (am *SignedAuthenticationManager) Authenticate(token string) *galaxy.Race {
+	if race := am.next.Authenticate(token); race != nil {
+		return race
+	}
+
+	// the race id may contain dots, the signature does not
+	dot := strings.LastIndex(token, ".")
+	if dot < 0 {
+		return nil
+	}
+	raceId := token[:dot]
+	tokenSignature, err := hex.DecodeString(token[dot+1:])
+	if err != nil {
+		return nil
+	}
+	for _, key := range am.keys {
+		if hmac.Equal(tokenSignature, signature(key, raceId)) {
+			return am.next.FindRace(raceId)
+		}
+	}
+
+	return nil
+}
+
+func (am *SignedAuthenticationManager) AuthenticateFromContext(c *gin.Context) *galaxy.Race {
+	return am.Authenticate(bearerToken(c))
+}
+
+func (am *SignedAuthenticationManager) TokenValid(token string) bool {
+	return am.Authenticate(token) != nil
+}
+
+func (am *SignedAuthenticationManager) AddToken(token string, race *galaxy.Race) {
+	am.next.AddToken(token, race)
+}
+
+func (am *SignedAuthenticationManager) FindRace(id string) *galaxy.Race {
+	return am.next.FindRace(id)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+
+	"glaktika.eu/galaktika/pkg/galaxy"
+)
+
+func TestSignedAuthenticationManager_Authenticate(t *testing.T) {
+	memory := NewMemoryAuthenticationManager()
+	memory.AddToken("token-rex", &galaxy.Race{ID: "rex"})
+
+	oldKey := "old-signing-key-of-at-least-32-bytes"
+	newKey := "new-signing-key-of-at-least-32-bytes"
+	previous := NewSignedAuthenticationManager(memory, []string{oldKey})
+	manager := NewSignedAuthenticationManager(memory, []string{newKey, oldKey})
+
+	tests := []struct {
+		name     string
+		token    string
+		wantRace string
+	}{
+		{name: "token of the next manager", token: "token-rex", wantRace: "rex"},
+		{name: "signed token", token: manager.Sign("rex"), wantRace: "rex"},
+		{name: "token signed by the rotated key", token: previous.Sign("rex"), wantRace: "rex"},
+		{name: "signed token of an unknown race", token: manager.Sign("zyx")},
+		{name: "token of another race", token: "zyx" + manager.Sign("rex")[len("rex"):]},
+		{name: "token without signature", token: "rex"},
+		{name: "malformed signature", token: "rex.not-hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			race := manager.Authenticate(tt.token)
+			if tt.wantRace == "" {
+				if race != nil || manager.TokenValid(tt.token) {
+					t.Errorf("expected token %q to be rejected, got %+v", tt.token, race)
+				}
+				return
+			}
+			if race == nil || race.ID != tt.wantRace || !manager.TokenValid(tt.token) {
+				t.Errorf("expected race %s, got %+v", tt.wantRace, race)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+type TechTreeController struct {
+	techTree *galaxy.TechTree
+}
+
+func NewTechTreeController(techTree *galaxy.TechTree) *TechTreeController {
+	return &TechTreeController{techTree: techTree}
+}
+
+// GetTechTree godoc
+// @Summary Get the tech tree with node costs, prerequisites and unlocked components
+// @Tags tech-tree
+// @Produce json
+// @Success 200 {object} galaxy.TechTree
+// @Router /tech-tree [get]
+func (controller *TechTreeController) GetTechTree(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.techTree)
+}
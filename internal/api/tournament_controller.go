@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+)
+
+// CreateTournamentRequest starts a tournament of the division fleets
+type CreateTournamentRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Format string `json:"format" binding:"required" example:"round_robin"`
+	// seed of the bracket and the battles, derived from the tournament ID when 0
+	Seed uint64 `json:"seed"`
+}
+
+type TournamentController struct {
+	authenticationManager AuthenticationManager
+	tournamentRepository  *dao.TournamentRepository
+	tournamentService     *game.TournamentService
+}
+
+func NewTournamentController(
+	authenticationManager AuthenticationManager,
+	tournamentRepository *dao.TournamentRepository,
+	tournamentService *game.TournamentService,
+) *TournamentController {
+	return &TournamentController{
+		authenticationManager: authenticationManager,
+		tournamentRepository:  tournamentRepository,
+		tournamentService:     tournamentService,
+	}
+}
+
+func tournamentError(c *gin.Context, err error) {
+	if errors.Is(err, game.ErrDivisionNotFound) || errors.Is(err, game.ErrTournamentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// authenticateAdmin returns the race of the bearer token when it is an admin, otherwise responds with 401 or 403.
+func (controller *TournamentController) authenticateAdmin(c *gin.Context) *galaxy.Race {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil
+	}
+
+	race := controller.authenticationManager.Authenticate(token)
+	if !race.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return nil
+	}
+
+	return race
+}
+
+// CreateTournament godoc
+// @Summary Create a tournament of the race fleets of a division and schedule its matches (admin only)
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param tournament body CreateTournamentRequest true "Tournament name, format (round_robin or single_elimination) and seed"
+// @Success 201 {object} galaxy.Tournament
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/tournaments [post]
+func (controller *TournamentController) CreateTournament(c *gin.Context) {
+	if controller.authenticateAdmin(c) == nil {
+		return
+	}
+
+	var request CreateTournamentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tournament, err := controller.tournamentService.Create(c.Param("id"), request.Name, request.Format, request.Seed)
+	if err != nil {
+		tournamentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tournament)
+}
+
+// GetTournaments godoc
+// @Summary List the tournaments of a division, the newest first
+// @Tags tournaments
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {array} galaxy.Tournament
+// @Router /divisions/{id}/tournaments [get]
+func (controller *TournamentController) GetTournaments(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.tournamentRepository.FindByDivision(c.Param("id")))
+}
+
+// GetTournament godoc
+// @Summary Get a tournament with its matches and standings
+// @Tags tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} galaxy.Tournament
+// @Failure 404 {object} map[string]string
+// @Router /tournaments/{id} [get]
+func (controller *TournamentController) GetTournament(c *gin.Context) {
+	tournament := controller.tournamentRepository.Get(c.Param("id"))
+	if tournament == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
+
+// GetStandings godoc
+// @Summary Get the standings table of a tournament
+// @Tags tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {array} galaxy.Standing
+// @Failure 404 {object} map[string]string
+// @Router /tournaments/{id}/standings [get]
+func (controller *TournamentController) GetStandings(c *gin.Context) {
+	tournament := controller.tournamentRepository.Get(c.Param("id"))
+	if tournament == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	c.JSON(http.StatusOK, tournament.Standings)
+}
+
+// RunTournament godoc
+// @Summary Play all the matches of a tournament and publish the standings (admin only)
+// @Tags tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} galaxy.Tournament
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tournaments/{id}/run [post]
+func (controller *TournamentController) RunTournament(c *gin.Context) {
+	if controller.authenticateAdmin(c) == nil {
+		return
+	}
+
+	tournament, err := controller.tournamentService.Run(c.Param("id"))
+	if err != nil {
+		tournamentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
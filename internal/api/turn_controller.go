@@ -0,0 +1,209 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"net/http"
+	"strconv"
+)
+
+type TurnController struct {
+	authenticationManager AuthenticationManager
+	turnRepository        *dao.TurnRepository
+	turnService           *game.TurnService
+}
+
+func NewTurnController(
+	authenticationManager AuthenticationManager,
+	turnRepository *dao.TurnRepository,
+	turnService *game.TurnService,
+) *TurnController {
+	return &TurnController{
+		authenticationManager: authenticationManager,
+		turnRepository:        turnRepository,
+		turnService:           turnService,
+	}
+}
+
+// turnError responds with the status matching the turn service error.
+func turnError(c *gin.Context, err error) {
+	if errors.Is(err, game.ErrDivisionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// authenticate returns the race of the bearer token or responds with 401.
+func (controller *TurnController) authenticate(c *gin.Context) *galaxy.Race {
+	token := bearerToken(c)
+	if !controller.authenticationManager.TokenValid(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil
+	}
+
+	return controller.authenticationManager.Authenticate(token)
+}
+
+// GetTurn godoc
+// @Summary Get the current turn of a division
+// @Tags turns
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {object} galaxy.TurnState
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/turn [get]
+func (controller *TurnController) GetTurn(c *gin.Context) {
+	state, err := controller.turnService.CurrentTurn(c.Param("id"))
+	if err != nil {
+		turnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// SubmitOrder godoc
+// @Summary Submit an order for the current turn of a division
+// @Tags turns
+// @Accept json
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param order body galaxy.Order true "Order data"
+// @Success 201 {object} galaxy.Order
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/orders [post]
+func (controller *TurnController) SubmitOrder(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	var order galaxy.Order
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order.DivisionId = c.Param("id")
+	order.RaceId = race.ID
+
+	if err := controller.turnService.SubmitOrder(&order); err != nil {
+		turnError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// GetOrders godoc
+// @Summary List the orders of the race for the current turn of a division
+// @Tags turns
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {array} galaxy.Order
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/orders [get]
+func (controller *TurnController) GetOrders(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	orders, err := controller.turnService.GetOrders(c.Param("id"), race.ID)
+	if err != nil {
+		turnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// CancelOrder godoc
+// @Summary Cancel an order of the current turn
+// @Tags turns
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param orderId path string true "Order ID"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/orders/{orderId} [delete]
+func (controller *TurnController) CancelOrder(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+
+	if err := controller.turnService.CancelOrder(c.Param("id"), race.ID, c.Param("orderId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Order cancelled successfully"})
+}
+
+// AdvanceTurn godoc
+// @Summary End the current turn of a division and resolve its orders (admin only)
+// @Tags turns
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {object} galaxy.TurnReport
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/turns/advance [post]
+func (controller *TurnController) AdvanceTurn(c *gin.Context) {
+	race := controller.authenticate(c)
+	if race == nil {
+		return
+	}
+	if !race.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	report, err := controller.turnService.AdvanceTurn(c.Param("id"))
+	if err != nil {
+		turnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTurnReports godoc
+// @Summary List the reports of the resolved turns of a division
+// @Tags turns
+// @Produce json
+// @Param id path string true "Division ID"
+// @Success 200 {array} galaxy.TurnReport
+// @Router /divisions/{id}/turns [get]
+func (controller *TurnController) GetTurnReports(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.turnRepository.GetReports(c.Param("id")))
+}
+
+// GetTurnReport godoc
+// @Summary Get the report of a resolved turn of a division
+// @Tags turns
+// @Produce json
+// @Param id path string true "Division ID"
+// @Param turn path int true "Turn number"
+// @Success 200 {object} galaxy.TurnReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /divisions/{id}/turns/{turn} [get]
+func (controller *TurnController) GetTurnReport(c *gin.Context) {
+	turn, err := strconv.Atoi(c.Param("turn"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "turn must be a number"})
+		return
+	}
+
+	report := controller.turnRepository.GetReport(c.Param("id"), turn)
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Turn report not found"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
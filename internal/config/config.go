@@ -0,0 +1,308 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"glaktika.eu/galaktika/internal/logging"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Environments of the server
+const (
+	// seeded with the sample divisions, races and tokens
+	ENV_DEV = "dev"
+	// like dev, used by the integration tests
+	ENV_TEST = "test"
+	// starts without any data, the races and the divisions come from a scenario or an archive
+	ENV_PROD = "prod"
+)
+
+// Storage backends, only the in-memory repositories are implemented
+const (
+	STORAGE_MEMORY = "memory"
+)
+
+var LOG_LEVELS = []string{"debug", "info", "warn", "error"}
+
+// MIN_SIGNING_KEY_LENGTH is the minimal length of the auth signing keys, in bytes.
+const MIN_SIGNING_KEY_LENGTH = 32
+
+// Config is the configuration of the server. The defaults are overridden by the config file,
+// the GALAKTIKA_* environment variables and the command line flags, in this order.
+type Config struct {
+	Environment string `json:"environment"`
+	// host:port the server listens on
+	Listen string `json:"listen"`
+	// the server uses HTTPS when both the certificate and the key are set
+	TLSCertFile string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `json:"tls_key_file,omitempty"`
+	// directories of the web pages and their assets
+	PagesDir  string `json:"pages_dir"`
+	AssetsDir string `json:"assets_dir"`
+	// origins allowed to call the API from a browser, "*" allows any origin
+	CORSOrigins []string `json:"cors_origins,omitempty"`
+	LogLevel    string   `json:"log_level"`
+	// text or json
+	LogFormat string `json:"log_format"`
+	// YAML or JSON scenario file loaded at the startup
+	Scenario string `json:"scenario,omitempty"`
+	// time for the requests in progress and the background jobs to finish when the server stops
+	ShutdownSeconds int `json:"shutdown_seconds"`
+
+	Storage StorageConfig `json:"storage"`
+	Auth    AuthConfig    `json:"auth"`
+	Battle  BattleConfig  `json:"battle"`
+}
+
+type StorageConfig struct {
+	Backend string `json:"backend"`
+}
+
+type AuthConfig struct {
+	// keys of the signed tokens, the first key signs, all keys verify so the keys can be rotated
+	SigningKeys []string `json:"signing_keys,omitempty"`
+}
+
+// BattleConfig ends the battles which do not finish by themselves.
+type BattleConfig struct {
+	MaxShots int `json:"max_shots"`
+	// the battle is a stalemate after this many shots in a row destroyed nothing
+	StalemateShots int `json:"stalemate_shots"`
+}
+
+func Default() *Config {
+	return &Config{
+		Environment: ENV_DEV,
+		Listen:      ":8080",
+		PagesDir:    "./pages",
+		AssetsDir:   "./assets",
+		LogLevel:    "info",
+		LogFormat:   logging.FORMAT_TEXT,
+		Storage:     StorageConfig{Backend: STORAGE_MEMORY},
+		Battle:      BattleConfig{MaxShots: 10000, StalemateShots: 100},
+
+		ShutdownSeconds: 30,
+	}
+}
+
+// setting is a value which can be set by an environment variable and a flag.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(config *Config, value string) error
+}
+
+var settings = []setting{
+	{"env", "GALAKTIKA_ENV", "environment: dev, test or prod", func(config *Config, value string) error {
+		config.Environment = value
+		return nil
+	}},
+	{"listen", "GALAKTIKA_LISTEN", "host:port the server listens on", func(config *Config, value string) error {
+		config.Listen = value
+		return nil
+	}},
+	{"tls-cert", "GALAKTIKA_TLS_CERT", "TLS certificate file", func(config *Config, value string) error {
+		config.TLSCertFile = value
+		return nil
+	}},
+	{"tls-key", "GALAKTIKA_TLS_KEY", "TLS private key file", func(config *Config, value string) error {
+		config.TLSKeyFile = value
+		return nil
+	}},
+	{"pages-dir", "GALAKTIKA_PAGES_DIR", "directory of the web pages", func(config *Config, value string) error {
+		config.PagesDir = value
+		return nil
+	}},
+	{"assets-dir", "GALAKTIKA_ASSETS_DIR", "directory of the web assets", func(config *Config, value string) error {
+		config.AssetsDir = value
+		return nil
+	}},
+	{"cors-origins", "GALAKTIKA_CORS_ORIGINS", "comma separated origins allowed to call the API", func(config *Config, value string) error {
+		config.CORSOrigins = splitList(value)
+		return nil
+	}},
+	{"log-level", "GALAKTIKA_LOG_LEVEL", "log level: debug, info, warn or error", func(config *Config, value string) error {
+		config.LogLevel = value
+		return nil
+	}},
+	{"log-format", "GALAKTIKA_LOG_FORMAT", "log format: text or json", func(config *Config, value string) error {
+		config.LogFormat = value
+		return nil
+	}},
+	{"scenario", "GALAKTIKA_SCENARIO", "YAML or JSON scenario file loaded at the startup", func(config *Config, value string) error {
+		config.Scenario = value
+		return nil
+	}},
+	{"shutdown-seconds", "GALAKTIKA_SHUTDOWN_SECONDS", "seconds to finish the requests and the jobs when the server stops", func(config *Config, value string) error {
+		return parseInt(value, &config.ShutdownSeconds)
+	}},
+	{"storage", "GALAKTIKA_STORAGE", "storage backend", func(config *Config, value string) error {
+		config.Storage.Backend = value
+		return nil
+	}},
+	{"signing-keys", "GALAKTIKA_SIGNING_KEYS", "comma separated auth signing keys, the first one signs", func(config *Config, value string) error {
+		config.Auth.SigningKeys = splitList(value)
+		return nil
+	}},
+	{"max-shots", "GALAKTIKA_MAX_SHOTS", "maximal number of shots of a battle", func(config *Config, value string) error {
+		return parseInt(value, &config.Battle.MaxShots)
+	}},
+	{"stalemate-shots", "GALAKTIKA_STALEMATE_SHOTS", "shots without a destroyed ship ending a battle as a stalemate", func(config *Config, value string) error {
+		return parseInt(value, &config.Battle.StalemateShots)
+	}},
+}
+
+// Load builds the config of the server from the defaults, the config file, the environment variables
+// and the command line arguments. The config file is given by the -config flag or GALAKTIKA_CONFIG_FILE.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	file := flags.String("config", getenv("GALAKTIKA_CONFIG_FILE"), "YAML or JSON config file")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		flags.Func(s.flag, s.usage+" ("+s.env+")", func(value string) error {
+			flagValues[s.flag] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+
+	config := Default()
+	if *file != "" {
+		if err := config.readFile(*file); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.set(config, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagValues[s.flag]; ok {
+			if err := s.set(config, value); err != nil {
+				return nil, fmt.Errorf("-%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// readFile overrides the config by the values of the file, unknown keys are rejected.
+func (config *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalWithOptions(data, config, yaml.Strict()); err != nil {
+		return fmt.Errorf("config file %s: %s", path, yaml.FormatError(err, false, true))
+	}
+
+	return nil
+}
+
+// Validate checks the server can start with the config, all the problems are reported together.
+func (config *Config) Validate() error {
+	var errs []error
+
+	switch config.Environment {
+	case ENV_DEV, ENV_TEST, ENV_PROD:
+	default:
+		errs = append(errs, fmt.Errorf("unknown environment %q", config.Environment))
+	}
+
+	if _, _, err := net.SplitHostPort(config.Listen); err != nil {
+		errs = append(errs, fmt.Errorf("listen address %q: %w", config.Listen, err))
+	}
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		errs = append(errs, errors.New("the TLS certificate and key files must be set together"))
+	}
+	for _, path := range []string{config.TLSCertFile, config.TLSKeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("TLS file: %w", err))
+		}
+	}
+
+	for _, origin := range config.CORSOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("CORS origin %q must be * or start with http:// or https://", origin))
+		}
+	}
+	if !slices.Contains(LOG_LEVELS, config.LogLevel) {
+		errs = append(errs, fmt.Errorf("unknown log level %q, the levels are %v", config.LogLevel, LOG_LEVELS))
+	}
+	if config.LogFormat != logging.FORMAT_TEXT && config.LogFormat != logging.FORMAT_JSON {
+		errs = append(errs, fmt.Errorf("unknown log format %q, the formats are %s and %s", config.LogFormat, logging.FORMAT_TEXT, logging.FORMAT_JSON))
+	}
+
+	if config.ShutdownSeconds < 0 {
+		errs = append(errs, fmt.Errorf("shutdown seconds must not be negative, got %d", config.ShutdownSeconds))
+	}
+
+	if config.Storage.Backend != STORAGE_MEMORY {
+		errs = append(errs, fmt.Errorf("storage backend %q is not implemented, the only backend is %s", config.Storage.Backend, STORAGE_MEMORY))
+	}
+
+	for i, key := range config.Auth.SigningKeys {
+		if len(key) < MIN_SIGNING_KEY_LENGTH {
+			errs = append(errs, fmt.Errorf("signing key %d is shorter than %d bytes", i+1, MIN_SIGNING_KEY_LENGTH))
+		}
+	}
+
+	if config.Battle.MaxShots < 1 {
+		errs = append(errs, fmt.Errorf("battle max shots must be positive, got %d", config.Battle.MaxShots))
+	}
+	if config.Battle.StalemateShots < 1 {
+		errs = append(errs, fmt.Errorf("battle stalemate shots must be positive, got %d", config.Battle.StalemateShots))
+	}
+
+	return errors.Join(errs...)
+}
+
+// TLS reports whether the server uses HTTPS.
+func (config *Config) TLS() bool {
+	return config.TLSCertFile != "" && config.TLSKeyFile != ""
+}
+
+// Seeded reports whether the repositories start with the sample data.
+func (config *Config) Seeded() bool {
+	return config.Environment != ENV_PROD
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseInt(value string, target *int) error {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	*target = number
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	file := writeFile(t, "server.yaml", `
+environment: prod
+listen: ":9000"
+log_level: warn
+cors_origins: ["https://file.example"]
+battle:
+  max_shots: 500
+`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected func(config *Config) bool
+	}{
+		{
+			name: "defaults",
+			expected: func(config *Config) bool {
+				return config.Listen == ":8080" && config.Environment == ENV_DEV && config.Battle.MaxShots == 10000
+			},
+		},
+		{
+			name: "file",
+			args: []string{"-config", file},
+			expected: func(config *Config) bool {
+				return config.Environment == ENV_PROD && config.Listen == ":9000" && config.Battle.MaxShots == 500 &&
+					config.Battle.StalemateShots == 100 && config.Storage.Backend == STORAGE_MEMORY
+			},
+		},
+		{
+			name:     "file from the environment",
+			env:      map[string]string{"GALAKTIKA_CONFIG_FILE": file},
+			expected: func(config *Config) bool { return config.Listen == ":9000" },
+		},
+		{
+			name: "environment overrides the file",
+			args: []string{"-config", file},
+			env:  map[string]string{"GALAKTIKA_LISTEN": ":9100", "GALAKTIKA_CORS_ORIGINS": "https://a.example, https://b.example", "GALAKTIKA_MAX_SHOTS": "50"},
+			expected: func(config *Config) bool {
+				return config.Listen == ":9100" && config.Battle.MaxShots == 50 && config.LogLevel == "warn" &&
+					slices.Equal(config.CORSOrigins, []string{"https://a.example", "https://b.example"})
+			},
+		},
+		{
+			name:     "flags override the environment",
+			args:     []string{"-config", file, "-listen", ":9200", "-stalemate-shots", "20"},
+			env:      map[string]string{"GALAKTIKA_LISTEN": ":9100", "GALAKTIKA_STALEMATE_SHOTS": "30"},
+			expected: func(config *Config) bool { return config.Listen == ":9200" && config.Battle.StalemateShots == 20 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := Load(tt.args, func(key string) string { return tt.env[key] })
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !tt.expected(config) {
+				t.Errorf("unexpected config %+v", config)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected string
+	}{
+		{name: "unknown flag", args: []string{"-port", "80"}, expected: "-port"},
+		{name: "not a number", env: map[string]string{"GALAKTIKA_MAX_SHOTS": "many"}, expected: "GALAKTIKA_MAX_SHOTS"},
+		{name: "missing file", args: []string{"-config", "missing.yaml"}, expected: "missing.yaml"},
+		{name: "unknown key", args: []string{"-config", writeFile(t, "server.yaml", "port: 80\n")}, expected: "port"},
+		{name: "arguments", args: []string{"serve"}, expected: "unexpected arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.args, func(key string) string { return tt.env[key] })
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected an error about %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cert := writeFile(t, "cert.pem", "cert")
+
+	tests := []struct {
+		name     string
+		change   func(config *Config)
+		expected []string
+	}{
+		{name: "default", change: func(config *Config) {}},
+		{name: "tls", change: func(config *Config) { config.TLSCertFile, config.TLSKeyFile = cert, cert }},
+		{name: "any origin", change: func(config *Config) { config.CORSOrigins = []string{"*"} }},
+		{
+			name:     "environment",
+			change:   func(config *Config) { config.Environment = "staging" },
+			expected: []string{"unknown environment"},
+		},
+		{
+			name:     "listen address",
+			change:   func(config *Config) { config.Listen = "8080" },
+			expected: []string{"listen address"},
+		},
+		{
+			name:     "tls key without certificate",
+			change:   func(config *Config) { config.TLSKeyFile = cert },
+			expected: []string{"set together"},
+		},
+		{
+			name:     "missing tls files",
+			change:   func(config *Config) { config.TLSCertFile, config.TLSKeyFile = "missing.pem", cert },
+			expected: []string{"missing.pem"},
+		},
+		{
+			name:     "storage",
+			change:   func(config *Config) { config.Storage = StorageConfig{Backend: "postgres"} },
+			expected: []string{"not implemented"},
+		},
+		{
+			name: "all problems",
+			change: func(config *Config) {
+				config.LogLevel = "verbose"
+				config.CORSOrigins = []string{"example.com"}
+				config.Auth.SigningKeys = []string{"short"}
+				config.Battle = BattleConfig{}
+				config.ShutdownSeconds = -1
+				config.LogFormat = "xml"
+			},
+			expected: []string{"log level", "log format", "CORS origin", "signing key 1", "max shots", "stalemate shots", "shutdown seconds"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Default()
+			tt.change(config)
+
+			err := config.Validate()
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors about %v", tt.expected)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected an error about %s, got %v", expected, err)
+				}
+			}
+		})
+	}
+}
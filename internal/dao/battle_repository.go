@@ -1,116 +1,175 @@
 package dao
 
-import "glaktika.eu/galaktika/pkg/galaxy"
+import (
+	"cmp"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
 
 type BattleRepository struct {
-	battle *galaxy.Battle
+	mutex sync.RWMutex
+
+	battleMap map[string]*galaxy.Battle
 }
 
 func NewBattleRepository() *BattleRepository {
-	return &BattleRepository{
-		battle: &galaxy.Battle{
-			ID: "1",
-			SideA: galaxy.NewFleet([]*galaxy.Ship{
-				{
-					ID: "A1",
-					Tech: galaxy.ShipTech{
-						Attack:        5,
-						Guns:          2,
-						Defense:       3,
-						Speed:         10,
-						CargoCapacity: 50,
-						Mass:          100,
-					},
-					Destroyed: false,
-					Name:      "Cruiser Alpha",
-					Owner:     "race_a",
+	r := &BattleRepository{battleMap: make(map[string]*galaxy.Battle)}
+	r.Upsert(exampleBattle())
+
+	return r
+}
+
+// exampleBattle is the battle shown by the battle viewer
+func exampleBattle() *galaxy.Battle {
+	return &galaxy.Battle{
+		ID: "1",
+		SideA: galaxy.NewFleet([]*galaxy.Ship{
+			{
+				ID: "A1",
+				Tech: galaxy.ShipTech{
+					Attack:        5,
+					Guns:          2,
+					Defense:       3,
+					Speed:         10,
+					CargoCapacity: 50,
+					Mass:          100,
 				},
-				{
-					ID: "A2",
-					Tech: galaxy.ShipTech{
-						Attack:        4,
-						Guns:          2,
-						Defense:       4,
-						Speed:         8,
-						CargoCapacity: 40,
-						Mass:          90,
-					},
-					Destroyed: false,
-					Name:      "Destroyer Alpha",
-					Owner:     "race_a",
+				Destroyed: false,
+				Name:      "Cruiser Alpha",
+				Owner:     "race_a",
+			},
+			{
+				ID: "A2",
+				Tech: galaxy.ShipTech{
+					Attack:        4,
+					Guns:          2,
+					Defense:       4,
+					Speed:         8,
+					CargoCapacity: 40,
+					Mass:          90,
 				},
-			}),
-			SideB: galaxy.NewFleet([]*galaxy.Ship{
-				{
-					ID: "B1",
-					Tech: galaxy.ShipTech{
-						Attack:        6,
-						Guns:          3,
-						Defense:       2,
-						Speed:         12,
-						CargoCapacity: 45,
-						Mass:          95,
-					},
-					Destroyed: false,
-					Name:      "Cruiser Beta",
-					Owner:     "race_b",
+				Destroyed: false,
+				Name:      "Destroyer Alpha",
+				Owner:     "race_a",
+			},
+		}),
+		SideB: galaxy.NewFleet([]*galaxy.Ship{
+			{
+				ID: "B1",
+				Tech: galaxy.ShipTech{
+					Attack:        6,
+					Guns:          3,
+					Defense:       2,
+					Speed:         12,
+					CargoCapacity: 45,
+					Mass:          95,
 				},
-				{
-					ID: "B1_2",
-					Tech: galaxy.ShipTech{
-						Attack:        6,
-						Guns:          3,
-						Defense:       2,
-						Speed:         12,
-						CargoCapacity: 45,
-						Mass:          95,
-					},
-					Destroyed: false,
-					Name:      "Cruiser Beta",
-					Owner:     "race_b",
+				Destroyed: false,
+				Name:      "Cruiser Beta",
+				Owner:     "race_b",
+			},
+			{
+				ID: "B1_2",
+				Tech: galaxy.ShipTech{
+					Attack:        6,
+					Guns:          3,
+					Defense:       2,
+					Speed:         12,
+					CargoCapacity: 45,
+					Mass:          95,
 				},
-				{
-					ID: "B1_3",
-					Tech: galaxy.ShipTech{
-						Attack:        6,
-						Guns:          3,
-						Defense:       2,
-						Speed:         12,
-						CargoCapacity: 45,
-						Mass:          95,
-					},
-					Destroyed: false,
-					Name:      "Cruiser Beta",
-					Owner:     "race_b",
+				Destroyed: false,
+				Name:      "Cruiser Beta",
+				Owner:     "race_b",
+			},
+			{
+				ID: "B1_3",
+				Tech: galaxy.ShipTech{
+					Attack:        6,
+					Guns:          3,
+					Defense:       2,
+					Speed:         12,
+					CargoCapacity: 45,
+					Mass:          95,
 				},
-				{
-					ID: "B2",
-					Tech: galaxy.ShipTech{
-						Attack:        5,
-						Guns:          2,
-						Defense:       3,
-						Speed:         9,
-						CargoCapacity: 55,
-						Mass:          105,
-					},
-					Destroyed: false,
-					Name:      "Destroyer Beta",
-					Owner:     "race_b",
+				Destroyed: false,
+				Name:      "Cruiser Beta",
+				Owner:     "race_b",
+			},
+			{
+				ID: "B2",
+				Tech: galaxy.ShipTech{
+					Attack:        5,
+					Guns:          2,
+					Defense:       3,
+					Speed:         9,
+					CargoCapacity: 55,
+					Mass:          105,
 				},
-			}),
-			Shots: []*galaxy.Shot{
-				{"A1", "B1", false},
-				{"B2", "A2", false},
-				{"A2", "B2", true},
-				{"B1", "A1", true},
-				{"A2", "B1", true},
-				{"A2", "B1_2", true},
-				{"A2", "B1_3", false},
+				Destroyed: false,
+				Name:      "Destroyer Beta",
+				Owner:     "race_b",
 			},
+		}),
+		Shots: []*galaxy.Shot{
+			{"A1", "B1", false},
+			{"B2", "A2", false},
+			{"A2", "B2", true},
+			{"B1", "A1", true},
+			{"A2", "B1", true},
+			{"A2", "B1_2", true},
+			{"A2", "B1_3", false},
 		},
 	}
 }
 
 func (r *BattleRepository) GetBattle(battleId string) *galaxy.Battle {
-	return r.battle
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.battleMap[battleId]
+}
+
+// FindByDivision returns the battles fought on the map of the division in the order they happened.
+func (r *BattleRepository) FindByDivision(divisionId string) []*galaxy.Battle {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	battles := slices.Collect(maps.Values(r.battleMap))
+	battles = slices.DeleteFunc(battles, func(battle *galaxy.Battle) bool { return battle.DivisionId != divisionId })
+
+	slices.SortFunc(battles, func(a, b *galaxy.Battle) int {
+		if a.Time != b.Time {
+			return cmp.Compare(a.Time, b.Time)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return battles
+}
+
+func (r *BattleRepository) Upsert(battle *galaxy.Battle) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.battleMap[battle.ID] = battle
+}
+
+// Count returns the number of the stored battles.
+func (r *BattleRepository) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.battleMap)
+}
+
+func (r *BattleRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	battle := exampleBattle()
+	r.battleMap = map[string]*galaxy.Battle{battle.ID: battle}
 }
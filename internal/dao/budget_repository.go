@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type budgetKey struct {
+	DivisionId string
+	RaceId     string
+}
+
+// BudgetRepository stores the budgets of the races in the divisions.
+type BudgetRepository struct {
+	mutex sync.RWMutex
+
+	budgetMap map[budgetKey]*galaxy.Budget
+}
+
+func NewBudgetRepository() *BudgetRepository {
+	return &BudgetRepository{
+		budgetMap: make(map[budgetKey]*galaxy.Budget),
+	}
+}
+
+func (r *BudgetRepository) Get(divisionId, raceId string) *galaxy.Budget {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if budget, ok := r.budgetMap[budgetKey{DivisionId: divisionId, RaceId: raceId}]; ok {
+		return copyBudget(budget)
+	}
+
+	return nil
+}
+
+// FindByDivision returns the budgets of the races in the division sorted by the race.
+func (r *BudgetRepository) FindByDivision(divisionId string) []*galaxy.Budget {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	budgets := []*galaxy.Budget{}
+	for budget := range maps.Values(r.budgetMap) {
+		if budget.DivisionId == divisionId {
+			budgets = append(budgets, copyBudget(budget))
+		}
+	}
+
+	slices.SortFunc(budgets, func(a, b *galaxy.Budget) int {
+		return strings.Compare(a.RaceId, b.RaceId)
+	})
+
+	return budgets
+}
+
+func (r *BudgetRepository) Upsert(budget *galaxy.Budget) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.budgetMap[budgetKey{DivisionId: budget.DivisionId, RaceId: budget.RaceId}] = copyBudget(budget)
+}
+
+// Credit adds the amount to the budget of the race. The initial budget is stored when the race has no budget yet.
+func (r *BudgetRepository) Credit(initial *galaxy.Budget, turn int, amount float64, description string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.budget(initial).Credit(turn, amount, description)
+}
+
+// Debit spends the amount from the budget of the race, it fails when the balance is not enough.
+// The initial budget is stored when the race has no budget yet.
+func (r *BudgetRepository) Debit(initial *galaxy.Budget, turn int, amount float64, description string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.budget(initial).Debit(turn, amount, description)
+}
+
+// budget returns the stored budget of the race of the initial budget or stores the initial one, the lock is held.
+func (r *BudgetRepository) budget(initial *galaxy.Budget) *galaxy.Budget {
+	key := budgetKey{DivisionId: initial.DivisionId, RaceId: initial.RaceId}
+	budget, ok := r.budgetMap[key]
+	if !ok {
+		budget = copyBudget(initial)
+		r.budgetMap[key] = budget
+	}
+
+	return budget
+}
+
+// copyBudget copies the budget and its list of entries, the entries are not changed once added.
+func copyBudget(budget *galaxy.Budget) *galaxy.Budget {
+	budgetCopy := *budget
+	budgetCopy.Entries = slices.Clone(budget.Entries)
+
+	return &budgetCopy
+}
+
+func (r *BudgetRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.budgetMap = make(map[budgetKey]*galaxy.Budget)
+}
@@ -5,9 +5,12 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 )
 
 type DivisionRepository struct {
+	mutex sync.RWMutex
+
 	divisionMap map[string]*galaxy.Division
 }
 
@@ -20,10 +23,16 @@ func NewDivisionRepository() *DivisionRepository {
 }
 
 func (r *DivisionRepository) Get(id string) *galaxy.Division {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	return r.divisionMap[id]
 }
 
 func (r *DivisionRepository) GetAll() []*galaxy.Division {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	divisions := slices.Collect(maps.Values(r.divisionMap))
 
 	slices.SortFunc(divisions, func(a, b *galaxy.Division) int {
@@ -34,13 +43,30 @@ func (r *DivisionRepository) GetAll() []*galaxy.Division {
 }
 
 func (r *DivisionRepository) Upsert(division *galaxy.Division) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.divisionMap[division.ID] = division
 }
 
 func (r *DivisionRepository) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	delete(r.divisionMap, id)
 }
 
+// Count returns the number of the stored divisions.
+func (r *DivisionRepository) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.divisionMap)
+}
+
 func (r *DivisionRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.divisionMap = make(map[string]*galaxy.Division)
 }
@@ -6,9 +6,12 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 )
 
 type FleetBuildRepository struct {
+	mutex sync.RWMutex
+
 	fleetBuildMap map[string]*galaxy.FleetBuild
 	// not very effective way, but this repository is for DEV purposes only
 	fleetBuildToShipModels []*galaxy.FleetBuildToShipModel
@@ -21,11 +24,24 @@ func NewFleetBuildRepository() *FleetBuildRepository {
 }
 
 func (r *FleetBuildRepository) Get(id string) *galaxy.FleetBuild {
-	return r.fleetBuildMap[id]
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if fleetBuild, ok := r.fleetBuildMap[id]; ok {
+		return fleetBuild.Copy()
+	}
+
+	return nil
 }
 
 func (r *FleetBuildRepository) GetAll(divisionId, raceId string) []*galaxy.FleetBuild {
-	fleetBuilds := slices.Collect(maps.Values(r.fleetBuildMap))
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	fleetBuilds := []*galaxy.FleetBuild{}
+	for fleetBuild := range maps.Values(r.fleetBuildMap) {
+		fleetBuilds = append(fleetBuilds, fleetBuild.Copy())
+	}
 
 	if divisionId != "" {
 		fleetBuilds = util.ArrayFilter(fleetBuilds, func(b *galaxy.FleetBuild) bool { return b.DivisionId == divisionId })
@@ -42,21 +58,42 @@ func (r *FleetBuildRepository) GetAll(divisionId, raceId string) []*galaxy.Fleet
 }
 
 func (r *FleetBuildRepository) Upsert(fleetBuild *galaxy.FleetBuild) {
-	r.fleetBuildMap[fleetBuild.ID] = fleetBuild
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.fleetBuildMap[fleetBuild.ID] = fleetBuild.Copy()
 }
 
 func (r *FleetBuildRepository) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	delete(r.fleetBuildMap, id)
 }
 
 func (r *FleetBuildRepository) FindAssignedShipModels(fleetBuildId string) []*galaxy.FleetBuildToShipModel {
-	return util.ArrayFilter(r.fleetBuildToShipModels, func(b2s *galaxy.FleetBuildToShipModel) bool { return b2s.FleetBuildID == fleetBuildId })
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	assigned := []*galaxy.FleetBuildToShipModel{}
+	for _, b2s := range r.fleetBuildToShipModels {
+		if b2s.FleetBuildID == fleetBuildId {
+			b2sCopy := *b2s
+			assigned = append(assigned, &b2sCopy)
+		}
+	}
+
+	return assigned
 }
 
 func (r *FleetBuildRepository) FindAssignedShipModel(fleetBuildId, shipModelId string) *galaxy.FleetBuildToShipModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	for _, b2m := range r.fleetBuildToShipModels {
 		if b2m.FleetBuildID == fleetBuildId && b2m.ShipModelID == shipModelId {
-			return b2m
+			b2mCopy := *b2m
+			return &b2mCopy
 		}
 	}
 
@@ -66,10 +103,14 @@ func (r *FleetBuildRepository) FindAssignedShipModel(fleetBuildId, shipModelId s
 // AssignShipModel assigns a ship model to a fleet build (upsert operation).
 // Returns true if a new assignment was created, false if an existing assignment was updated.
 func (r *FleetBuildRepository) AssignShipModel(fleetBuild2ShipModel *galaxy.FleetBuildToShipModel) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	for _, b2s := range r.fleetBuildToShipModels {
 		if b2s.ShipModelID == fleetBuild2ShipModel.ShipModelID && b2s.FleetBuildID == fleetBuild2ShipModel.FleetBuildID {
 			// Update existing assignment
 			b2s.Amount = fleetBuild2ShipModel.Amount
+			b2s.ShipModelVersion = fleetBuild2ShipModel.ShipModelVersion
 			b2s.ResultMass = fleetBuild2ShipModel.ResultMass
 			b2s.ShipModel = fleetBuild2ShipModel.ShipModel
 			return false // Updated existing
@@ -77,11 +118,15 @@ func (r *FleetBuildRepository) AssignShipModel(fleetBuild2ShipModel *galaxy.Flee
 	}
 
 	// Create new assignment
-	r.fleetBuildToShipModels = append(r.fleetBuildToShipModels, fleetBuild2ShipModel)
+	b2sCopy := *fleetBuild2ShipModel
+	r.fleetBuildToShipModels = append(r.fleetBuildToShipModels, &b2sCopy)
 	return true // Created new
 }
 
 func (r *FleetBuildRepository) UnassignShipModel(fleetBuildId, shipModelId string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	foundIndex := -1
 	for i, b2s := range r.fleetBuildToShipModels {
 		if b2s.ShipModelID == shipModelId && b2s.FleetBuildID == fleetBuildId {
@@ -99,7 +144,18 @@ func (r *FleetBuildRepository) UnassignShipModel(fleetBuildId, shipModelId strin
 	return true
 }
 
+// Count returns the number of the stored fleet builds.
+func (r *FleetBuildRepository) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.fleetBuildMap)
+}
+
 func (r *FleetBuildRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.fleetBuildMap = make(map[string]*galaxy.FleetBuild)
 	r.fleetBuildToShipModels = nil
 }
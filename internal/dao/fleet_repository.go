@@ -1,6 +1,12 @@
 package dao
 
-import "glaktika.eu/galaktika/pkg/galaxy"
+import (
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
 
 type fleetKey struct {
 	DivisionId string
@@ -8,34 +14,130 @@ type fleetKey struct {
 }
 
 type FleetRepository struct {
+	mutex sync.RWMutex
+
 	fleetMap       map[string]*galaxy.Fleet
 	divisionFleets map[fleetKey]*galaxy.DivisionFleet
+	history        map[string][]*galaxy.FleetEvent // fleet id -> events in the order they happened
 }
 
 func NewFleetRepository() *FleetRepository {
 	return &FleetRepository{
 		fleetMap:       make(map[string]*galaxy.Fleet),
 		divisionFleets: make(map[fleetKey]*galaxy.DivisionFleet),
+		history:        make(map[string][]*galaxy.FleetEvent),
 	}
 }
 
 func (r *FleetRepository) Get(id string) *galaxy.Fleet {
-	return r.fleetMap[id]
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if fleet, ok := r.fleetMap[id]; ok {
+		return fleet.Copy()
+	}
+
+	return nil
+}
+
+// FindByDivision returns the fleets on the map of the division sorted by ID.
+func (r *FleetRepository) FindByDivision(divisionId string) []*galaxy.Fleet {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	fleets := []*galaxy.Fleet{}
+	for fleet := range maps.Values(r.fleetMap) {
+		if fleet.DivisionId == divisionId {
+			fleets = append(fleets, fleet.Copy())
+		}
+	}
+
+	slices.SortFunc(fleets, func(a, b *galaxy.Fleet) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return fleets
 }
 
 func (r *FleetRepository) Upsert(fleet *galaxy.Fleet) {
-	r.fleetMap[fleet.ID] = fleet
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.fleetMap[fleet.ID] = fleet.Copy()
+}
+
+func (r *FleetRepository) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.fleetMap, id)
 }
 
 func (r *FleetRepository) GetDivisionFleet(divisionId, userId string) *galaxy.DivisionFleet {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	return r.divisionFleets[fleetKey{DivisionId: divisionId, UserId: userId}]
 }
 
 func (r *FleetRepository) UpsertDivisionFleet(df *galaxy.DivisionFleet) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.divisionFleets[fleetKey{DivisionId: df.DivisionId, UserId: df.UserId}] = df
 }
 
+// Count returns the number of the stored fleets.
+func (r *FleetRepository) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.fleetMap)
+}
+
 func (r *FleetRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.fleetMap = make(map[string]*galaxy.Fleet)
 	r.divisionFleets = make(map[fleetKey]*galaxy.DivisionFleet)
+	r.history = make(map[string][]*galaxy.FleetEvent)
+}
+
+// AddEvent appends the event to the history of its fleet. The history is kept when the fleet is deleted.
+func (r *FleetRepository) AddEvent(event *galaxy.FleetEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.history[event.FleetId] = append(r.history[event.FleetId], event)
+}
+
+// ReplaceHistory sets the whole history of the fleet, e.g. restored from an archive.
+func (r *FleetRepository) ReplaceHistory(fleetId string, events []*galaxy.FleetEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.history[fleetId] = slices.Clone(events)
+}
+
+func (r *FleetRepository) GetHistory(fleetId string) []*galaxy.FleetEvent {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return slices.Clone(r.history[fleetId])
+}
+
+// FindDivisionFleets returns the fleets of the races in the division sorted by the race.
+func (r *FleetRepository) FindDivisionFleets(divisionId string) []*galaxy.DivisionFleet {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	divisionFleets := slices.Collect(maps.Values(r.divisionFleets))
+	divisionFleets = slices.DeleteFunc(divisionFleets, func(df *galaxy.DivisionFleet) bool { return df.DivisionId != divisionId })
+
+	slices.SortFunc(divisionFleets, func(a, b *galaxy.DivisionFleet) int {
+		return strings.Compare(a.UserId, b.UserId)
+	})
+
+	return divisionFleets
 }
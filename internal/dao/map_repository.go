@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MapRepository stores the planets and the map clock of every division.
+type MapRepository struct {
+	mutex sync.RWMutex
+
+	planetMap map[string]*galaxy.Planet
+	clocks    map[string]float64 // division id -> map time
+}
+
+func NewMapRepository() *MapRepository {
+	return &MapRepository{
+		planetMap: make(map[string]*galaxy.Planet),
+		clocks:    make(map[string]float64),
+	}
+}
+
+func (r *MapRepository) GetPlanet(id string) *galaxy.Planet {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.planetMap[id]
+}
+
+// GetPlanets returns the planets of the division sorted by ID.
+func (r *MapRepository) GetPlanets(divisionId string) []*galaxy.Planet {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	planets := slices.Collect(maps.Values(r.planetMap))
+	planets = slices.DeleteFunc(planets, func(planet *galaxy.Planet) bool { return planet.DivisionId != divisionId })
+
+	slices.SortFunc(planets, func(a, b *galaxy.Planet) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return planets
+}
+
+func (r *MapRepository) UpsertPlanet(planet *galaxy.Planet) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.planetMap[planet.ID] = planet
+}
+
+func (r *MapRepository) DeletePlanet(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.planetMap, id)
+}
+
+// GetTime returns the map clock of the division.
+func (r *MapRepository) GetTime(divisionId string) float64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.clocks[divisionId]
+}
+
+func (r *MapRepository) SetTime(divisionId string, time float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.clocks[divisionId] = time
+}
+
+func (r *MapRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.planetMap = make(map[string]*galaxy.Planet)
+	r.clocks = make(map[string]float64)
+}
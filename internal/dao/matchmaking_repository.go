@@ -0,0 +1,91 @@
+package dao
+
+import (
+	"cmp"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MatchmakingRepository stores the matchmaking queue entries and the notifications of the races.
+type MatchmakingRepository struct {
+	mutex sync.RWMutex
+
+	entryMap      map[string]*galaxy.QueueEntry
+	notifications map[string][]*galaxy.Notification // race id -> notifications in the order they were sent
+}
+
+func NewMatchmakingRepository() *MatchmakingRepository {
+	return &MatchmakingRepository{
+		entryMap:      make(map[string]*galaxy.QueueEntry),
+		notifications: make(map[string][]*galaxy.Notification),
+	}
+}
+
+func (r *MatchmakingRepository) Get(id string) *galaxy.QueueEntry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.entryMap[id]
+}
+
+// FindEntries returns the entries of the race, the newest first.
+func (r *MatchmakingRepository) FindEntries(raceId string) []*galaxy.QueueEntry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := slices.Collect(maps.Values(r.entryMap))
+	entries = slices.DeleteFunc(entries, func(entry *galaxy.QueueEntry) bool { return entry.RaceId != raceId })
+
+	slices.SortFunc(entries, func(a, b *galaxy.QueueEntry) int {
+		return cmp.Or(b.EnqueuedAt.Compare(a.EnqueuedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return entries
+}
+
+// FindWaiting returns the waiting entries of all the divisions, the longest waiting first.
+func (r *MatchmakingRepository) FindWaiting() []*galaxy.QueueEntry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := slices.Collect(maps.Values(r.entryMap))
+	entries = slices.DeleteFunc(entries, func(entry *galaxy.QueueEntry) bool { return entry.Status != galaxy.QUEUE_STATUS_WAITING })
+
+	slices.SortFunc(entries, func(a, b *galaxy.QueueEntry) int {
+		return cmp.Or(a.EnqueuedAt.Compare(b.EnqueuedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return entries
+}
+
+func (r *MatchmakingRepository) Upsert(entry *galaxy.QueueEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entryMap[entry.ID] = entry
+}
+
+func (r *MatchmakingRepository) AddNotification(notification *galaxy.Notification) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.notifications[notification.RaceId] = append(r.notifications[notification.RaceId], notification)
+}
+
+func (r *MatchmakingRepository) GetNotifications(raceId string) []*galaxy.Notification {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return slices.Clone(r.notifications[raceId])
+}
+
+func (r *MatchmakingRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entryMap = make(map[string]*galaxy.QueueEntry)
+	r.notifications = make(map[string][]*galaxy.Notification)
+}
@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"cmp"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OptimizationRepository stores the fleet build optimization jobs.
+type OptimizationRepository struct {
+	mutex sync.RWMutex
+
+	jobMap map[string]*galaxy.OptimizationJob
+}
+
+func NewOptimizationRepository() *OptimizationRepository {
+	return &OptimizationRepository{jobMap: make(map[string]*galaxy.OptimizationJob)}
+}
+
+func (r *OptimizationRepository) Get(id string) *galaxy.OptimizationJob {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.jobMap[id]
+}
+
+// FindByRace returns the jobs of the race, the newest first.
+func (r *OptimizationRepository) FindByRace(raceId string) []*galaxy.OptimizationJob {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	jobs := slices.Collect(maps.Values(r.jobMap))
+	jobs = slices.DeleteFunc(jobs, func(job *galaxy.OptimizationJob) bool { return job.RaceId != raceId })
+
+	slices.SortFunc(jobs, func(a, b *galaxy.OptimizationJob) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return jobs
+}
+
+func (r *OptimizationRepository) Upsert(job *galaxy.OptimizationJob) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobMap[job.ID] = job
+}
+
+func (r *OptimizationRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobMap = make(map[string]*galaxy.OptimizationJob)
+}
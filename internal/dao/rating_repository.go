@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"cmp"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type ratingKey struct {
+	DivisionId string
+	RaceId     string
+}
+
+// RatingRepository stores the ratings of the races, the global ratings have an empty division.
+type RatingRepository struct {
+	mutex sync.RWMutex
+
+	ratingMap map[ratingKey]*galaxy.Rating
+	history   map[string][]*galaxy.RatingChange // race id -> changes in the order they happened
+}
+
+func NewRatingRepository() *RatingRepository {
+	return &RatingRepository{
+		ratingMap: make(map[ratingKey]*galaxy.Rating),
+		history:   make(map[string][]*galaxy.RatingChange),
+	}
+}
+
+func (r *RatingRepository) Get(divisionId, raceId string) *galaxy.Rating {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.ratingMap[ratingKey{DivisionId: divisionId, RaceId: raceId}]
+}
+
+// FindByDivision returns the ratings of the division, or the global ratings for an empty division,
+// the best rating first.
+func (r *RatingRepository) FindByDivision(divisionId string) []*galaxy.Rating {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	ratings := slices.Collect(maps.Values(r.ratingMap))
+	ratings = slices.DeleteFunc(ratings, func(rating *galaxy.Rating) bool { return rating.DivisionId != divisionId })
+
+	slices.SortFunc(ratings, func(a, b *galaxy.Rating) int {
+		return cmp.Or(cmp.Compare(b.Rating, a.Rating), strings.Compare(a.RaceId, b.RaceId))
+	})
+
+	return ratings
+}
+
+func (r *RatingRepository) Upsert(rating *galaxy.Rating) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ratingMap[ratingKey{DivisionId: rating.DivisionId, RaceId: rating.RaceId}] = rating
+}
+
+func (r *RatingRepository) AddChange(change *galaxy.RatingChange) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.history[change.RaceId] = append(r.history[change.RaceId], change)
+}
+
+// GetHistory returns the rating changes of the race in all the divisions and the global ones.
+func (r *RatingRepository) GetHistory(raceId string) []*galaxy.RatingChange {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return slices.Clone(r.history[raceId])
+}
+
+func (r *RatingRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ratingMap = make(map[ratingKey]*galaxy.Rating)
+	r.history = make(map[string][]*galaxy.RatingChange)
+}
@@ -2,31 +2,82 @@ package dao
 
 import (
 	"glaktika.eu/galaktika/pkg/galaxy"
-	"glaktika.eu/galaktika/pkg/util"
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 )
 
 type ShipModelRepository struct {
+	mutex sync.RWMutex
+
 	shipModelMap map[string]*galaxy.ShipModel
+	// immutable history of every ship model, ordered by version
+	versions map[string][]*galaxy.ShipModel
 }
 
 func NewShipModelRepository() *ShipModelRepository {
 	return &ShipModelRepository{
 		shipModelMap: make(map[string]*galaxy.ShipModel),
+		versions:     make(map[string][]*galaxy.ShipModel),
 	}
 }
 
+// Get returns a copy of the latest version of the ship model.
 func (r *ShipModelRepository) Get(id string) *galaxy.ShipModel {
-	return r.shipModelMap[id]
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if shipModel, ok := r.shipModelMap[id]; ok {
+		return shipModel.Copy()
+	}
+
+	return nil
+}
+
+// GetVersion returns a copy of the given version of the ship model. Version 0 means the latest version.
+func (r *ShipModelRepository) GetVersion(id string, version int) *galaxy.ShipModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if version == 0 {
+		if shipModel, ok := r.shipModelMap[id]; ok {
+			return shipModel.Copy()
+		}
+		return nil
+	}
+
+	for _, shipModel := range r.versions[id] {
+		if shipModel.Version == version {
+			return shipModel.Copy()
+		}
+	}
+
+	return nil
+}
+
+// GetVersions returns copies of all the versions of the ship model, the deleted ship models keep their history.
+func (r *ShipModelRepository) GetVersions(id string) []*galaxy.ShipModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	versions := make([]*galaxy.ShipModel, 0, len(r.versions[id]))
+	for _, shipModel := range r.versions[id] {
+		versions = append(versions, shipModel.Copy())
+	}
+
+	return versions
 }
 
 func (r *ShipModelRepository) GetAll(ownerId string) []*galaxy.ShipModel {
-	shipModels := slices.Collect(maps.Values(r.shipModelMap))
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-	if ownerId != "" {
-		shipModels = util.ArrayFilter(shipModels, func(m *galaxy.ShipModel) bool { return m.OwnerId == ownerId })
+	shipModels := []*galaxy.ShipModel{}
+	for shipModel := range maps.Values(r.shipModelMap) {
+		if ownerId == "" || shipModel.OwnerId == ownerId {
+			shipModels = append(shipModels, shipModel.Copy())
+		}
 	}
 
 	slices.SortFunc(shipModels, func(a, b *galaxy.ShipModel) int {
@@ -36,14 +87,46 @@ func (r *ShipModelRepository) GetAll(ownerId string) []*galaxy.ShipModel {
 	return shipModels
 }
 
-func (r *ShipModelRepository) Upsert(shipModel *galaxy.ShipModel) {
-	r.shipModelMap[shipModel.ID] = shipModel
+// Upsert stores a copy of the ship model as its new version, designs without modules are migrated into modules.
+// The previous versions are kept unchanged. Returns a copy of the stored version.
+func (r *ShipModelRepository) Upsert(shipModel *galaxy.ShipModel) *galaxy.ShipModel {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := shipModel.Copy()
+	stored.MigrateModules()
+
+	history := r.versions[stored.ID]
+	stored.Version = 1
+	if len(history) > 0 {
+		stored.Version = history[len(history)-1].Version + 1
+	}
+
+	r.versions[stored.ID] = append(history, stored)
+	r.shipModelMap[stored.ID] = stored
+
+	return stored.Copy()
 }
 
 func (r *ShipModelRepository) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	delete(r.shipModelMap, id)
 }
 
+// Count returns the number of the stored ship models, not counting their old versions.
+func (r *ShipModelRepository) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.shipModelMap)
+}
+
 func (r *ShipModelRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.shipModelMap = make(map[string]*galaxy.ShipModel)
+	r.versions = make(map[string][]*galaxy.ShipModel)
 }
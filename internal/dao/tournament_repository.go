@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"cmp"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// TournamentRepository stores the tournaments of the divisions.
+type TournamentRepository struct {
+	mutex sync.RWMutex
+
+	tournamentMap map[string]*galaxy.Tournament
+}
+
+func NewTournamentRepository() *TournamentRepository {
+	return &TournamentRepository{
+		tournamentMap: make(map[string]*galaxy.Tournament),
+	}
+}
+
+func (r *TournamentRepository) Get(id string) *galaxy.Tournament {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.tournamentMap[id]
+}
+
+// FindByDivision returns the tournaments of the division, the newest first.
+func (r *TournamentRepository) FindByDivision(divisionId string) []*galaxy.Tournament {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tournaments := slices.Collect(maps.Values(r.tournamentMap))
+	tournaments = slices.DeleteFunc(tournaments, func(tournament *galaxy.Tournament) bool { return tournament.DivisionId != divisionId })
+
+	slices.SortFunc(tournaments, func(a, b *galaxy.Tournament) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return tournaments
+}
+
+func (r *TournamentRepository) Upsert(tournament *galaxy.Tournament) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tournamentMap[tournament.ID] = tournament
+}
+
+func (r *TournamentRepository) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.tournamentMap, id)
+}
+
+func (r *TournamentRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tournamentMap = make(map[string]*galaxy.Tournament)
+}
@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// TurnRepository stores the current turns, the submitted orders and the turn reports of the divisions.
+type TurnRepository struct {
+	mutex sync.RWMutex
+
+	stateMap map[string]*galaxy.TurnState // division id -> current turn
+	orderMap map[string]*galaxy.Order
+	reports  map[string][]*galaxy.TurnReport // division id -> reports in turn order
+}
+
+func NewTurnRepository() *TurnRepository {
+	return &TurnRepository{
+		stateMap: make(map[string]*galaxy.TurnState),
+		orderMap: make(map[string]*galaxy.Order),
+		reports:  make(map[string][]*galaxy.TurnReport),
+	}
+}
+
+func (r *TurnRepository) GetState(divisionId string) *galaxy.TurnState {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.stateMap[divisionId]
+}
+
+func (r *TurnRepository) UpsertState(state *galaxy.TurnState) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.stateMap[state.DivisionId] = state
+}
+
+func (r *TurnRepository) GetOrder(id string) *galaxy.Order {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.orderMap[id]
+}
+
+// FindOrders returns the orders submitted in the division for the turn sorted by ID.
+func (r *TurnRepository) FindOrders(divisionId string, turn int) []*galaxy.Order {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	orders := slices.Collect(maps.Values(r.orderMap))
+	orders = slices.DeleteFunc(orders, func(order *galaxy.Order) bool {
+		return order.DivisionId != divisionId || order.Turn != turn
+	})
+
+	slices.SortFunc(orders, func(a, b *galaxy.Order) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return orders
+}
+
+func (r *TurnRepository) UpsertOrder(order *galaxy.Order) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.orderMap[order.ID] = order
+}
+
+func (r *TurnRepository) DeleteOrder(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.orderMap, id)
+}
+
+func (r *TurnRepository) GetReports(divisionId string) []*galaxy.TurnReport {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return slices.Clone(r.reports[divisionId])
+}
+
+func (r *TurnRepository) GetReport(divisionId string, turn int) *galaxy.TurnReport {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, report := range r.reports[divisionId] {
+		if report.Turn == turn {
+			return report
+		}
+	}
+
+	return nil
+}
+
+func (r *TurnRepository) AddReport(report *galaxy.TurnReport) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.reports[report.DivisionId] = append(r.reports[report.DivisionId], report)
+}
+
+func (r *TurnRepository) ResetData() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.stateMap = make(map[string]*galaxy.TurnState)
+	r.orderMap = make(map[string]*galaxy.Order)
+	r.reports = make(map[string][]*galaxy.TurnReport)
+}
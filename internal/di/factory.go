@@ -12,6 +12,7 @@ func NewAuthenticationManager() api.AuthenticationManager {
 	am.AddToken("token-rex-001", &galaxy.Race{ID: "rex", Name: "Commander Rex", Role: "commander"})
 	am.AddToken("token-zyx-002", &galaxy.Race{ID: "zyx", Name: "Admiral Zyx", Role: "admiral"})
 	am.AddToken("token-keth-003", &galaxy.Race{ID: "keth", Name: "Warlord Keth", Role: "warlord"})
+	am.AddToken("token-admin-000", &galaxy.Race{ID: "admin", Name: "Game Master", Role: galaxy.ROLE_ADMIN})
 
 	return am
 }
@@ -49,3 +50,19 @@ func NewDivisionRepository() *dao.DivisionRepository {
 
 	return divisionRepository
 }
+
+func NewMapRepository() *dao.MapRepository {
+	r := dao.NewMapRepository()
+
+	races := []string{"rex", "zyx", "keth"}
+	for i, divisionID := range []string{"alpha", "beta", "gamma"} {
+		r.UpsertPlanet(&galaxy.Planet{ID: divisionID + "-prime", DivisionId: divisionID, Name: "Prime", X: 0, Y: 0,
+			OwnerId: races[i], Population: 100, Industry: 20})
+		r.UpsertPlanet(&galaxy.Planet{ID: divisionID + "-outpost", DivisionId: divisionID, Name: "Outpost", X: 3, Y: 4,
+			OwnerId: races[(i+1)%len(races)], Population: 100, Industry: 20})
+		r.UpsertPlanet(&galaxy.Planet{ID: divisionID + "-frontier", DivisionId: divisionID, Name: "Frontier", X: 10, Y: 0,
+			Population: 20, Industry: 5})
+	}
+
+	return r
+}
@@ -10,6 +10,55 @@ func RegisterRoutes(apiRoute *gin.RouterGroup) {
 	apiRoute.POST("/divisions", func(c *gin.Context) { DivisionControllerInstance.CreateDivision(c) })
 	apiRoute.PUT("/divisions/:id", func(c *gin.Context) { DivisionControllerInstance.UpdateDivision(c) })
 	apiRoute.DELETE("/divisions/:id", func(c *gin.Context) { DivisionControllerInstance.DeleteDivision(c) })
+	apiRoute.GET("/divisions/:id/research-cost", func(c *gin.Context) { DivisionControllerInstance.GetResearchCost(c) })
+
+	apiRoute.GET("/divisions/:id/map", func(c *gin.Context) { MapControllerInstance.GetMap(c) })
+	apiRoute.POST("/divisions/:id/map/advance", func(c *gin.Context) { MapControllerInstance.AdvanceTime(c) })
+	apiRoute.GET("/divisions/:id/battles", func(c *gin.Context) { MapControllerInstance.GetBattles(c) })
+	apiRoute.POST("/divisions/:id/planets", func(c *gin.Context) { MapControllerInstance.CreatePlanet(c) })
+	apiRoute.DELETE("/divisions/:id/planets/:planetId", func(c *gin.Context) { MapControllerInstance.DeletePlanet(c) })
+	apiRoute.GET("/fleets/:id", func(c *gin.Context) { FleetControllerInstance.GetFleet(c) })
+	apiRoute.GET("/fleets/:id/history", func(c *gin.Context) { FleetControllerInstance.GetFleetHistory(c) })
+	apiRoute.GET("/fleets/:id/repair", func(c *gin.Context) { FleetControllerInstance.PreviewRepair(c) })
+	apiRoute.POST("/fleets/:id/repair", func(c *gin.Context) { FleetControllerInstance.Repair(c) })
+	apiRoute.GET("/fleets/:id/refit", func(c *gin.Context) { FleetControllerInstance.PreviewRefit(c) })
+	apiRoute.POST("/fleets/:id/refit", func(c *gin.Context) { FleetControllerInstance.Refit(c) })
+	apiRoute.POST("/fleets/:id/deploy", func(c *gin.Context) { MapControllerInstance.DeployFleet(c) })
+	apiRoute.POST("/fleets/:id/move", func(c *gin.Context) { MapControllerInstance.MoveFleet(c) })
+
+	apiRoute.GET("/divisions/:id/economy", func(c *gin.Context) { EconomyControllerInstance.GetEconomyReport(c) })
+
+	apiRoute.GET("/divisions/:id/turn", func(c *gin.Context) { TurnControllerInstance.GetTurn(c) })
+	apiRoute.GET("/divisions/:id/orders", func(c *gin.Context) { TurnControllerInstance.GetOrders(c) })
+	apiRoute.POST("/divisions/:id/orders", func(c *gin.Context) { TurnControllerInstance.SubmitOrder(c) })
+	apiRoute.DELETE("/divisions/:id/orders/:orderId", func(c *gin.Context) { TurnControllerInstance.CancelOrder(c) })
+	apiRoute.GET("/divisions/:id/turns", func(c *gin.Context) { TurnControllerInstance.GetTurnReports(c) })
+	apiRoute.POST("/divisions/:id/turns/advance", func(c *gin.Context) { TurnControllerInstance.AdvanceTurn(c) })
+	apiRoute.GET("/divisions/:id/turns/:turn", func(c *gin.Context) { TurnControllerInstance.GetTurnReport(c) })
+	apiRoute.GET("/divisions/:id/tournaments", func(c *gin.Context) { TournamentControllerInstance.GetTournaments(c) })
+	apiRoute.POST("/divisions/:id/tournaments", func(c *gin.Context) { TournamentControllerInstance.CreateTournament(c) })
+	apiRoute.GET("/tournaments/:id", func(c *gin.Context) { TournamentControllerInstance.GetTournament(c) })
+	apiRoute.GET("/tournaments/:id/standings", func(c *gin.Context) { TournamentControllerInstance.GetStandings(c) })
+	apiRoute.POST("/tournaments/:id/run", func(c *gin.Context) { TournamentControllerInstance.RunTournament(c) })
+
+	apiRoute.GET("/ratings", func(c *gin.Context) { RatingControllerInstance.GetGlobalLeaderboard(c) })
+	apiRoute.GET("/divisions/:id/ratings", func(c *gin.Context) { RatingControllerInstance.GetDivisionLeaderboard(c) })
+	apiRoute.GET("/divisions/:id/opponents", func(c *gin.Context) { RatingControllerInstance.FindOpponents(c) })
+	apiRoute.GET("/races/:id/ratings/history", func(c *gin.Context) { RatingControllerInstance.GetRatingHistory(c) })
+
+	apiRoute.POST("/divisions/:id/queue", func(c *gin.Context) { MatchmakingControllerInstance.Enqueue(c) })
+	apiRoute.GET("/queue", func(c *gin.Context) { MatchmakingControllerInstance.GetQueueEntries(c) })
+	apiRoute.GET("/queue/:entryId", func(c *gin.Context) { MatchmakingControllerInstance.GetQueueEntry(c) })
+	apiRoute.DELETE("/queue/:entryId", func(c *gin.Context) { MatchmakingControllerInstance.CancelQueueEntry(c) })
+	apiRoute.GET("/notifications", func(c *gin.Context) { MatchmakingControllerInstance.GetNotifications(c) })
+
+	apiRoute.POST("/divisions/:id/optimizations", func(c *gin.Context) { OptimizationControllerInstance.StartOptimization(c) })
+	apiRoute.GET("/optimizations", func(c *gin.Context) { OptimizationControllerInstance.GetOptimizations(c) })
+	apiRoute.GET("/optimizations/:id", func(c *gin.Context) { OptimizationControllerInstance.GetOptimization(c) })
+
+	apiRoute.POST("/scenarios", func(c *gin.Context) { ScenarioControllerInstance.ImportScenario(c) })
+	apiRoute.GET("/divisions/:id/export", func(c *gin.Context) { ArchiveControllerInstance.ExportDivision(c) })
+	apiRoute.POST("/divisions/import", func(c *gin.Context) { ArchiveControllerInstance.ImportDivision(c) })
 
 	apiRoute.GET("/fleet-builds", func(c *gin.Context) { FleetBuildControllerInstance.GetAllFleetBuilds(c) })
 	apiRoute.GET("/fleet-builds/:id", func(c *gin.Context) { FleetBuildControllerInstance.GetFleetBuild(c) })
@@ -19,15 +68,33 @@ func RegisterRoutes(apiRoute *gin.RouterGroup) {
 	apiRoute.GET("/fleet-builds/:id/statistics", func(c *gin.Context) { FleetBuildControllerInstance.GetStatistics(c) })
 	apiRoute.GET("/fleet-builds/:id/ship-models", func(c *gin.Context) { FleetBuildControllerInstance.GetAssignedShipModels(c) })
 	apiRoute.POST("/fleet-builds/:id/ship-models", func(c *gin.Context) { FleetBuildControllerInstance.AssignShipModel(c) })
+	apiRoute.POST("/fleet-builds/:id/ship-models/upgrade", func(c *gin.Context) { FleetBuildControllerInstance.UpgradeShipModels(c) })
 	apiRoute.DELETE("/fleet-builds/:id/ship-models/:shipModelId", func(c *gin.Context) { FleetBuildControllerInstance.UnassignShipModel(c) })
 	apiRoute.POST("/fleet-builds/:id/build", func(c *gin.Context) { FleetBuildControllerInstance.Build(c) })
+	apiRoute.POST("/fleet-builds/:id/reinforce", func(c *gin.Context) { FleetBuildControllerInstance.Reinforce(c) })
 	apiRoute.GET("/fleet-builds/:id/fleet", func(c *gin.Context) { FleetBuildControllerInstance.GetFleet(c) })
+	apiRoute.GET("/fleet-builds/:id/research-preview", func(c *gin.Context) { FleetBuildControllerInstance.PreviewResearch(c) })
 	apiRoute.GET("/fleet-builds/:id/ship-models/:shipModelId/calculate-ship-tech", func(c *gin.Context) { FleetBuildControllerInstance.CalculateShipTech(c) })
 
 	apiRoute.GET("/ship-models", func(c *gin.Context) { ShipModelControllerInstance.GetAllShipModels(c) })
 	apiRoute.GET("/ship-models/:id", func(c *gin.Context) { ShipModelControllerInstance.GetShipModel(c) })
 	apiRoute.POST("/ship-models", func(c *gin.Context) { ShipModelControllerInstance.CreateShipModel(c) })
 	apiRoute.PUT("/ship-models/:id", func(c *gin.Context) { ShipModelControllerInstance.UpdateShipModel(c) })
+	apiRoute.GET("/ship-models/:id/versions", func(c *gin.Context) { ShipModelControllerInstance.GetShipModelVersions(c) })
+	apiRoute.GET("/ship-models/:id/versions/:version", func(c *gin.Context) { ShipModelControllerInstance.GetShipModelVersion(c) })
+	apiRoute.GET("/ship-models/:id/diff", func(c *gin.Context) { ShipModelControllerInstance.DiffShipModelVersions(c) })
 	apiRoute.POST("/ship-models/:id/calculate-ship-tech", func(c *gin.Context) { ShipModelControllerInstance.CalculateShipTech(c) })
 	apiRoute.DELETE("/ship-models/:id", func(c *gin.Context) { ShipModelControllerInstance.DeleteShipModel(c) })
+
+	apiRoute.GET("/tech-tree", func(c *gin.Context) { TechTreeControllerInstance.GetTechTree(c) })
+
+	apiRoute.GET("/ai/archetypes", func(c *gin.Context) { AIControllerInstance.GetArchetypes(c) })
+	apiRoute.GET("/divisions/:id/ai-fleet-build", func(c *gin.Context) { AIControllerInstance.GenerateFleetBuild(c) })
+}
+
+// RegisterHealthRoutes registers the probes and the metrics outside of the API group.
+func RegisterHealthRoutes(router gin.IRoutes) {
+	router.GET("/healthz", func(c *gin.Context) { HealthControllerInstance.Healthz(c) })
+	router.GET("/readyz", func(c *gin.Context) { HealthControllerInstance.Readyz(c) })
+	router.GET("/metrics", func(c *gin.Context) { MetricsControllerInstance.Metrics(c) })
 }
@@ -1,8 +1,17 @@
 package di
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"glaktika.eu/galaktika/internal/api"
+	"glaktika.eu/galaktika/internal/config"
 	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/internal/game"
+	"glaktika.eu/galaktika/internal/metrics"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"time"
 )
 
 var AuthenticationManagerInstance api.AuthenticationManager
@@ -13,34 +22,175 @@ var DivisionControllerInstance *api.DivisionController
 var FleetBuildRepositoryInstance *dao.FleetBuildRepository
 var FleetBuildControllerInstance *api.FleetBuildController
 var FleetRepositoryInstance *dao.FleetRepository
+var FleetControllerInstance *api.FleetController
+var MapRepositoryInstance *dao.MapRepository
+var TurnRepositoryInstance *dao.TurnRepository
+var BudgetRepositoryInstance *dao.BudgetRepository
+var EconomyServiceInstance *game.EconomyService
+var EconomyControllerInstance *api.EconomyController
+var FleetBuilderInstance *game.FleetBuilder
+var ShipyardInstance *game.Shipyard
+var TurnServiceInstance *game.TurnService
+var TurnSchedulerInstance *game.TurnScheduler
+var TurnControllerInstance *api.TurnController
+var TournamentRepositoryInstance *dao.TournamentRepository
+var RatingRepositoryInstance *dao.RatingRepository
+var RatingServiceInstance *game.RatingService
+var RatingControllerInstance *api.RatingController
+var TournamentServiceInstance *game.TournamentService
+var TournamentControllerInstance *api.TournamentController
+var MatchmakingRepositoryInstance *dao.MatchmakingRepository
+var MatchmakerInstance *game.Matchmaker
+var MatchmakingSchedulerInstance *game.MatchmakingScheduler
+var MatchmakingControllerInstance *api.MatchmakingController
+var MapControllerInstance *api.MapController
 var ShipModelRepositoryInstance *dao.ShipModelRepository
 var ShipModelControllerInstance *api.ShipModelController
+var TechTreeControllerInstance *api.TechTreeController
+var AIControllerInstance *api.AIController
+var OptimizationRepositoryInstance *dao.OptimizationRepository
+var OptimizationServiceInstance *game.OptimizationService
+var OptimizationControllerInstance *api.OptimizationController
+var ScenarioLoaderInstance *game.ScenarioLoader
+var ScenarioControllerInstance *api.ScenarioController
+var ArchiveServiceInstance *game.ArchiveService
+var ArchiveControllerInstance *api.ArchiveController
+var HealthControllerInstance *api.HealthController
+var MetricsControllerInstance *api.MetricsController
 
-func CreateSingletons(env string) {
-	// Based on env, choose repository implementation
-	// Currently only in-memory repos are implemented
-	switch env {
-	case "test", "dev":
+// CreateSingletons creates the repositories by the storage backend of the validated config and the services on top of them.
+func CreateSingletons(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// only the in-memory repositories are implemented, the validation rejects other backends
+	if cfg.Seeded() {
 		AuthenticationManagerInstance = NewAuthenticationManager()
-		BattleRepositoryInstance = dao.NewBattleRepository()
 		DivisionRepositoryInstance = NewDivisionRepository()
 		FleetBuildRepositoryInstance = NewFleetBuildRepository()
-		FleetRepositoryInstance = dao.NewFleetRepository()
+		MapRepositoryInstance = NewMapRepository()
 		ShipModelRepositoryInstance = NewShipModelRepository()
-
-	case "prod":
-		// Future: DB-backed repositories
-		// Example: DivisionRepositoryInstance = dao.NewDBDivisionRepository(dbConn)
-		panic("prod environment not yet implemented")
-	default:
-		panic("unknown environment: " + env)
+	} else {
+		AuthenticationManagerInstance = api.NewMemoryAuthenticationManager()
+		DivisionRepositoryInstance = dao.NewDivisionRepository()
+		FleetBuildRepositoryInstance = dao.NewFleetBuildRepository()
+		MapRepositoryInstance = dao.NewMapRepository()
+		ShipModelRepositoryInstance = dao.NewShipModelRepository()
+	}
+	if len(cfg.Auth.SigningKeys) > 0 {
+		AuthenticationManagerInstance = api.NewSignedAuthenticationManager(AuthenticationManagerInstance, cfg.Auth.SigningKeys)
 	}
+	BattleRepositoryInstance = dao.NewBattleRepository()
+	FleetRepositoryInstance = dao.NewFleetRepository()
+	TurnRepositoryInstance = dao.NewTurnRepository()
+	BudgetRepositoryInstance = dao.NewBudgetRepository()
+	TournamentRepositoryInstance = dao.NewTournamentRepository()
+	RatingRepositoryInstance = dao.NewRatingRepository()
+	MatchmakingRepositoryInstance = dao.NewMatchmakingRepository()
+	OptimizationRepositoryInstance = dao.NewOptimizationRepository()
+
+	battleLimits := game.BattleLimits{MaxShots: cfg.Battle.MaxShots, StalemateShots: cfg.Battle.StalemateShots}
+
+	RatingServiceInstance = game.NewRatingService(RatingRepositoryInstance, FleetRepositoryInstance)
+	EconomyServiceInstance = game.NewEconomyService(BudgetRepositoryInstance, MapRepositoryInstance)
+	FleetBuilderInstance = game.NewFleetBuilder(FleetBuildRepositoryInstance, FleetRepositoryInstance, ShipModelRepositoryInstance, DivisionRepositoryInstance, MapRepositoryInstance, TurnRepositoryInstance, EconomyServiceInstance, &util.UUIDGenerator{})
+	ShipyardInstance = game.NewShipyard(FleetRepositoryInstance, ShipModelRepositoryInstance, DivisionRepositoryInstance, MapRepositoryInstance, TurnRepositoryInstance, FleetBuilderInstance, EconomyServiceInstance)
+	TurnServiceInstance = game.NewTurnService(TurnRepositoryInstance, DivisionRepositoryInstance, FleetRepositoryInstance, FleetBuildRepositoryInstance, MapRepositoryInstance, BattleRepositoryInstance, FleetBuilderInstance, EconomyServiceInstance, RatingServiceInstance, battleLimits, &util.UUIDGenerator{})
+	TournamentServiceInstance = game.NewTournamentService(TournamentRepositoryInstance, DivisionRepositoryInstance, FleetRepositoryInstance, BattleRepositoryInstance, RatingServiceInstance, battleLimits, &util.UUIDGenerator{})
+	MatchmakerInstance = game.NewMatchmaker(MatchmakingRepositoryInstance, FleetRepositoryInstance, DivisionRepositoryInstance, BattleRepositoryInstance, RatingServiceInstance,
+		game.NewAIOpponentGenerator(DivisionRepositoryInstance, &util.UUIDGenerator{}), battleLimits, &util.UUIDGenerator{}, 2*time.Minute)
+	OptimizationServiceInstance = game.NewOptimizationService(OptimizationRepositoryInstance, DivisionRepositoryInstance, FleetRepositoryInstance,
+		game.NewFleetOptimizer(battleLimits, &util.UUIDGenerator{}), &util.UUIDGenerator{})
+	ScenarioLoaderInstance = game.NewScenarioLoader(DivisionRepositoryInstance, ShipModelRepositoryInstance, FleetBuildRepositoryInstance, AuthenticationManagerInstance, ResetTestData)
+	ArchiveServiceInstance = game.NewArchiveService(DivisionRepositoryInstance, ShipModelRepositoryInstance, FleetBuildRepositoryInstance, FleetRepositoryInstance,
+		BattleRepositoryInstance, RatingRepositoryInstance, MapRepositoryInstance, BudgetRepositoryInstance, TurnRepositoryInstance, AuthenticationManagerInstance, &util.UUIDGenerator{})
+	// started by the server, the turns are advanced manually in tests
+	TurnSchedulerInstance = game.NewTurnScheduler(TurnServiceInstance, time.Second)
+	MatchmakingSchedulerInstance = game.NewMatchmakingScheduler(MatchmakerInstance, time.Second)
 
 	// Controllers are environment-agnostic
 	BattleControllerInstance = api.NewBattleController(BattleRepositoryInstance)
 	DivisionControllerInstance = api.NewDivisionController(DivisionRepositoryInstance)
-	FleetBuildControllerInstance = api.NewFleetBuildController(AuthenticationManagerInstance, FleetBuildRepositoryInstance, FleetRepositoryInstance, ShipModelRepositoryInstance, DivisionRepositoryInstance)
-	ShipModelControllerInstance = api.NewShipModelController(AuthenticationManagerInstance, ShipModelRepositoryInstance)
+	FleetBuildControllerInstance = api.NewFleetBuildController(AuthenticationManagerInstance, FleetBuildRepositoryInstance, FleetRepositoryInstance, ShipModelRepositoryInstance, DivisionRepositoryInstance, FleetBuilderInstance, TurnServiceInstance, EconomyServiceInstance)
+	ShipModelControllerInstance = api.NewShipModelController(AuthenticationManagerInstance, ShipModelRepositoryInstance, DivisionRepositoryInstance)
+	TechTreeControllerInstance = api.NewTechTreeController(galaxy.DefaultTechTree())
+	AIControllerInstance = api.NewAIController(DivisionRepositoryInstance, &util.UUIDGenerator{})
+	EconomyControllerInstance = api.NewEconomyController(AuthenticationManagerInstance, DivisionRepositoryInstance, EconomyServiceInstance)
+	TurnControllerInstance = api.NewTurnController(AuthenticationManagerInstance, TurnRepositoryInstance, TurnServiceInstance)
+	TournamentControllerInstance = api.NewTournamentController(AuthenticationManagerInstance, TournamentRepositoryInstance, TournamentServiceInstance)
+	RatingControllerInstance = api.NewRatingController(AuthenticationManagerInstance, RatingRepositoryInstance, DivisionRepositoryInstance, RatingServiceInstance)
+	MatchmakingControllerInstance = api.NewMatchmakingController(AuthenticationManagerInstance, MatchmakingRepositoryInstance, MatchmakerInstance)
+	OptimizationControllerInstance = api.NewOptimizationController(AuthenticationManagerInstance, OptimizationServiceInstance)
+	ScenarioControllerInstance = api.NewScenarioController(AuthenticationManagerInstance, ScenarioLoaderInstance)
+	ArchiveControllerInstance = api.NewArchiveController(AuthenticationManagerInstance, ArchiveServiceInstance)
+	FleetControllerInstance = api.NewFleetController(AuthenticationManagerInstance, FleetRepositoryInstance, ShipyardInstance)
+	MapControllerInstance = api.NewMapController(AuthenticationManagerInstance, MapRepositoryInstance, FleetRepositoryInstance, DivisionRepositoryInstance, BattleRepositoryInstance, TurnServiceInstance)
+	HealthControllerInstance = api.NewHealthController(map[string]api.HealthCheck{
+		"storage":               checkStorage,
+		"turn_scheduler":        checkRunning("turn scheduler", TurnSchedulerInstance.Running),
+		"matchmaking_scheduler": checkRunning("matchmaking scheduler", MatchmakingSchedulerInstance.Running),
+		"optimizations":         checkRunning("optimization service", OptimizationServiceInstance.Accepting),
+	})
+	MetricsControllerInstance = api.NewMetricsController(metrics.Default)
+	registerGauges(metrics.Default)
+
+	return nil
+}
+
+// registerGauges registers the gauges read from the services when the metrics are scraped.
+func registerGauges(registry *metrics.Registry) {
+	registry.GaugeFunc("galaktika_optimizations_running", "Fleet build optimizations running in the background.", "", func() map[string]float64 {
+		return map[string]float64{"": float64(OptimizationServiceInstance.Running())}
+	})
+	registry.GaugeFunc("galaktika_matchmaking_waiting", "Entries waiting in the matchmaking queue.", "", func() map[string]float64 {
+		return map[string]float64{"": float64(MatchmakerInstance.Waiting())}
+	})
+	registry.GaugeFunc("galaktika_repository_objects", "Objects stored in the repositories.", "repository", func() map[string]float64 {
+		return map[string]float64{
+			"divisions":    float64(DivisionRepositoryInstance.Count()),
+			"ship_models":  float64(ShipModelRepositoryInstance.Count()),
+			"fleet_builds": float64(FleetBuildRepositoryInstance.Count()),
+			"fleets":       float64(FleetRepositoryInstance.Count()),
+			"battles":      float64(BattleRepositoryInstance.Count()),
+		}
+	})
+}
+
+// checkStorage checks the repositories can be used. The in-memory repositories are always available,
+// the database backends ping their connection here.
+func checkStorage() error {
+	if DivisionRepositoryInstance == nil {
+		return errors.New("repositories are not created")
+	}
+
+	return nil
+}
+
+func checkRunning(name string, running func() bool) api.HealthCheck {
+	return func() error {
+		if !running() {
+			return errors.New(name + " is not running")
+		}
+
+		return nil
+	}
+}
+
+// Shutdown stops the background workers and flushes the storage. The turn resolution and the matching
+// in progress end before their schedulers stop, the running optimizations get the time left by the context.
+func Shutdown(ctx context.Context) error {
+	if HealthControllerInstance != nil {
+		HealthControllerInstance.SetReady(false)
+	}
+	TurnSchedulerInstance.Stop()
+	MatchmakingSchedulerInstance.Stop()
+	if err := OptimizationServiceInstance.Shutdown(ctx); err != nil {
+		return fmt.Errorf("waiting for the optimizations: %w", err)
+	}
+
+	// the in-memory storage has nothing to flush, the database backends close their connections here
+	return nil
 }
 
 // ResetTestData clears all data in repositories for testing.
@@ -48,13 +198,39 @@ func CreateSingletons(env string) {
 // is shared across multiple test cases and repositories need to be reset
 // between tests to ensure data isolation.
 func ResetTestData() {
-	// TODO: Add BattleRepositoryInstance.ResetData() when implemented
+	if BattleRepositoryInstance != nil {
+		BattleRepositoryInstance.ResetData()
+	}
 	if DivisionRepositoryInstance != nil {
 		DivisionRepositoryInstance.ResetData()
 	}
 	if FleetBuildRepositoryInstance != nil {
 		FleetBuildRepositoryInstance.ResetData()
 	}
+	if FleetRepositoryInstance != nil {
+		FleetRepositoryInstance.ResetData()
+	}
+	if MapRepositoryInstance != nil {
+		MapRepositoryInstance.ResetData()
+	}
+	if BudgetRepositoryInstance != nil {
+		BudgetRepositoryInstance.ResetData()
+	}
+	if TurnRepositoryInstance != nil {
+		TurnRepositoryInstance.ResetData()
+	}
+	if TournamentRepositoryInstance != nil {
+		TournamentRepositoryInstance.ResetData()
+	}
+	if RatingRepositoryInstance != nil {
+		RatingRepositoryInstance.ResetData()
+	}
+	if MatchmakingRepositoryInstance != nil {
+		MatchmakingRepositoryInstance.ResetData()
+	}
+	if OptimizationRepositoryInstance != nil {
+		OptimizationRepositoryInstance.ResetData()
+	}
 	if ShipModelRepositoryInstance != nil {
 		ShipModelRepositoryInstance.ResetData()
 	}
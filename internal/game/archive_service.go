@@ -0,0 +1,344 @@
+package game
+
+import (
+	"cmp"
+	"encoding/json"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RaceDirectory finds the races known by the server.
+type RaceDirectory interface {
+	FindRace(id string) *galaxy.Race
+}
+
+// ArchiveService exports the complete state of a division and imports it back, possibly into another server.
+type ArchiveService struct {
+	divisionRepository   *dao.DivisionRepository
+	shipModelRepository  *dao.ShipModelRepository
+	fleetBuildRepository *dao.FleetBuildRepository
+	fleetRepository      *dao.FleetRepository
+	battleRepository     *dao.BattleRepository
+	ratingRepository     *dao.RatingRepository
+	mapRepository        *dao.MapRepository
+	budgetRepository     *dao.BudgetRepository
+	turnRepository       *dao.TurnRepository
+	raceDirectory        RaceDirectory
+	idGenerator          util.IdGenerator
+	now                  func() time.Time
+}
+
+func NewArchiveService(
+	divisionRepository *dao.DivisionRepository,
+	shipModelRepository *dao.ShipModelRepository,
+	fleetBuildRepository *dao.FleetBuildRepository,
+	fleetRepository *dao.FleetRepository,
+	battleRepository *dao.BattleRepository,
+	ratingRepository *dao.RatingRepository,
+	mapRepository *dao.MapRepository,
+	budgetRepository *dao.BudgetRepository,
+	turnRepository *dao.TurnRepository,
+	raceDirectory RaceDirectory,
+	idGenerator util.IdGenerator,
+) *ArchiveService {
+	return &ArchiveService{
+		divisionRepository:   divisionRepository,
+		shipModelRepository:  shipModelRepository,
+		fleetBuildRepository: fleetBuildRepository,
+		fleetRepository:      fleetRepository,
+		battleRepository:     battleRepository,
+		ratingRepository:     ratingRepository,
+		mapRepository:        mapRepository,
+		budgetRepository:     budgetRepository,
+		turnRepository:       turnRepository,
+		raceDirectory:        raceDirectory,
+		idGenerator:          idGenerator,
+		now:                  time.Now,
+	}
+}
+
+// Export collects the division with its races, ship models, fleet builds, map, fleets, battles, ratings and budgets.
+func (s *ArchiveService) Export(divisionId string) (*galaxy.DivisionArchive, error) {
+	division := s.divisionRepository.Get(divisionId)
+	if division == nil {
+		return nil, ErrDivisionNotFound
+	}
+
+	archive := &galaxy.DivisionArchive{
+		Format:         galaxy.ARCHIVE_FORMAT,
+		Version:        galaxy.ARCHIVE_VERSION,
+		ExportedAt:     s.now(),
+		Division:       division,
+		MapTime:        s.mapRepository.GetTime(divisionId),
+		TurnState:      s.turnRepository.GetState(divisionId),
+		FleetBuilds:    s.fleetBuildRepository.GetAll(divisionId, ""),
+		Planets:        s.mapRepository.GetPlanets(divisionId),
+		DivisionFleets: s.fleetRepository.FindDivisionFleets(divisionId),
+		Battles:        s.battleRepository.FindByDivision(divisionId),
+		Ratings:        s.ratingRepository.FindByDivision(divisionId),
+		Budgets:        s.budgetRepository.FindByDivision(divisionId),
+	}
+
+	// the built fleets of the races are on the map only when they are deployed
+	fleets := map[string]*galaxy.Fleet{}
+	for _, fleet := range s.fleetRepository.FindByDivision(divisionId) {
+		fleets[fleet.ID] = fleet
+	}
+	for _, divisionFleet := range archive.DivisionFleets {
+		if fleet := s.fleetRepository.Get(divisionFleet.FleetId); fleet != nil {
+			fleets[fleet.ID] = fleet
+		}
+	}
+	archive.Fleets = slices.SortedFunc(maps.Values(fleets), func(a, b *galaxy.Fleet) int { return strings.Compare(a.ID, b.ID) })
+	for _, fleet := range archive.Fleets {
+		archive.FleetEvents = append(archive.FleetEvents, s.fleetRepository.GetHistory(fleet.ID)...)
+	}
+
+	races := map[string]bool{}
+	for _, fleetBuild := range archive.FleetBuilds {
+		races[fleetBuild.RaceId] = true
+	}
+	for _, divisionFleet := range archive.DivisionFleets {
+		races[divisionFleet.UserId] = true
+	}
+	for _, fleet := range archive.Fleets {
+		races[fleet.Owner] = true
+	}
+	for _, planet := range archive.Planets {
+		if planet.OwnerId != "" {
+			races[planet.OwnerId] = true
+		}
+	}
+	for _, rating := range archive.Ratings {
+		races[rating.RaceId] = true
+	}
+	for _, budget := range archive.Budgets {
+		races[budget.RaceId] = true
+	}
+
+	shipModelIds := map[string]bool{}
+	for _, raceId := range slices.Sorted(maps.Keys(races)) {
+		archiveRace := &galaxy.ArchiveRace{ID: raceId}
+		if race := s.raceDirectory.FindRace(raceId); race != nil {
+			archiveRace.Name = race.Name
+			archiveRace.Role = race.Role
+		}
+		archive.Races = append(archive.Races, archiveRace)
+
+		for _, change := range s.ratingRepository.GetHistory(raceId) {
+			if change.DivisionId == divisionId {
+				archive.RatingChanges = append(archive.RatingChanges, change)
+			}
+		}
+		for _, shipModel := range s.shipModelRepository.GetAll(raceId) {
+			shipModelIds[shipModel.ID] = true
+		}
+	}
+
+	for _, fleetBuild := range archive.FleetBuilds {
+		for _, assignment := range s.fleetBuildRepository.FindAssignedShipModels(fleetBuild.ID) {
+			archive.Assignments = append(archive.Assignments, &galaxy.ArchiveAssignment{
+				FleetBuildId:     assignment.FleetBuildID,
+				ShipModelId:      assignment.ShipModelID,
+				ShipModelVersion: assignment.ShipModelVersion,
+				Amount:           assignment.Amount,
+			})
+			// the assigned ship models may be deleted or owned by other races
+			shipModelIds[assignment.ShipModelID] = true
+		}
+	}
+	for _, shipModelId := range slices.Sorted(maps.Keys(shipModelIds)) {
+		archive.ShipModels = append(archive.ShipModels, s.shipModelRepository.GetVersions(shipModelId)...)
+	}
+
+	return archive, nil
+}
+
+// Import stores the archive, returning the ids changed by the import. The archived ids which are already
+// stored are handled by the conflict option of the import, nothing is stored when the import fails.
+func (s *ArchiveService) Import(archive *galaxy.DivisionArchive, options galaxy.ArchiveImportOptions) (*galaxy.ArchiveMapping, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the archive is changed by the remapping, the caller keeps its copy
+	archive, err := cloneArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := s.mapIds(archive, options)
+	if err != nil {
+		return nil, err
+	}
+	archive.Remap(mapping)
+
+	s.divisionRepository.Upsert(archive.Division)
+	s.mapRepository.SetTime(archive.Division.ID, archive.MapTime)
+	if archive.TurnState != nil {
+		s.turnRepository.UpsertState(archive.TurnState)
+	}
+	for _, shipModel := range archive.ShipModels {
+		s.shipModelRepository.Upsert(shipModel)
+	}
+	for _, planet := range archive.Planets {
+		s.mapRepository.UpsertPlanet(planet)
+	}
+	for _, fleetBuild := range archive.FleetBuilds {
+		s.fleetBuildRepository.Upsert(fleetBuild)
+	}
+	for _, assignment := range archive.Assignments {
+		fleetBuildToShipModel := &galaxy.FleetBuildToShipModel{
+			FleetBuildID:     assignment.FleetBuildId,
+			ShipModelID:      assignment.ShipModelId,
+			ShipModelVersion: assignment.ShipModelVersion,
+			Amount:           assignment.Amount,
+			ShipModel:        s.shipModelRepository.GetVersion(assignment.ShipModelId, assignment.ShipModelVersion),
+		}
+		if fleetBuildToShipModel.ShipModel != nil {
+			fleetBuildToShipModel.ResultMass = fleetBuildToShipModel.CalculateResultMass()
+		}
+		s.fleetBuildRepository.AssignShipModel(fleetBuildToShipModel)
+	}
+
+	events := map[string][]*galaxy.FleetEvent{}
+	for _, event := range archive.FleetEvents {
+		events[event.FleetId] = append(events[event.FleetId], event)
+	}
+	for _, fleet := range archive.Fleets {
+		s.fleetRepository.Upsert(fleet)
+		s.fleetRepository.ReplaceHistory(fleet.ID, events[fleet.ID])
+	}
+	for _, divisionFleet := range archive.DivisionFleets {
+		s.fleetRepository.UpsertDivisionFleet(divisionFleet)
+	}
+	for _, battle := range archive.Battles {
+		s.battleRepository.Upsert(battle)
+	}
+	for _, rating := range archive.Ratings {
+		s.ratingRepository.Upsert(rating)
+	}
+	for _, change := range archive.RatingChanges {
+		// an overwritten division keeps its rating history
+		if !slices.ContainsFunc(s.ratingRepository.GetHistory(change.RaceId), func(stored *galaxy.RatingChange) bool {
+			return stored.DivisionId == change.DivisionId && stored.BattleId == change.BattleId && stored.Time.Equal(change.Time)
+		}) {
+			s.ratingRepository.AddChange(change)
+		}
+	}
+	for _, budget := range archive.Budgets {
+		s.budgetRepository.Upsert(budget)
+	}
+
+	return mapping, nil
+}
+
+// mapIds finds the archived ids which are already stored and maps them by the conflict option.
+func (s *ArchiveService) mapIds(archive *galaxy.DivisionArchive, options galaxy.ArchiveImportOptions) (*galaxy.ArchiveMapping, error) {
+	mapping := galaxy.NewArchiveMapping(cmp.Or(options.DivisionId, archive.Division.ID))
+	for from, to := range options.Races {
+		if from != to {
+			mapping.Races[from] = to
+		}
+	}
+
+	conflicts := []string{}
+	resolve := func(kind string, ids map[string]string, id string, stored bool) {
+		if !stored {
+			return
+		}
+		conflicts = append(conflicts, kind+" "+id)
+		if options.Conflict == galaxy.ARCHIVE_CONFLICT_RENAME {
+			ids[id] = s.idGenerator.NextId()
+		}
+	}
+
+	if s.divisionRepository.Get(mapping.Division) != nil {
+		conflicts = append(conflicts, "division "+mapping.Division)
+		if options.Conflict == galaxy.ARCHIVE_CONFLICT_RENAME {
+			mapping.Division = s.idGenerator.NextId()
+		}
+	}
+	for _, id := range archivedIds(archive.ShipModels, func(shipModel *galaxy.ShipModel) string { return shipModel.ID }) {
+		resolve("ship model", mapping.ShipModels, id, len(s.shipModelRepository.GetVersions(id)) > 0)
+	}
+	for _, fleetBuild := range archive.FleetBuilds {
+		resolve("fleet build", mapping.FleetBuilds, fleetBuild.ID, s.fleetBuildRepository.Get(fleetBuild.ID) != nil)
+	}
+	for _, planet := range archive.Planets {
+		resolve("planet", mapping.Planets, planet.ID, s.mapRepository.GetPlanet(planet.ID) != nil)
+	}
+	for _, fleet := range archive.Fleets {
+		resolve("fleet", mapping.Fleets, fleet.ID, s.fleetRepository.Get(fleet.ID) != nil)
+	}
+	for _, battle := range archive.Battles {
+		resolve("battle", mapping.Battles, battle.ID, s.battleRepository.GetBattle(battle.ID) != nil)
+	}
+
+	if len(conflicts) > 0 && cmp.Or(options.Conflict, galaxy.ARCHIVE_CONFLICT_FAIL) == galaxy.ARCHIVE_CONFLICT_FAIL {
+		return nil, &galaxy.ArchiveConflictError{Conflicts: conflicts}
+	}
+
+	// the versions of an overwritten ship model continue its stored history
+	for _, id := range archivedIds(archive.ShipModels, func(shipModel *galaxy.ShipModel) string { return shipModel.ID }) {
+		history := s.shipModelRepository.GetVersions(mapId(mapping.ShipModels, id))
+		next := 1
+		if len(history) > 0 {
+			next = history[len(history)-1].Version + 1
+		}
+
+		for _, shipModel := range archive.ShipModels {
+			if shipModel.ID != id {
+				continue
+			}
+			if shipModel.Version != next {
+				if mapping.ShipModelVersions[id] == nil {
+					mapping.ShipModelVersions[id] = map[int]int{}
+				}
+				mapping.ShipModelVersions[id][shipModel.Version] = next
+			}
+			next++
+		}
+	}
+
+	return mapping, nil
+}
+
+// archivedIds returns the distinct ids in the order of the objects.
+func archivedIds[T any](objects []T, id func(T) string) []string {
+	ids := []string{}
+	for _, object := range objects {
+		if !slices.Contains(ids, id(object)) {
+			ids = append(ids, id(object))
+		}
+	}
+
+	return ids
+}
+
+func mapId(ids map[string]string, id string) string {
+	return cmp.Or(ids[id], id)
+}
+
+// cloneArchive copies the archive through its JSON form, the same way it is moved between servers.
+func cloneArchive(archive *galaxy.DivisionArchive) (*galaxy.DivisionArchive, error) {
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &galaxy.DivisionArchive{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
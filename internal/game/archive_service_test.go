@@ -0,0 +1,279 @@
+package game
+
+import (
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+	"time"
+)
+
+type testRaceDirectory map[string]*galaxy.Race
+
+func (directory testRaceDirectory) FindRace(id string) *galaxy.Race {
+	return directory[id]
+}
+
+type testArchiveRepositories struct {
+	divisions   *dao.DivisionRepository
+	shipModels  *dao.ShipModelRepository
+	fleetBuilds *dao.FleetBuildRepository
+	fleets      *dao.FleetRepository
+	battles     *dao.BattleRepository
+	ratings     *dao.RatingRepository
+	maps        *dao.MapRepository
+	budgets     *dao.BudgetRepository
+	turns       *dao.TurnRepository
+}
+
+func newTestArchiveService() (*ArchiveService, *testArchiveRepositories) {
+	r := &testArchiveRepositories{
+		divisions:   dao.NewDivisionRepository(),
+		shipModels:  dao.NewShipModelRepository(),
+		fleetBuilds: dao.NewFleetBuildRepository(),
+		fleets:      dao.NewFleetRepository(),
+		battles:     dao.NewBattleRepository(),
+		ratings:     dao.NewRatingRepository(),
+		maps:        dao.NewMapRepository(),
+		budgets:     dao.NewBudgetRepository(),
+		turns:       dao.NewTurnRepository(),
+	}
+	races := testRaceDirectory{"r1": {ID: "r1", Name: "Red", Role: "commander"}}
+	service := NewArchiveService(r.divisions, r.shipModels, r.fleetBuilds, r.fleets, r.battles, r.ratings, r.maps, r.budgets, r.turns,
+		races, &util.SimpleIdGenerator{CurrentId: 100})
+	service.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	return service, r
+}
+
+// addTestDivision stores a division with a fleet build, a fleet which fought a battle, a planet, a rating and a budget.
+func addTestDivision(r *testArchiveRepositories) {
+	r.divisions.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: 100})
+	r.shipModels.Upsert(&galaxy.ShipModel{ID: "m1", OwnerId: "r1", Guns: 1, OneGunMass: 1, EngineMass: 1})
+	r.shipModels.Upsert(&galaxy.ShipModel{ID: "m1", OwnerId: "r1", Guns: 2, OneGunMass: 1, EngineMass: 1})
+	r.fleetBuilds.Upsert(&galaxy.FleetBuild{ID: "b1", DivisionId: "d1", RaceId: "r1"})
+	r.fleetBuilds.AssignShipModel(&galaxy.FleetBuildToShipModel{FleetBuildID: "b1", ShipModelID: "m1", ShipModelVersion: 1, Amount: 2})
+	r.maps.UpsertPlanet(&galaxy.Planet{ID: "p1", DivisionId: "d1", OwnerId: "r1"})
+	r.maps.SetTime("d1", 7)
+
+	fleet := galaxy.NewFleet([]*galaxy.Ship{{ID: "s1", Owner: "r1", ShipModelID: "m1", ShipModelVersion: 1}})
+	fleet.ID = "f1"
+	fleet.Owner = "r1"
+	fleet.DivisionId = "d1"
+	fleet.Location = "p1"
+	r.fleets.Upsert(fleet)
+	r.fleets.UpsertDivisionFleet(&galaxy.DivisionFleet{DivisionId: "d1", UserId: "r1", FleetId: "f1"})
+	r.fleets.AddEvent(&galaxy.FleetEvent{FleetId: "f1", Type: galaxy.FLEET_EVENT_BATTLE, BattleId: "bt1", PlanetId: "p1"})
+	r.battles.Upsert(&galaxy.Battle{ID: "bt1", DivisionId: "d1", Location: "p1", SideA: fleet.Snapshot(), SideB: galaxy.NewFleet(nil)})
+
+	r.ratings.Upsert(&galaxy.Rating{DivisionId: "d1", RaceId: "r1", Rating: 1510, Games: 1, Wins: 1})
+	r.ratings.AddChange(&galaxy.RatingChange{DivisionId: "d1", RaceId: "r1", OpponentId: "r2", BattleId: "bt1", Before: 1500, After: 1510})
+	r.ratings.AddChange(&galaxy.RatingChange{DivisionId: "other", RaceId: "r1", BattleId: "bt2"})
+	r.budgets.Upsert(&galaxy.Budget{DivisionId: "d1", RaceId: "r1", Balance: 12})
+}
+
+func TestArchiveService_Export(t *testing.T) {
+	service, r := newTestArchiveService()
+	addTestDivision(r)
+
+	archive, err := service.Export("d1")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if archive.Format != galaxy.ARCHIVE_FORMAT || archive.Version != galaxy.ARCHIVE_VERSION || archive.MapTime != 7 {
+		t.Errorf("unexpected archive header %s %d, map time %v", archive.Format, archive.Version, archive.MapTime)
+	}
+	if len(archive.Races) != 1 || *archive.Races[0] != (galaxy.ArchiveRace{ID: "r1", Name: "Red", Role: "commander"}) {
+		t.Errorf("unexpected races %v", archive.Races)
+	}
+	if len(archive.ShipModels) != 2 || archive.ShipModels[1].Version != 2 {
+		t.Errorf("expected both versions of the ship model, got %v", archive.ShipModels)
+	}
+	counts := map[string]int{
+		"fleet builds":   len(archive.FleetBuilds),
+		"assignments":    len(archive.Assignments),
+		"planets":        len(archive.Planets),
+		"fleets":         len(archive.Fleets),
+		"fleet events":   len(archive.FleetEvents),
+		"battles":        len(archive.Battles),
+		"ratings":        len(archive.Ratings),
+		"rating changes": len(archive.RatingChanges),
+		"budgets":        len(archive.Budgets),
+	}
+	for name, count := range counts {
+		if count != 1 {
+			t.Errorf("expected 1 of %s, got %d", name, count)
+		}
+	}
+
+	if _, err := service.Export("unknown"); !errors.Is(err, ErrDivisionNotFound) {
+		t.Errorf("expected ErrDivisionNotFound, got %v", err)
+	}
+}
+
+func TestArchiveService_Import(t *testing.T) {
+	source, r := newTestArchiveService()
+	addTestDivision(r)
+	archive, err := source.Export("d1")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	service, target := newTestArchiveService()
+	mapping, err := service.Import(archive, galaxy.ArchiveImportOptions{Races: map[string]string{"r1": "red"}})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if mapping.Division != "d1" || mapping.Races["r1"] != "red" || len(mapping.ShipModels) != 0 {
+		t.Errorf("unexpected mapping %+v", mapping)
+	}
+	if fleetBuild := target.fleetBuilds.Get("b1"); fleetBuild == nil || fleetBuild.RaceId != "red" {
+		t.Errorf("expected fleet build of race red, got %+v", fleetBuild)
+	}
+	if assignment := target.fleetBuilds.FindAssignedShipModel("b1", "m1"); assignment == nil || assignment.ShipModelVersion != 1 || assignment.ShipModel == nil {
+		t.Errorf("unexpected assignment %+v", assignment)
+	}
+	fleet := target.fleets.Get("f1")
+	if fleet == nil || fleet.Owner != "red" || fleet.GetShipById("s1") == nil || fleet.GetShipById("s1").Owner != "red" {
+		t.Errorf("expected the fleet of race red with its ship index, got %+v", fleet)
+	}
+	if history := target.fleets.GetHistory("f1"); len(history) != 1 || history[0].BattleId != "bt1" {
+		t.Errorf("unexpected fleet history %v", history)
+	}
+	if battle := target.battles.GetBattle("bt1"); battle == nil || battle.SideA.Owner != "red" {
+		t.Errorf("unexpected battle %+v", battle)
+	}
+	if rating := target.ratings.Get("d1", "red"); rating == nil || rating.Rating != 1510 {
+		t.Errorf("unexpected rating %+v", rating)
+	}
+	if history := target.ratings.GetHistory("red"); len(history) != 1 || history[0].BattleId != "bt1" {
+		t.Errorf("expected the rating history of the division only, got %v", history)
+	}
+	if budget := target.budgets.Get("d1", "red"); budget == nil || budget.Balance != 12 {
+		t.Errorf("unexpected budget %+v", budget)
+	}
+	if target.maps.GetTime("d1") != 7 || target.maps.GetPlanet("p1").OwnerId != "red" {
+		t.Error("expected the map of the division")
+	}
+
+	// the exported archive is not changed by the import
+	if archive.FleetBuilds[0].RaceId != "r1" {
+		t.Errorf("expected the archive unchanged, got race %s", archive.FleetBuilds[0].RaceId)
+	}
+}
+
+func TestArchiveService_Import_Conflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		conflict string
+		check    func(t *testing.T, mapping *galaxy.ArchiveMapping, r *testArchiveRepositories)
+	}{
+		{
+			name:     "rename",
+			conflict: galaxy.ARCHIVE_CONFLICT_RENAME,
+			check: func(t *testing.T, mapping *galaxy.ArchiveMapping, r *testArchiveRepositories) {
+				division := mapping.Division
+				fleetBuildId, shipModelId, fleetId := mapping.FleetBuilds["b1"], mapping.ShipModels["m1"], mapping.Fleets["f1"]
+				if division == "d1" || fleetBuildId == "" || shipModelId == "" || fleetId == "" || mapping.Battles["bt1"] == "" || mapping.Planets["p1"] == "" {
+					t.Fatalf("expected all the ids renamed, got %+v", mapping)
+				}
+				if len(r.shipModels.GetVersions(shipModelId)) != 2 || len(r.shipModels.GetVersions("m1")) != 2 {
+					t.Error("expected a renamed copy of the ship model")
+				}
+				assignment := r.fleetBuilds.FindAssignedShipModel(fleetBuildId, shipModelId)
+				if assignment == nil || assignment.ShipModelVersion != 1 {
+					t.Errorf("unexpected assignment %+v", assignment)
+				}
+				fleet := r.fleets.Get(fleetId)
+				if fleet == nil || fleet.DivisionId != division || fleet.Location != mapping.Planets["p1"] || fleet.Ships[0].ShipModelID != shipModelId {
+					t.Errorf("expected the references of the fleet renamed, got %+v", fleet)
+				}
+				if divisionFleet := r.fleets.GetDivisionFleet(division, "r1"); divisionFleet == nil || divisionFleet.FleetId != fleetId {
+					t.Errorf("unexpected division fleet %+v", divisionFleet)
+				}
+				if r.fleetBuilds.Get("b1").DivisionId != "d1" {
+					t.Error("expected the stored fleet build unchanged")
+				}
+			},
+		},
+		{
+			name:     "overwrite",
+			conflict: galaxy.ARCHIVE_CONFLICT_OVERWRITE,
+			check: func(t *testing.T, mapping *galaxy.ArchiveMapping, r *testArchiveRepositories) {
+				if mapping.Division != "d1" || len(mapping.FleetBuilds) != 0 {
+					t.Fatalf("expected the ids kept, got %+v", mapping)
+				}
+				if versions := mapping.ShipModelVersions["m1"]; versions[1] != 3 || versions[2] != 4 {
+					t.Errorf("expected the versions after the stored history, got %v", versions)
+				}
+				if len(r.shipModels.GetVersions("m1")) != 4 {
+					t.Errorf("expected 4 versions of the ship model, got %d", len(r.shipModels.GetVersions("m1")))
+				}
+				if assignment := r.fleetBuilds.FindAssignedShipModel("b1", "m1"); assignment == nil || assignment.ShipModelVersion != 3 {
+					t.Errorf("expected the assigned version remapped, got %+v", assignment)
+				}
+				if ship := r.fleets.Get("f1").GetShipById("s1"); ship == nil || ship.ShipModelVersion != 3 {
+					t.Errorf("expected the ship version remapped, got %+v", ship)
+				}
+				if history := r.ratings.GetHistory("r1"); len(history) != 2 {
+					t.Errorf("expected the rating history kept without duplicates, got %d changes", len(history))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, r := newTestArchiveService()
+			addTestDivision(r)
+			archive, err := service.Export("d1")
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			_, err = service.Import(archive, galaxy.ArchiveImportOptions{})
+			var conflictError *galaxy.ArchiveConflictError
+			if !errors.As(err, &conflictError) || len(conflictError.Conflicts) != 6 {
+				t.Fatalf("expected 6 conflicts, got %v", err)
+			}
+
+			mapping, err := service.Import(archive, galaxy.ArchiveImportOptions{Conflict: tt.conflict})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			tt.check(t, mapping, r)
+		})
+	}
+}
+
+func TestArchiveService_Import_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive *galaxy.DivisionArchive
+		options galaxy.ArchiveImportOptions
+	}{
+		{name: "unknown format", archive: &galaxy.DivisionArchive{Format: "other", Version: 1, Division: &galaxy.Division{ID: "d1"}}},
+		{name: "newer version", archive: &galaxy.DivisionArchive{Format: galaxy.ARCHIVE_FORMAT, Version: galaxy.ARCHIVE_VERSION + 1, Division: &galaxy.Division{ID: "d1"}}},
+		{name: "no division", archive: &galaxy.DivisionArchive{Format: galaxy.ARCHIVE_FORMAT, Version: 1}},
+		{
+			name:    "unknown conflict handling",
+			archive: &galaxy.DivisionArchive{Format: galaxy.ARCHIVE_FORMAT, Version: 1, Division: &galaxy.Division{ID: "d1"}},
+			options: galaxy.ArchiveImportOptions{Conflict: "merge"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, r := newTestArchiveService()
+			if _, err := service.Import(tt.archive, tt.options); err == nil {
+				t.Fatal("expected an error")
+			}
+			if r.divisions.Get("d1") != nil {
+				t.Error("expected nothing stored")
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package game
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// Balance analysis defaults
+const (
+	// share of the battles against the other archetypes one archetype may win in a balanced rule set
+	BALANCE_THRESHOLD = 0.6
+	// exponent of the ship mass in the defense formula of the game
+	DEFENSE_MASS_EXPONENT = 0.5
+	BALANCE_BUDGET        = 300
+)
+
+// BalanceRules are the tuned numbers of the game the archetypes fight with.
+type BalanceRules struct {
+	// resources of one technology level, the research is linear
+	TechCost float64 `json:"tech_cost"`
+	// the ship defense is DefenseMass * Defense / mass^DefenseExponent
+	DefenseExponent float64          `json:"defense_exponent"`
+	Destruction     DestructionCurve `json:"destruction"`
+	// resources of every fleet
+	Budget int `json:"budget"`
+}
+
+func DefaultBalanceRules() BalanceRules {
+	return BalanceRules{
+		TechCost:        galaxy.ONE_TECH_RESOURCES,
+		DefenseExponent: DEFENSE_MASS_EXPONENT,
+		Destruction:     DefaultDestructionCurve(),
+		Budget:          BALANCE_BUDGET,
+	}
+}
+
+func (rules BalanceRules) Validate() error {
+	if rules.TechCost <= 0 {
+		return errors.New("tech_cost must be positive")
+	}
+	if rules.DefenseExponent < 0 || math.IsNaN(rules.DefenseExponent) {
+		return errors.New("defense_exponent must not be negative")
+	}
+	if rules.Budget < galaxy.AI_MIN_SHIP_MASS {
+		return fmt.Errorf("budget must be at least %d", galaxy.AI_MIN_SHIP_MASS)
+	}
+	if _, err := rules.Destruction.function(); err != nil {
+		return fmt.Errorf("destruction curve: %w", err)
+	}
+
+	return nil
+}
+
+// BalanceSweep lists the values of every tuned number, the rule sets are all their combinations.
+// The default value is used when a list is empty.
+type BalanceSweep struct {
+	TechCosts         []float64          `json:"tech_costs"`
+	DefenseExponents  []float64          `json:"defense_exponents"`
+	DestructionCurves []DestructionCurve `json:"destruction_curves"`
+	Budgets           []int              `json:"budgets"`
+}
+
+// RuleSets returns the combinations of the sweep values.
+func (sweep BalanceSweep) RuleSets() []BalanceRules {
+	defaults := DefaultBalanceRules()
+	orDefault := func(values []float64, value float64) []float64 {
+		if len(values) == 0 {
+			return []float64{value}
+		}
+		return values
+	}
+
+	curves := sweep.DestructionCurves
+	if len(curves) == 0 {
+		curves = []DestructionCurve{defaults.Destruction}
+	}
+	budgets := sweep.Budgets
+	if len(budgets) == 0 {
+		budgets = []int{defaults.Budget}
+	}
+
+	ruleSets := []BalanceRules{}
+	for _, techCost := range orDefault(sweep.TechCosts, defaults.TechCost) {
+		for _, exponent := range orDefault(sweep.DefenseExponents, defaults.DefenseExponent) {
+			for _, curve := range curves {
+				for _, budget := range budgets {
+					ruleSets = append(ruleSets, BalanceRules{TechCost: techCost, DefenseExponent: exponent, Destruction: curve, Budget: budget})
+				}
+			}
+		}
+	}
+
+	return ruleSets
+}
+
+// BalanceStrategy is the result of an archetype against all the other archetypes of a rule set.
+type BalanceStrategy struct {
+	Archetype string  `json:"archetype"`
+	Wins      int     `json:"wins"`
+	Draws     int     `json:"draws"`
+	Losses    int     `json:"losses"`
+	WinRate   float64 `json:"win_rate"`
+}
+
+// BalanceReport holds the win rates of the archetypes fighting each other with a rule set.
+type BalanceReport struct {
+	Rules      BalanceRules `json:"rules"`
+	Archetypes []string     `json:"archetypes"`
+	// share of the battles the archetype of the row won against the archetype of the column
+	WinRates   [][]float64        `json:"win_rates"`
+	Strategies []*BalanceStrategy `json:"strategies"`
+	// the archetype with the highest win rate
+	Dominant string `json:"dominant"`
+	// the dominant archetype wins above the threshold
+	Unbalanced bool `json:"unbalanced"`
+}
+
+// BalanceAnalyzer fights the AI archetypes against each other with the tuned rule sets.
+// Every pair of the archetypes fights the same seeded battles in all the rule sets.
+type BalanceAnalyzer struct {
+	battleLimits BattleLimits
+	idGenerator  util.IdGenerator
+	// battles of every pair of the archetypes
+	battles   int
+	seed      uint64
+	threshold float64
+}
+
+func NewBalanceAnalyzer(battleLimits BattleLimits, idGenerator util.IdGenerator, battles int, seed uint64, threshold float64) *BalanceAnalyzer {
+	return &BalanceAnalyzer{battleLimits: battleLimits, idGenerator: idGenerator, battles: battles, seed: seed, threshold: threshold}
+}
+
+func balanceBattleSeed(seed uint64, battle int) uint64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "balance/%d/%d", seed, battle)
+
+	// seed 0 would be replaced by a random seed
+	return hash.Sum64() | 1
+}
+
+// Sweep analyzes every rule set of the sweep. The progress is called after every analyzed rule set.
+func (a *BalanceAnalyzer) Sweep(sweep BalanceSweep, progress func(done int, total int)) ([]*BalanceReport, error) {
+	ruleSets := sweep.RuleSets()
+
+	reports := make([]*BalanceReport, 0, len(ruleSets))
+	for i, rules := range ruleSets {
+		report, err := a.Analyze(rules)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+
+		if progress != nil {
+			progress(i+1, len(ruleSets))
+		}
+	}
+
+	return reports, nil
+}
+
+// Analyze fights every pair of the archetypes, the mirror matches included, with the rule set.
+func (a *BalanceAnalyzer) Analyze(rules BalanceRules) (*BalanceReport, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	if a.battles <= 0 {
+		return nil, errors.New("battles must be positive")
+	}
+	destruction, _ := rules.Destruction.function()
+
+	fleets := make([]*galaxy.Fleet, len(galaxy.Archetypes))
+	report := &BalanceReport{Rules: rules, WinRates: make([][]float64, len(galaxy.Archetypes))}
+	for i, archetype := range galaxy.Archetypes {
+		fleet, err := a.archetypeFleet(archetype, rules)
+		if err != nil {
+			return nil, fmt.Errorf("archetype %s: %w", archetype.Name, err)
+		}
+		fleets[i] = fleet
+		report.Archetypes = append(report.Archetypes, archetype.Name)
+		report.WinRates[i] = make([]float64, len(galaxy.Archetypes))
+		report.Strategies = append(report.Strategies, &BalanceStrategy{Archetype: archetype.Name})
+	}
+
+	for i := range fleets {
+		for j := i; j < len(fleets); j++ {
+			wins, losses := 0, 0
+			for battle := 0; battle < a.battles; battle++ {
+				rng := gamemath.NewStdRandomGenerator(balanceBattleSeed(a.seed, battle))
+				switch galaxy.BattleScore(executeBattleWithDestruction(a.battleLimits, a.idGenerator, rng, destruction, fleets[i].Snapshot(), fleets[j].Snapshot())) {
+				case galaxy.SCORE_WIN:
+					wins++
+				case galaxy.SCORE_LOSS:
+					losses++
+				}
+			}
+			report.WinRates[i][j] = float64(wins) / float64(a.battles)
+			// the mirror matches do not count to the strategies
+			if i != j {
+				report.WinRates[j][i] = float64(losses) / float64(a.battles)
+				report.Strategies[i].add(wins, losses, a.battles)
+				report.Strategies[j].add(losses, wins, a.battles)
+			}
+		}
+	}
+
+	for _, strategy := range report.Strategies {
+		if battles := strategy.Wins + strategy.Draws + strategy.Losses; battles > 0 {
+			strategy.WinRate = float64(strategy.Wins) / float64(battles)
+		}
+	}
+	// the first archetype is dominant on a tie
+	dominant := slices.MaxFunc(report.Strategies, func(x, y *BalanceStrategy) int {
+		return cmp.Compare(x.WinRate, y.WinRate)
+	})
+	report.Dominant = dominant.Archetype
+	report.Unbalanced = dominant.WinRate > a.threshold
+
+	return report, nil
+}
+
+func (strategy *BalanceStrategy) add(wins int, losses int, battles int) {
+	strategy.Wins += wins
+	strategy.Losses += losses
+	strategy.Draws += battles - wins - losses
+}
+
+// archetypeFleet generates the fleet of the archetype researching with the tech cost of the rules.
+func (a *BalanceAnalyzer) archetypeFleet(archetype *galaxy.Archetype, rules BalanceRules) (*galaxy.Fleet, error) {
+	researchRules := galaxy.NewLinearResearchRules()
+	for _, technology := range galaxy.TechnologyNames {
+		curve, _ := researchRules.Curve(technology)
+		curve.BaseCost = rules.TechCost
+	}
+	division := &galaxy.Division{ID: "balance", ResourcesAmount: rules.Budget, ResearchRules: researchRules}
+
+	fleetBuild, err := galaxy.GenerateFleetBuild(division, archetype, 0, a.idGenerator)
+	if err != nil {
+		return nil, err
+	}
+	fleetBuild.RaceId = archetype.Name
+
+	fleet := GenerateFleet(fleetBuild, a.idGenerator)
+	for _, ship := range fleet.Ships {
+		// the ships are generated with the square root of the mass in the defense formula
+		ship.Tech.Defense *= math.Pow(ship.Tech.Mass, DEFENSE_MASS_EXPONENT-rules.DefenseExponent)
+	}
+
+	return fleet, nil
+}
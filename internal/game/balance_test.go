@@ -0,0 +1,112 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+)
+
+func TestBalanceSweep_RuleSets(t *testing.T) {
+	sweep := BalanceSweep{
+		TechCosts:         []float64{50, 100, 200},
+		DestructionCurves: []DestructionCurve{DefaultDestructionCurve(), {Ratios: []float64{0.5, 2}, Chances: []float64{1, 0}}},
+	}
+
+	ruleSets := sweep.RuleSets()
+	if len(ruleSets) != 6 {
+		t.Fatalf("expected 6 combinations, got %d", len(ruleSets))
+	}
+	for _, rules := range ruleSets {
+		if rules.DefenseExponent != DEFENSE_MASS_EXPONENT || rules.Budget != BALANCE_BUDGET {
+			t.Errorf("expected the defaults for the values not swept, got %+v", rules)
+		}
+	}
+	if ruleSets[0].TechCost != 50 || ruleSets[5].TechCost != 200 || len(ruleSets[5].Destruction.Ratios) != 2 {
+		t.Errorf("unexpected combinations %+v", ruleSets)
+	}
+}
+
+func TestBalanceAnalyzer_Analyze(t *testing.T) {
+	report, err := NewBalanceAnalyzer(DefaultBattleLimits(), &util.SimpleIdGenerator{}, 10, 1, BALANCE_THRESHOLD).Analyze(DefaultBalanceRules())
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if len(report.Archetypes) != len(galaxy.Archetypes) || len(report.WinRates) != len(galaxy.Archetypes) {
+		t.Fatalf("expected a row per archetype, got %+v", report)
+	}
+	for i := range report.WinRates {
+		for j := range report.WinRates {
+			if i != j && report.WinRates[i][j]+report.WinRates[j][i] > 1 {
+				t.Errorf("win rates of %s and %s exceed all the battles", report.Archetypes[i], report.Archetypes[j])
+			}
+		}
+	}
+
+	dominant := report.Strategies[0]
+	for _, strategy := range report.Strategies {
+		// every archetype fights the others, the mirror matches are not counted
+		if battles := strategy.Wins + strategy.Draws + strategy.Losses; battles != 10*(len(galaxy.Archetypes)-1) {
+			t.Errorf("unexpected battles of %s: %d", strategy.Archetype, battles)
+		}
+		if strategy.WinRate > dominant.WinRate {
+			dominant = strategy
+		}
+	}
+	if report.Dominant != dominant.Archetype || report.Unbalanced != (dominant.WinRate > BALANCE_THRESHOLD) {
+		t.Errorf("unexpected dominant %s of %+v", report.Dominant, report.Strategies)
+	}
+
+	// the same seed fights the same battles, any dominant archetype wins above zero
+	flagged, _ := NewBalanceAnalyzer(DefaultBattleLimits(), &util.SimpleIdGenerator{}, 10, 1, 0).Analyze(DefaultBalanceRules())
+	if flagged.Dominant != report.Dominant || !flagged.Unbalanced {
+		t.Errorf("expected the rule set to be flagged, got %+v", flagged)
+	}
+}
+
+func TestBalanceAnalyzer_archetypeFleet(t *testing.T) {
+	analyzer := NewBalanceAnalyzer(DefaultBattleLimits(), &util.SimpleIdGenerator{}, 1, 1, BALANCE_THRESHOLD)
+	archetype := galaxy.GetArchetype(galaxy.ARCHETYPE_TANK)
+
+	rules := DefaultBalanceRules()
+	standard, _ := analyzer.archetypeFleet(archetype, rules)
+
+	rules.DefenseExponent = 1
+	rules.TechCost = 50
+	tuned, _ := analyzer.archetypeFleet(archetype, rules)
+
+	ship := standard.Ships[0].Tech
+	if tuned.Ships[0].Tech.Defense >= ship.Defense {
+		t.Errorf("expected the heavier mass exponent to weaken the defense: %g, %g", tuned.Ships[0].Tech.Defense, ship.Defense)
+	}
+	if tuned.Ships[0].Tech.Attack <= ship.Attack {
+		t.Errorf("expected the cheaper research to raise the attack: %g, %g", tuned.Ships[0].Tech.Attack, ship.Attack)
+	}
+}
+
+func TestBalanceRules_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(rules *BalanceRules)
+	}{
+		{name: "zero tech cost", modify: func(rules *BalanceRules) { rules.TechCost = 0 }},
+		{name: "negative defense exponent", modify: func(rules *BalanceRules) { rules.DefenseExponent = -1 }},
+		{name: "budget too small", modify: func(rules *BalanceRules) { rules.Budget = 2 }},
+		{name: "decreasing ratios", modify: func(rules *BalanceRules) { rules.Destruction.Ratios = []float64{4, 1, 0.25} }},
+		{name: "mismatched curve", modify: func(rules *BalanceRules) { rules.Destruction.Chances = []float64{1} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultBalanceRules()
+			tt.modify(&rules)
+			if err := rules.Validate(); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+
+	if err := DefaultBalanceRules().Validate(); err != nil {
+		t.Errorf("expected the default rules to be valid, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package game
 import (
 	"glaktika.eu/galaktika/pkg/galaxy"
 	"glaktika.eu/galaktika/pkg/util"
+	"time"
 )
 
 type ShipRef struct {
@@ -10,9 +11,21 @@ type ShipRef struct {
 	index uint
 }
 
+// BattleLimits end the battles which do not finish by themselves.
+type BattleLimits struct {
+	MaxShots int
+	// the battle is a stalemate after this many shots in a row destroyed nothing
+	StalemateShots int
+}
+
+func DefaultBattleLimits() BattleLimits {
+	return BattleLimits{MaxShots: 10000, StalemateShots: 100}
+}
+
 type BattleHandler struct {
 	decisionProducer DecisionProducerInterface
 	idGenerator      util.IdGenerator
+	limits           BattleLimits
 
 	// Battle state (implements BattleState interface)
 	shipsMapA   map[string]*galaxy.Ship
@@ -93,10 +106,12 @@ func (bh *BattleHandler) IsBattleOver() bool {
 func NewBattleHandler(
 	idGenerator util.IdGenerator,
 	decisionProducer DecisionProducerInterface,
+	limits BattleLimits,
 ) *BattleHandler {
 	return &BattleHandler{
 		decisionProducer: decisionProducer,
 		idGenerator:      idGenerator,
+		limits:           limits,
 	}
 }
 
@@ -106,6 +121,7 @@ func (bh *BattleHandler) ExecuteBattle(fleetA *galaxy.Fleet, fleetB *galaxy.Flee
 		panic("BattleHandler.ExecuteBattle: The decision producer is nil ")
 	}
 
+	start := time.Now()
 	bh.initializeBattleState(fleetA, fleetB)
 
 	battle := galaxy.Battle{
@@ -114,11 +130,10 @@ func (bh *BattleHandler) ExecuteBattle(fleetA *galaxy.Fleet, fleetB *galaxy.Flee
 		SideB: fleetB,
 	}
 
-	maxShots := 10000
-	stalemateThreshold := 100 // If 100 consecutive shots don't destroy anything, declare stalemate
 	consecutiveNonDestructiveShots := 0
+	stalemate := false
 
-	for i := 0; i < maxShots; i++ {
+	for i := 0; i < bh.limits.MaxShots; i++ {
 		if bh.IsBattleOver() {
 			break
 		}
@@ -158,9 +173,10 @@ func (bh *BattleHandler) ExecuteBattle(fleetA *galaxy.Fleet, fleetB *galaxy.Flee
 			}
 		} else {
 			consecutiveNonDestructiveShots++
-			if consecutiveNonDestructiveShots >= stalemateThreshold {
+			if consecutiveNonDestructiveShots >= bh.limits.StalemateShots {
 				// Stalemate detected - too many shots without any destruction
 				battle.Shots = append(battle.Shots, &shot)
+				stalemate = true
 				break
 			}
 		}
@@ -171,6 +187,17 @@ func (bh *BattleHandler) ExecuteBattle(fleetA *galaxy.Fleet, fleetB *galaxy.Flee
 
 	battle.PostSideA = galaxy.NewFleet(bh.shipsA)
 	battle.PostSideB = galaxy.NewFleet(bh.shipsB)
+	battle.LootA = galaxy.CalculateLoot(battle.PostSideA, battle.PostSideB)
+	battle.LootB = galaxy.CalculateLoot(battle.PostSideB, battle.PostSideA)
+
+	outcome := BATTLE_OUTCOME_FINISHED
+	switch {
+	case stalemate:
+		outcome = BATTLE_OUTCOME_STALEMATE
+	case !bh.IsBattleOver():
+		outcome = BATTLE_OUTCOME_SHOT_LIMIT
+	}
+	observeBattle(outcome, len(battle.Shots), time.Since(start))
 
 	return &battle
 }
@@ -245,7 +245,7 @@ func TestBattleHandlerWithRuntimeDecisionProducer(t *testing.T) {
 			rng := gamemath.NewPredefinedRandomGenerator(tt.randomValues)
 			idGenerator := util.NewSequenceGenerator([]string{"battle-1"})
 
-			battleHandler := NewBattleHandler(idGenerator, nil)
+			battleHandler := NewBattleHandler(idGenerator, nil, DefaultBattleLimits())
 			battleHandler.initializeBattleState(tt.fleetA, tt.fleetB)
 
 			decisionProducer := NewRuntimeDecisionProducer(rng, battleHandler)
@@ -337,7 +337,7 @@ func TestBattleHandlerWithRuntimeDecisionProducerStalemate(t *testing.T) {
 	rng := gamemath.NewPredefinedRandomGenerator(randomValues)
 	idGenerator := util.NewSequenceGenerator([]string{"battle-stalemate"})
 
-	battleHandler := NewBattleHandler(idGenerator, nil)
+	battleHandler := NewBattleHandler(idGenerator, nil, DefaultBattleLimits())
 	battleHandler.initializeBattleState(fleetA, fleetB)
 
 	decisionProducer := NewRuntimeDecisionProducer(rng, battleHandler)
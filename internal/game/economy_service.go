@@ -0,0 +1,84 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"slices"
+)
+
+// EconomyService credits the production of the planets to the budgets of their owners.
+type EconomyService struct {
+	budgetRepository *dao.BudgetRepository
+	mapRepository    *dao.MapRepository
+}
+
+func NewEconomyService(budgetRepository *dao.BudgetRepository, mapRepository *dao.MapRepository) *EconomyService {
+	return &EconomyService{budgetRepository: budgetRepository, mapRepository: mapRepository}
+}
+
+// Budget returns a copy of the budget of the race in the division. A new budget starts with the division resources,
+// it is stored only once resources are credited or spent.
+func (s *EconomyService) Budget(division *galaxy.Division, raceId string) *galaxy.Budget {
+	if budget := s.budgetRepository.Get(division.ID, raceId); budget != nil {
+		return budget
+	}
+
+	return newBudget(division, raceId)
+}
+
+// newBudget returns the budget of a race that has not spent or earned anything in the division yet.
+func newBudget(division *galaxy.Division, raceId string) *galaxy.Budget {
+	budget := &galaxy.Budget{DivisionId: division.ID, RaceId: raceId, Entries: []*galaxy.BudgetEntry{}}
+	budget.Credit(0, float64(division.ResourcesAmount), "division resources")
+
+	return budget
+}
+
+// Pay debits the amount from the budget of the race, it fails with galaxy.ErrInsufficientResources
+// when the balance is not enough.
+func (s *EconomyService) Pay(division *galaxy.Division, raceId string, turn int, amount float64, description string) error {
+	return s.budgetRepository.Debit(newBudget(division, raceId), turn, amount, description)
+}
+
+// Credit adds the amount to the budget of the race.
+func (s *EconomyService) Credit(division *galaxy.Division, raceId string, turn int, amount float64, description string) {
+	s.budgetRepository.Credit(newBudget(division, raceId), turn, amount, description)
+}
+
+// ownedPlanets returns the planets of the division owned by the race.
+func (s *EconomyService) ownedPlanets(divisionId, raceId string) []*galaxy.Planet {
+	return slices.DeleteFunc(s.mapRepository.GetPlanets(divisionId), func(planet *galaxy.Planet) bool {
+		return planet.OwnerId != raceId
+	})
+}
+
+// CollectIncome credits the production of every owned planet of the division for the turn.
+func (s *EconomyService) CollectIncome(division *galaxy.Division, turn int) {
+	for _, planet := range s.mapRepository.GetPlanets(division.ID) {
+		if planet.OwnerId == "" || planet.Production() <= 0 {
+			continue
+		}
+
+		s.Credit(division, planet.OwnerId, turn, planet.Production(), "production of "+planet.ID)
+	}
+}
+
+// Report returns the economy of the race in the division.
+func (s *EconomyService) Report(division *galaxy.Division, raceId string) *galaxy.EconomyReport {
+	budget := s.Budget(division, raceId)
+	planets := s.ownedPlanets(division.ID, raceId)
+
+	income := 0.0
+	for _, planet := range planets {
+		income += planet.Production()
+	}
+
+	return &galaxy.EconomyReport{
+		DivisionId:    division.ID,
+		RaceId:        raceId,
+		Balance:       budget.Balance,
+		IncomePerTurn: income,
+		Planets:       planets,
+		History:       budget.Entries,
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"sync"
+	"testing"
+)
+
+func TestEconomyService_Budget(t *testing.T) {
+	budgetRepository := dao.NewBudgetRepository()
+	service := NewEconomyService(budgetRepository, dao.NewMapRepository())
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 50}
+
+	// reading the budget does not store it
+	if budget := service.Budget(division, "race-a"); budget.Balance != 50 || budgetRepository.Get("d1", "race-a") != nil {
+		t.Fatalf("expected an unsaved budget of the division resources, got %+v", budget)
+	}
+	if report := service.Report(division, "race-a"); report.Balance != 50 || budgetRepository.Get("d1", "race-a") != nil {
+		t.Fatalf("expected the report not to store the budget, got %+v", report)
+	}
+
+	if err := service.Pay(division, "race-a", 1, 30, "fleet"); err != nil {
+		t.Fatalf("Pay() error = %v", err)
+	}
+	if budget := budgetRepository.Get("d1", "race-a"); budget == nil || budget.Balance != 20 {
+		t.Fatalf("expected the paid budget to be stored, got %+v", budget)
+	}
+	if err := service.Pay(division, "race-a", 1, 30, "fleet"); err == nil {
+		t.Errorf("expected error when the balance is not enough")
+	}
+}
+
+func TestEconomyService_PayConcurrently(t *testing.T) {
+	budgetRepository := dao.NewBudgetRepository()
+	service := NewEconomyService(budgetRepository, dao.NewMapRepository())
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 50}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	paid := 0
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := service.Pay(division, "race-a", 1, 10, "fleet"); err == nil {
+				mutex.Lock()
+				paid++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the balance is never spent twice
+	if budget := budgetRepository.Get("d1", "race-a"); paid != 5 || budget.Balance != 0 || len(budget.Entries) != 6 {
+		t.Errorf("expected 5 payments and an empty balance, got %d payments and %+v", paid, budget)
+	}
+}
@@ -11,8 +11,8 @@ type testLogger struct {
 	t *testing.T
 }
 
-func (tl *testLogger) Printf(format string, v ...interface{}) {
-	tl.t.Logf(format, v...)
+func (tl *testLogger) Info(msg string, args ...any) {
+	tl.t.Log(append([]any{msg}, args...)...)
 }
 
 func TestExecuteBattle(t *testing.T) {
@@ -249,7 +249,7 @@ func TestExecuteBattle(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := &testLogger{t: t}
-			battleHandler := NewBattleHandler(tt.idGenerator, tt.decisionProducer)
+			battleHandler := NewBattleHandler(tt.idGenerator, tt.decisionProducer, DefaultBattleLimits())
 			battle := battleHandler.ExecuteBattle(tt.fleetA, tt.fleetB)
 
 			// Assertion: Battle.SideA must be the same fleet as FleetA
@@ -0,0 +1,245 @@
+package game
+
+import (
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+)
+
+var (
+	ErrFleetBuildNotFound = errors.New("FleetBuild not found")
+	ErrDivisionNotFound   = errors.New("Division not found")
+	ErrFleetNotFound      = errors.New("Fleet not found")
+	// the fleet build of another race can not reinforce the fleet of the race
+	ErrFleetBuildNotOwned = errors.New("FleetBuild belongs to another race")
+)
+
+// ShipModelError is returned when an assigned ship model can not be built.
+type ShipModelError struct {
+	ShipModelID string
+	Err         error
+}
+
+func (e *ShipModelError) Error() string {
+	return "ShipModel " + e.ShipModelID + ": " + e.Err.Error()
+}
+
+func (e *ShipModelError) Unwrap() error {
+	return e.Err
+}
+
+// FleetBuilder builds the fleets of fleet builds, the built ships are paid from the race budget.
+type FleetBuilder struct {
+	fleetBuildRepository *dao.FleetBuildRepository
+	fleetRepository      *dao.FleetRepository
+	shipModelRepository  *dao.ShipModelRepository
+	divisionRepository   *dao.DivisionRepository
+	mapRepository        *dao.MapRepository
+	turnRepository       *dao.TurnRepository
+	economyService       *EconomyService
+	idGenerator          util.IdGenerator
+}
+
+func NewFleetBuilder(
+	fleetBuildRepository *dao.FleetBuildRepository,
+	fleetRepository *dao.FleetRepository,
+	shipModelRepository *dao.ShipModelRepository,
+	divisionRepository *dao.DivisionRepository,
+	mapRepository *dao.MapRepository,
+	turnRepository *dao.TurnRepository,
+	economyService *EconomyService,
+	idGenerator util.IdGenerator,
+) *FleetBuilder {
+	return &FleetBuilder{
+		fleetBuildRepository: fleetBuildRepository,
+		fleetRepository:      fleetRepository,
+		shipModelRepository:  shipModelRepository,
+		divisionRepository:   divisionRepository,
+		mapRepository:        mapRepository,
+		turnRepository:       turnRepository,
+		economyService:       economyService,
+		idGenerator:          idGenerator,
+	}
+}
+
+// Prepare loads a copy of the fleet build with the rules of its division and its assigned ship models.
+func (b *FleetBuilder) Prepare(fleetBuildId string) (*galaxy.FleetBuild, *galaxy.Division, error) {
+	fleetBuild := b.fleetBuildRepository.Get(fleetBuildId)
+	if fleetBuild == nil {
+		return nil, nil, ErrFleetBuildNotFound
+	}
+
+	division := b.divisionRepository.Get(fleetBuild.DivisionId)
+	if division == nil {
+		return nil, nil, ErrDivisionNotFound
+	}
+	fleetBuild.ApplyDivision(division)
+
+	assignments := b.fleetBuildRepository.FindAssignedShipModels(fleetBuildId)
+	fleetBuild.AssignedShipModels = make([]galaxy.ShipModelAssignment, 0, len(assignments))
+	for _, a := range assignments {
+		shipModel := b.shipModelRepository.GetVersion(a.ShipModelID, a.ShipModelVersion)
+		if shipModel != nil {
+			fleetBuild.AssignedShipModels = append(fleetBuild.AssignedShipModels, galaxy.ShipModelAssignment{
+				ShipModel: *shipModel,
+				Amount:    a.Amount,
+			})
+		}
+	}
+
+	return fleetBuild, division, nil
+}
+
+// buildShips creates the ships of the assigned ship models of the prepared fleet build.
+func (b *FleetBuilder) buildShips(fleetBuild *galaxy.FleetBuild, raceId string) ([]*galaxy.Ship, error) {
+	var ships []*galaxy.Ship
+	for _, assignment := range fleetBuild.AssignedShipModels {
+		shipModel := assignment.ShipModel
+		if err := fleetBuild.ValidateShipModel(&shipModel); err != nil {
+			return nil, &ShipModelError{ShipModelID: shipModel.ID, Err: err}
+		}
+		shipTech := fleetBuild.CalculateShipTech(&shipModel)
+		for i := 0; i < assignment.Amount; i++ {
+			ships = append(ships, &galaxy.Ship{
+				ID:               b.idGenerator.NextId(),
+				Name:             shipModel.Name,
+				Tech:             shipTech,
+				Owner:            raceId,
+				ShipModelID:      shipModel.ID,
+				ShipModelVersion: shipModel.Version,
+			})
+		}
+	}
+
+	return ships, nil
+}
+
+// Build builds the fleet of the fleet build for the race and pays the mass of its ships from the budget
+// of the race. The new fleet replaces the previous fleet of the race in the division.
+func (b *FleetBuilder) Build(fleetBuildId string, raceId string) (*galaxy.Fleet, error) {
+	fleetBuild, division, err := b.Prepare(fleetBuildId)
+	if err != nil {
+		return nil, err
+	}
+	if fleetBuild.RaceId != raceId {
+		return nil, ErrFleetBuildNotOwned
+	}
+
+	ships, err := b.buildShips(fleetBuild, raceId)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.pay(fleetBuild, division, raceId); err != nil {
+		return nil, err
+	}
+
+	fleet := galaxy.NewFleet(ships)
+	fleet.ID = b.idGenerator.NextId()
+	fleet.Owner = raceId
+	fleet.DivisionId = fleetBuild.DivisionId
+	// the rebuilt fleet replaces the previous fleet of the race on the division map
+	if previous := b.fleetRepository.GetDivisionFleet(fleetBuild.DivisionId, raceId); previous != nil {
+		b.fleetRepository.Delete(previous.FleetId)
+	}
+
+	b.fleetRepository.Upsert(fleet)
+	b.fleetRepository.UpsertDivisionFleet(&galaxy.DivisionFleet{
+		DivisionId: fleetBuild.DivisionId,
+		UserId:     raceId,
+		FleetId:    fleet.ID,
+	})
+	b.fleetRepository.AddEvent(&galaxy.FleetEvent{
+		FleetId:     fleet.ID,
+		Type:        galaxy.FLEET_EVENT_BUILT,
+		MapTime:     b.mapRepository.GetTime(fleet.DivisionId),
+		Ships:       len(fleet.Ships),
+		Added:       len(ships),
+		Description: "built from " + fleetBuildId,
+	})
+
+	return fleet, nil
+}
+
+// pay debits the mass of the ships of the prepared fleet build from the budget of the race, in the current turn.
+func (b *FleetBuilder) pay(fleetBuild *galaxy.FleetBuild, division *galaxy.Division, raceId string) error {
+	cost := float64(fleetBuild.CalculateStatistics(0).UsedResourcesForShips)
+
+	return b.economyService.Pay(division, raceId, b.turn(division), cost, "fleet of "+fleetBuild.ID)
+}
+
+// turn returns the number of the current turn of the division, 0 before the first turn.
+func (b *FleetBuilder) turn(division *galaxy.Division) int {
+	if state := b.turnRepository.GetState(division.ID); state != nil {
+		return state.Number
+	}
+
+	return 0
+}
+
+// PayResearch debits the research added by the fleet build from the budget of its race. The research of the
+// previous fleet build of the same race and division is already paid, a fleet build without one pays all
+// its research. Lowering the research is not refunded.
+func (b *FleetBuilder) PayResearch(previous *galaxy.FleetBuild, fleetBuild *galaxy.FleetBuild) error {
+	cost := fleetBuild.ResearchCost()
+	if previous != nil && previous.DivisionId == fleetBuild.DivisionId && previous.RaceId == fleetBuild.RaceId {
+		cost -= previous.ResearchCost()
+	}
+	if cost <= 0 {
+		return nil
+	}
+
+	division := b.divisionRepository.Get(fleetBuild.DivisionId)
+	if division == nil {
+		return ErrDivisionNotFound
+	}
+
+	return b.economyService.Pay(division, fleetBuild.RaceId, b.turn(division), cost, "research of "+fleetBuild.ID)
+}
+
+// Reinforce adds the ships of the fleet build of the race to its existing fleet in the division and pays
+// their mass from the budget of the race. The fleet keeps its position and its surviving ships,
+// it can not be reinforced while moving.
+func (b *FleetBuilder) Reinforce(fleetBuildId string, raceId string) (*galaxy.Fleet, error) {
+	fleetBuild, division, err := b.Prepare(fleetBuildId)
+	if err != nil {
+		return nil, err
+	}
+	if fleetBuild.RaceId != raceId {
+		return nil, ErrFleetBuildNotOwned
+	}
+
+	divisionFleet := b.fleetRepository.GetDivisionFleet(fleetBuild.DivisionId, raceId)
+	if divisionFleet == nil {
+		return nil, ErrFleetNotFound
+	}
+	fleet := b.fleetRepository.Get(divisionFleet.FleetId)
+	if fleet == nil {
+		return nil, ErrFleetNotFound
+	}
+	if fleet.Movement != nil {
+		return nil, errors.New("fleet is moving, it can be reinforced only at a planet")
+	}
+
+	ships, err := b.buildShips(fleetBuild, raceId)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.pay(fleetBuild, division, raceId); err != nil {
+		return nil, err
+	}
+
+	fleet.AddShips(ships)
+	b.fleetRepository.Upsert(fleet)
+	b.fleetRepository.AddEvent(&galaxy.FleetEvent{
+		FleetId:     fleet.ID,
+		Type:        galaxy.FLEET_EVENT_REINFORCED,
+		MapTime:     b.mapRepository.GetTime(fleet.DivisionId),
+		Ships:       len(fleet.Ships),
+		Added:       len(ships),
+		PlanetId:    fleet.Location,
+		Description: "reinforced from " + fleetBuildId,
+	})
+
+	return fleet, nil
+}
@@ -0,0 +1,170 @@
+package game
+
+import (
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"sync"
+	"testing"
+)
+
+func TestFleetBuilder_Build(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: 20})
+
+	shipModelRepository := dao.NewShipModelRepository()
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter", Guns: 1, OneGunMass: 2, DefenseMass: 2, EngineMass: 4})
+
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	fleetBuildRepository.Upsert(&galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a"})
+	fleetBuildRepository.AssignShipModel(&galaxy.FleetBuildToShipModel{FleetBuildID: "fb1", ShipModelID: "sm1", ShipModelVersion: 1, Amount: 2})
+
+	fleetRepository := dao.NewFleetRepository()
+	mapRepository := dao.NewMapRepository()
+	budgetRepository := dao.NewBudgetRepository()
+	builder := NewFleetBuilder(fleetBuildRepository, fleetRepository, shipModelRepository, divisionRepository,
+		mapRepository, dao.NewTurnRepository(), NewEconomyService(budgetRepository, mapRepository), &util.SimpleIdGenerator{})
+
+	if _, err := builder.Build("fb1", "race-b"); !errors.Is(err, ErrFleetBuildNotOwned) {
+		t.Errorf("expected ErrFleetBuildNotOwned for the fleet build of another race, got %v", err)
+	}
+	if budget := budgetRepository.Get("d1", "race-b"); budget != nil {
+		t.Errorf("expected the other race not to pay, got budget %+v", budget)
+	}
+
+	// 2 ships of mass 8 cost 16 of the 20 resources
+	if _, err := builder.Build("fb1", "race-a"); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if budget := budgetRepository.Get("d1", "race-a"); budget == nil || budget.Balance != 4 {
+		t.Fatalf("expected the fleet to be paid, got budget %+v", budget)
+	}
+
+	previous := fleetRepository.GetDivisionFleet("d1", "race-a")
+	if _, err := builder.Build("fb1", "race-a"); !errors.Is(err, galaxy.ErrInsufficientResources) {
+		t.Errorf("expected ErrInsufficientResources, got %v", err)
+	}
+	if divisionFleet := fleetRepository.GetDivisionFleet("d1", "race-a"); divisionFleet.FleetId != previous.FleetId {
+		t.Errorf("expected the unpaid fleet not to replace the previous one")
+	}
+}
+
+func TestFleetBuilder_Reinforce(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: 40})
+
+	shipModelRepository := dao.NewShipModelRepository()
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter", Guns: 1, OneGunMass: 2, DefenseMass: 2, EngineMass: 4})
+
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	fleetBuildRepository.Upsert(&galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a"})
+	fleetBuildRepository.AssignShipModel(&galaxy.FleetBuildToShipModel{FleetBuildID: "fb1", ShipModelID: "sm1", ShipModelVersion: 1, Amount: 2})
+
+	fleetRepository := dao.NewFleetRepository()
+	mapRepository := dao.NewMapRepository()
+	budgetRepository := dao.NewBudgetRepository()
+	builder := NewFleetBuilder(fleetBuildRepository, fleetRepository, shipModelRepository, divisionRepository,
+		mapRepository, dao.NewTurnRepository(), NewEconomyService(budgetRepository, mapRepository), &util.SimpleIdGenerator{})
+
+	if _, err := builder.Reinforce("fb1", "race-a"); !errors.Is(err, ErrFleetNotFound) {
+		t.Fatalf("expected ErrFleetNotFound without a fleet, got %v", err)
+	}
+
+	fleet, err := builder.Build("fb1", "race-a")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if _, err := builder.Reinforce("fb1", "race-b"); !errors.Is(err, ErrFleetBuildNotOwned) {
+		t.Errorf("expected ErrFleetBuildNotOwned for the fleet build of another race, got %v", err)
+	}
+
+	reinforced, err := builder.Reinforce("fb1", "race-a")
+	if err != nil {
+		t.Fatalf("Reinforce() error = %v", err)
+	}
+	if reinforced.ID != fleet.ID || len(reinforced.Ships) != 4 {
+		t.Errorf("expected fleet %s with 4 ships, got %s with %d", fleet.ID, reinforced.ID, len(reinforced.Ships))
+	}
+
+	// the fleet and the reinforcement cost 16 each of the 40 resources
+	if budget := budgetRepository.Get("d1", "race-a"); budget.Balance != 8 {
+		t.Errorf("expected the reinforcement to be paid, got balance %v", budget.Balance)
+	}
+	if _, err := builder.Reinforce("fb1", "race-a"); !errors.Is(err, galaxy.ErrInsufficientResources) {
+		t.Errorf("expected ErrInsufficientResources, got %v", err)
+	}
+
+	history := fleetRepository.GetHistory(fleet.ID)
+	if len(history) != 2 || history[0].Type != galaxy.FLEET_EVENT_BUILT || history[1].Type != galaxy.FLEET_EVENT_REINFORCED || history[1].Added != 2 {
+		t.Errorf("unexpected fleet history %+v", history)
+	}
+
+	reinforced.Movement = &galaxy.FleetMovement{From: "p1", To: "p2"}
+	if _, err := builder.Reinforce("fb1", "race-a"); err == nil {
+		t.Errorf("expected error when reinforcing a moving fleet")
+	}
+}
+
+func TestFleetBuilder_PayResearch(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: 20})
+
+	mapRepository := dao.NewMapRepository()
+	budgetRepository := dao.NewBudgetRepository()
+	builder := NewFleetBuilder(dao.NewFleetBuildRepository(), dao.NewFleetRepository(), dao.NewShipModelRepository(), divisionRepository,
+		mapRepository, dao.NewTurnRepository(), NewEconomyService(budgetRepository, mapRepository), &util.SimpleIdGenerator{})
+
+	previous := &galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a", AttackResources: 10}
+	if err := builder.PayResearch(nil, previous); err != nil {
+		t.Fatalf("PayResearch() error = %v", err)
+	}
+
+	// only the added research is paid, the lowered research is not refunded
+	fleetBuild := &galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a", AttackResources: 5, DefenseResources: 8}
+	if err := builder.PayResearch(previous, fleetBuild); err != nil {
+		t.Fatalf("PayResearch() error = %v", err)
+	}
+	if budget := budgetRepository.Get("d1", "race-a"); budget.Balance != 7 {
+		t.Errorf("expected balance 7, got %v", budget.Balance)
+	}
+
+	fleetBuild.AttackResources = 20
+	if err := builder.PayResearch(previous, fleetBuild); !errors.Is(err, galaxy.ErrInsufficientResources) {
+		t.Errorf("expected ErrInsufficientResources, got %v", err)
+	}
+	if budget := budgetRepository.Get("d1", "race-a"); budget.Balance != 7 {
+		t.Errorf("expected the unpaid research to keep the balance, got %v", budget.Balance)
+	}
+}
+
+func TestFleetBuilder_PrepareConcurrently(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: 20})
+
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	fleetBuildRepository.Upsert(&galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a"})
+
+	mapRepository := dao.NewMapRepository()
+	builder := NewFleetBuilder(fleetBuildRepository, dao.NewFleetRepository(), dao.NewShipModelRepository(), divisionRepository,
+		mapRepository, dao.NewTurnRepository(), NewEconomyService(dao.NewBudgetRepository(), mapRepository), &util.SimpleIdGenerator{})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := builder.Prepare("fb1"); err != nil {
+				t.Errorf("Prepare() error = %v", err)
+			}
+			_ = fleetBuildRepository.Get("fb1").CalculateTechnologies()
+		}()
+	}
+	wg.Wait()
+
+	// the division rules are applied to the copies only
+	if fleetBuild := fleetBuildRepository.Get("fb1"); fleetBuild.BaseTechnologies != nil || fleetBuild.AssignedShipModels != nil {
+		t.Errorf("expected the stored fleet build not to change, got %+v", fleetBuild)
+	}
+}
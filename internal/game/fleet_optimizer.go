@@ -0,0 +1,222 @@
+package game
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// FleetOptimizer searches for the fleet builds beating an opponent fleet by hill climbing. A climber starts
+// from every AI archetype, a mutated design replaces the design of the climber when it scores at least as well.
+// All the candidates fight the same seeded battles against the opponent, so their scores are comparable.
+type FleetOptimizer struct {
+	battleLimits BattleLimits
+	idGenerator  util.IdGenerator
+}
+
+func NewFleetOptimizer(battleLimits BattleLimits, idGenerator util.IdGenerator) *FleetOptimizer {
+	return &FleetOptimizer{battleLimits: battleLimits, idGenerator: idGenerator}
+}
+
+func optimizationBattleSeed(seed uint64, battle int) uint64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "optimization/%d/%d", seed, battle)
+
+	// seed 0 would be replaced by a random seed
+	return hash.Sum64() | 1
+}
+
+// Optimize returns the best fleet builds of the race found against the opponent, the best first.
+// The progress is called after every iteration with the number of the finished iterations,
+// the optimization stops with the error of the context when it is cancelled between the iterations.
+func (o *FleetOptimizer) Optimize(
+	ctx context.Context,
+	division *galaxy.Division,
+	raceId string,
+	opponent *galaxy.Fleet,
+	options galaxy.OptimizationOptions,
+	progress func(iteration int),
+) ([]*galaxy.OptimizedFleetBuild, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if err := options.ValidateBudget(division); err != nil {
+		return nil, err
+	}
+	options = options.WithDefaults()
+	if len(opponent.Ships) == 0 {
+		return nil, errors.New("opponent fleet has no ships")
+	}
+
+	budget := cmp.Or(options.Budget, division.ResourcesAmount)
+	rng := gamemath.NewStdRandomGenerator(options.Seed)
+
+	var candidates, climbers []*galaxy.OptimizedFleetBuild
+	for _, archetype := range galaxy.Archetypes {
+		design := *archetype
+		if candidate, err := o.evaluate(division, raceId, &design, budget, opponent, options); err == nil {
+			candidates = append(candidates, candidate)
+			climbers = append(climbers, candidate)
+		}
+	}
+	if len(climbers) == 0 {
+		return nil, fmt.Errorf("budget %d is too small for a fleet", budget)
+	}
+
+	for iteration := 1; iteration <= options.Iterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for i, climber := range climbers {
+			design := mutateDesign(climber.Design, rng, max(budget/galaxy.AI_MIN_SHIP_MASS, 1))
+			candidate, err := o.evaluate(division, raceId, design, budget, opponent, options)
+			if err != nil {
+				continue
+			}
+
+			candidates = append(candidates, candidate)
+			if candidate.Score >= climber.Score {
+				climbers[i] = candidate
+			}
+		}
+
+		if progress != nil {
+			progress(iteration)
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b *galaxy.OptimizedFleetBuild) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.WinRate, a.WinRate))
+	})
+
+	// different designs may end in the same fleet build
+	seen := map[string]bool{}
+	results := []*galaxy.OptimizedFleetBuild{}
+	for _, candidate := range candidates {
+		key := fleetBuildKey(candidate.FleetBuild)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		results = append(results, candidate)
+		if len(results) == options.Results {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+func fleetBuildKey(fleetBuild *galaxy.FleetBuild) string {
+	key := fmt.Sprintf("%g/%g/%g/%g", fleetBuild.AttackResources, fleetBuild.DefenseResources, fleetBuild.EngineResources, fleetBuild.CargoResources)
+	for _, assignment := range fleetBuild.AssignedShipModels {
+		key += fmt.Sprintf("/%d*%v", assignment.Amount, assignment.ShipModel.Modules)
+	}
+
+	return key
+}
+
+// evaluate generates the fleet build of the design and fights the seeded battles of the options against the opponent.
+func (o *FleetOptimizer) evaluate(
+	division *galaxy.Division,
+	raceId string,
+	design *galaxy.Archetype,
+	budget int,
+	opponent *galaxy.Fleet,
+	options galaxy.OptimizationOptions,
+) (*galaxy.OptimizedFleetBuild, error) {
+	fleetBuild, err := galaxy.GenerateFleetBuild(division, design, budget, o.idGenerator)
+	if err != nil {
+		return nil, err
+	}
+	fleetBuild.RaceId = raceId
+	for i := range fleetBuild.AssignedShipModels {
+		fleetBuild.AssignedShipModels[i].ShipModel.OwnerId = raceId
+	}
+	fleet := GenerateFleet(fleetBuild, o.idGenerator)
+
+	result := &galaxy.OptimizedFleetBuild{Design: design, FleetBuild: fleetBuild}
+	for i := 0; i < options.Battles; i++ {
+		rng := gamemath.NewStdRandomGenerator(optimizationBattleSeed(options.Seed, i))
+		battle := executeBattle(o.battleLimits, o.idGenerator, rng, fleet.Snapshot(), opponent.Snapshot())
+
+		score := galaxy.BattleScore(battle)
+		switch score {
+		case galaxy.SCORE_WIN:
+			result.Wins++
+		case galaxy.SCORE_LOSS:
+			result.Losses++
+		default:
+			result.Draws++
+		}
+		result.Score += score
+	}
+	result.Score /= float64(options.Battles)
+	result.WinRate = float64(result.Wins) / float64(options.Battles)
+
+	return result, nil
+}
+
+// mutateDesign returns a copy of the design with one of its parts changed: the ship mass split,
+// the research split, the research share of the budget or the planned number of ships.
+func mutateDesign(design *galaxy.Archetype, rng gamemath.RandomGenerator, maxShips int) *galaxy.Archetype {
+	mutated := *design
+	jitter := func(value float64, step float64) float64 {
+		return value + (rng.NextRandom()-0.5)*2*step
+	}
+
+	switch int(rng.NextRandom() * 4) {
+	case 0:
+		shares := normalizeShares([]float64{
+			max(jitter(mutated.WeaponShare, 0.2), 0.05),
+			max(jitter(mutated.DefenseShare, 0.2), 0),
+			max(jitter(mutated.EngineShare, 0.2), 0.05),
+		})
+		mutated.WeaponShare, mutated.DefenseShare, mutated.EngineShare = shares[0], shares[1], shares[2]
+	case 1:
+		shares := normalizeShares([]float64{
+			max(jitter(mutated.AttackResearch, 0.2), 0),
+			max(jitter(mutated.DefenseResearch, 0.2), 0),
+			max(jitter(mutated.EngineResearch, 0.2), 0),
+			max(jitter(mutated.CargoResearch, 0.1), 0),
+		})
+		mutated.AttackResearch, mutated.DefenseResearch, mutated.EngineResearch, mutated.CargoResearch = shares[0], shares[1], shares[2], shares[3]
+	case 2:
+		mutated.ResearchShare = min(max(jitter(mutated.ResearchShare, 0.15), 0), 0.8)
+	default:
+		ships := int(math.Round(float64(mutated.Ships) * (0.5 + rng.NextRandom())))
+		if ships == mutated.Ships {
+			ships++
+		}
+		mutated.Ships = min(max(ships, 1), maxShips)
+	}
+
+	return &mutated
+}
+
+// normalizeShares scales the shares to the sum of 1, equal shares are returned when all are 0.
+func normalizeShares(shares []float64) []float64 {
+	total := 0.0
+	for _, share := range shares {
+		total += share
+	}
+
+	for i := range shares {
+		if total == 0 {
+			shares[i] = 1 / float64(len(shares))
+		} else {
+			shares[i] /= total
+		}
+	}
+
+	return shares
+}
@@ -0,0 +1,218 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newOptimizerTestOpponent(t *testing.T, division *galaxy.Division) *galaxy.Fleet {
+	idGenerator := &util.SimpleIdGenerator{CurrentId: 1000}
+	fleetBuild, err := galaxy.GenerateFleetBuild(division, galaxy.GetArchetype(galaxy.ARCHETYPE_TANK), 0, idGenerator)
+	if err != nil {
+		t.Fatalf("GenerateFleetBuild() error = %v", err)
+	}
+	fleetBuild.RaceId = "race-b"
+
+	return GenerateFleet(fleetBuild, idGenerator)
+}
+
+func TestFleetOptimizer_Optimize(t *testing.T) {
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 200}
+	opponent := newOptimizerTestOpponent(t, division)
+	options := galaxy.OptimizationOptions{Iterations: 5, Battles: 4, Results: 3, Seed: 7}
+
+	optimizer := NewFleetOptimizer(DefaultBattleLimits(), &util.SimpleIdGenerator{})
+	iterations := 0
+	results, err := optimizer.Optimize(context.Background(), division, "race-a", opponent, options, func(iteration int) { iterations = iteration })
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if iterations != options.Iterations {
+		t.Errorf("expected progress of %d iterations, got %d", options.Iterations, iterations)
+	}
+	if len(results) != options.Results {
+		t.Fatalf("expected %d results, got %d", options.Results, len(results))
+	}
+
+	for i, result := range results {
+		if i > 0 && result.Score > results[i-1].Score {
+			t.Errorf("expected the best results first, got %g after %g", result.Score, results[i-1].Score)
+		}
+		if result.Wins+result.Draws+result.Losses != options.Battles {
+			t.Errorf("expected %d battles, got %+v", options.Battles, result)
+		}
+		if statistics := result.FleetBuild.CalculateStatistics(division.ResourcesAmount); statistics.ExceedingResources != 0 {
+			t.Errorf("fleet build exceeds the budget: %+v", statistics)
+		}
+		if result.FleetBuild.RaceId != "race-a" || result.FleetBuild.AssignedShipModels[0].ShipModel.OwnerId != "race-a" {
+			t.Errorf("expected the fleet build of the race, got %+v", result.FleetBuild)
+		}
+	}
+
+	// the climbers start from the archetypes and keep a design only when it scores at least as well
+	for _, archetype := range galaxy.Archetypes {
+		design := *archetype
+		start, err := optimizer.evaluate(division, "race-a", &design, division.ResourcesAmount, opponent, options.WithDefaults())
+		if err == nil && start.Score > results[0].Score {
+			t.Errorf("expected the best result to score at least as the %s archetype: %g, %g", archetype.Name, results[0].Score, start.Score)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := optimizer.Optimize(cancelled, division, "race-a", opponent, options, nil); err != context.Canceled {
+		t.Errorf("expected the cancelled optimization to stop, got %v", err)
+	}
+
+	// the same seed finds the same fleet builds
+	again, _ := NewFleetOptimizer(DefaultBattleLimits(), &util.SimpleIdGenerator{}).Optimize(context.Background(), division, "race-a", opponent, options, nil)
+	for i := range results {
+		if !reflect.DeepEqual(results[i].Design, again[i].Design) || results[i].Score != again[i].Score {
+			t.Errorf("expected the same result %d, got %+v and %+v", i, results[i], again[i])
+		}
+	}
+}
+
+func TestFleetOptimizer_OptimizeErrors(t *testing.T) {
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 200}
+	opponent := newOptimizerTestOpponent(t, division)
+
+	tests := []struct {
+		name     string
+		opponent *galaxy.Fleet
+		options  galaxy.OptimizationOptions
+	}{
+		{name: "opponent without ships", opponent: galaxy.NewFleet([]*galaxy.Ship{}), options: galaxy.OptimizationOptions{Iterations: 1}},
+		{name: "budget too small", opponent: opponent, options: galaxy.OptimizationOptions{Budget: 2, Iterations: 1}},
+		{name: "negative battles", opponent: opponent, options: galaxy.OptimizationOptions{Battles: -1}},
+		{name: "too many iterations", opponent: opponent, options: galaxy.OptimizationOptions{Iterations: galaxy.OPTIMIZATION_MAX_ITERATIONS + 1}},
+		{name: "budget above the division resources", opponent: opponent, options: galaxy.OptimizationOptions{Budget: 201, Iterations: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFleetOptimizer(DefaultBattleLimits(), &util.SimpleIdGenerator{}).Optimize(context.Background(), division, "race-a", tt.opponent, tt.options, nil); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestOptimizationService_Start(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 200}
+	divisionRepository.Upsert(division)
+
+	fleetRepository := dao.NewFleetRepository()
+	opponent := newOptimizerTestOpponent(t, division)
+	opponent.ID = "opponent"
+	opponent.DivisionId = "d1"
+	fleetRepository.Upsert(opponent)
+
+	service := NewOptimizationService(dao.NewOptimizationRepository(), divisionRepository, fleetRepository,
+		NewFleetOptimizer(DefaultBattleLimits(), &util.UUIDGenerator{}), &util.SimpleIdGenerator{CurrentId: 100})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	if _, err := service.Start("d2", "race-a", "opponent", galaxy.OptimizationOptions{}); err != ErrDivisionNotFound {
+		t.Errorf("expected division not found, got %v", err)
+	}
+	if _, err := service.Start("d1", "race-a", "unknown", galaxy.OptimizationOptions{}); err != ErrFleetNotFound {
+		t.Errorf("expected fleet not found, got %v", err)
+	}
+	var validationError *galaxy.ValidationError
+	if _, err := service.Start("d1", "race-a", "opponent", galaxy.OptimizationOptions{Budget: 1000}); !errors.As(err, &validationError) {
+		t.Errorf("expected a validation error of the budget, got %v", err)
+	}
+
+	job, err := service.Start("d1", "race-a", "opponent", galaxy.OptimizationOptions{Iterations: 2, Battles: 2})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if job.Status != galaxy.OPTIMIZATION_STATUS_RUNNING || job.Options.Results != galaxy.OPTIMIZATION_RESULTS {
+		t.Errorf("unexpected started job %+v", job)
+	}
+
+	service.Wait()
+	job, err = service.Get(job.ID, "race-a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if job.Status != galaxy.OPTIMIZATION_STATUS_FINISHED || job.Iteration != 2 || len(job.Results) == 0 || job.FinishedAt == nil {
+		t.Errorf("unexpected finished job %+v", job)
+	}
+	if _, err := service.Get(job.ID, "race-b"); err != ErrOptimizationNotFound {
+		t.Errorf("expected the job to be hidden from other races, got %v", err)
+	}
+	if jobs := service.FindByRace("race-a"); len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("unexpected jobs of the race %+v", jobs)
+	}
+}
+
+func TestOptimizationService_Shutdown(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 200}
+	divisionRepository.Upsert(division)
+
+	fleetRepository := dao.NewFleetRepository()
+	opponent := newOptimizerTestOpponent(t, division)
+	opponent.ID = "opponent"
+	opponent.DivisionId = "d1"
+	fleetRepository.Upsert(opponent)
+
+	service := NewOptimizationService(dao.NewOptimizationRepository(), divisionRepository, fleetRepository,
+		NewFleetOptimizer(DefaultBattleLimits(), &util.UUIDGenerator{}), &util.SimpleIdGenerator{CurrentId: 100})
+	job, err := service.Start("d1", "race-a", "opponent", galaxy.OptimizationOptions{Iterations: 2, Battles: 2})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := service.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if job, _ = service.Get(job.ID, "race-a"); job.Status != galaxy.OPTIMIZATION_STATUS_FINISHED {
+		t.Errorf("expected the running job to finish before the shutdown ends, got %s", job.Status)
+	}
+	if service.Accepting() {
+		t.Error("expected no new jobs accepted")
+	}
+	if _, err := service.Start("d1", "race-a", "opponent", galaxy.OptimizationOptions{}); err != ErrOptimizationsStopped {
+		t.Errorf("expected the optimizations stopped, got %v", err)
+	}
+}
+
+func TestOptimizationService_ShutdownCancels(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	division := &galaxy.Division{ID: "d1", ResourcesAmount: 200}
+	divisionRepository.Upsert(division)
+
+	fleetRepository := dao.NewFleetRepository()
+	opponent := newOptimizerTestOpponent(t, division)
+	opponent.ID = "opponent"
+	opponent.DivisionId = "d1"
+	fleetRepository.Upsert(opponent)
+
+	service := NewOptimizationService(dao.NewOptimizationRepository(), divisionRepository, fleetRepository,
+		NewFleetOptimizer(DefaultBattleLimits(), &util.UUIDGenerator{}), &util.SimpleIdGenerator{CurrentId: 100})
+	job, err := service.Start("d1", "race-a", "opponent", galaxy.OptimizationOptions{Iterations: galaxy.OPTIMIZATION_MAX_ITERATIONS, Battles: 2})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// the shutdown does not wait, the job is cancelled after its current iteration
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := service.Shutdown(ctx); err != context.Canceled {
+		t.Errorf("expected the shutdown to end with its context, got %v", err)
+	}
+	service.Wait()
+	if job, _ = service.Get(job.ID, "race-a"); job.Status != galaxy.OPTIMIZATION_STATUS_FAILED || job.Error != ErrOptimizationsStopped.Error() {
+		t.Errorf("expected the job to be cancelled, got %+v", job)
+	}
+}
@@ -1 +1,250 @@
 package game
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"slices"
+)
+
+// MapService moves the fleets on the division maps and starts battles when hostile fleets meet.
+// The battles are ranked, the winner of a battle gets its loot.
+type MapService struct {
+	mapRepository      *dao.MapRepository
+	fleetRepository    *dao.FleetRepository
+	battleRepository   *dao.BattleRepository
+	divisionRepository *dao.DivisionRepository
+	turnRepository     *dao.TurnRepository
+	ratingService      *RatingService
+	economyService     *EconomyService
+	battleLimits       BattleLimits
+	idGenerator        util.IdGenerator
+	rng                gamemath.RandomGenerator
+}
+
+func NewMapService(
+	mapRepository *dao.MapRepository,
+	fleetRepository *dao.FleetRepository,
+	battleRepository *dao.BattleRepository,
+	divisionRepository *dao.DivisionRepository,
+	turnRepository *dao.TurnRepository,
+	ratingService *RatingService,
+	economyService *EconomyService,
+	battleLimits BattleLimits,
+	idGenerator util.IdGenerator,
+	rng gamemath.RandomGenerator,
+) *MapService {
+	return &MapService{
+		mapRepository:      mapRepository,
+		fleetRepository:    fleetRepository,
+		battleRepository:   battleRepository,
+		divisionRepository: divisionRepository,
+		turnRepository:     turnRepository,
+		ratingService:      ratingService,
+		economyService:     economyService,
+		battleLimits:       battleLimits,
+		idGenerator:        idGenerator,
+		rng:                rng,
+	}
+}
+
+func (s *MapService) divisionPlanet(divisionId, planetId string) (*galaxy.Planet, error) {
+	planet := s.mapRepository.GetPlanet(planetId)
+	if planet == nil || planet.DivisionId != divisionId {
+		return nil, fmt.Errorf("planet %q is not on the map of division %q", planetId, divisionId)
+	}
+
+	return planet, nil
+}
+
+// DeployFleet positions a fleet which is not on the map yet at a planet of its division.
+// Battles with the hostile fleets at the planet are fought immediately.
+func (s *MapService) DeployFleet(fleet *galaxy.Fleet, planetId string) ([]*galaxy.Battle, error) {
+	if fleet.Location != "" || fleet.Movement != nil {
+		return nil, errors.New("fleet is already on the map")
+	}
+
+	planet, err := s.divisionPlanet(fleet.DivisionId, planetId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.mapRepository.GetTime(fleet.DivisionId)
+	fleet.Location = planet.ID
+	s.fleetRepository.Upsert(fleet)
+	s.fleetRepository.AddEvent(&galaxy.FleetEvent{FleetId: fleet.ID, Type: galaxy.FLEET_EVENT_DEPLOYED, MapTime: now, Ships: len(fleet.Ships), PlanetId: planet.ID})
+
+	return s.fightAt(fleet, now), nil
+}
+
+// MoveFleet orders a fleet standing at a planet to fly to another planet of its division.
+// The travel time is the distance divided by the fleet speed.
+func (s *MapService) MoveFleet(fleet *galaxy.Fleet, planetId string) (*galaxy.FleetMovement, error) {
+	if fleet.Movement != nil {
+		return nil, errors.New("fleet is already moving")
+	}
+	if fleet.Location == "" {
+		return nil, errors.New("fleet is not deployed on the map")
+	}
+
+	from, err := s.divisionPlanet(fleet.DivisionId, fleet.Location)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.divisionPlanet(fleet.DivisionId, planetId)
+	if err != nil {
+		return nil, err
+	}
+	if from.ID == to.ID {
+		return nil, errors.New("fleet is already at the planet")
+	}
+
+	travelTime, err := fleet.TravelTime(from.Distance(to))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.mapRepository.GetTime(fleet.DivisionId)
+	fleet.Movement = &galaxy.FleetMovement{
+		From:          from.ID,
+		To:            to.ID,
+		DepartureTime: now,
+		ArrivalTime:   now + travelTime,
+	}
+	fleet.Location = ""
+	s.fleetRepository.Upsert(fleet)
+	s.fleetRepository.AddEvent(&galaxy.FleetEvent{FleetId: fleet.ID, Type: galaxy.FLEET_EVENT_MOVED, MapTime: now, Ships: len(fleet.Ships), PlanetId: to.ID,
+		Description: "departed from " + from.ID})
+
+	return fleet.Movement, nil
+}
+
+// AdvanceTime moves the map clock of the division forward. The fleets arrive in the order
+// of their arrival times, every arriving fleet fights the hostile fleets at the destination.
+func (s *MapService) AdvanceTime(divisionId string, delta float64) ([]*galaxy.Battle, error) {
+	if delta < 0 {
+		return nil, errors.New("time can not go backwards")
+	}
+
+	now := s.mapRepository.GetTime(divisionId) + delta
+
+	arriving := slices.DeleteFunc(s.fleetRepository.FindByDivision(divisionId), func(fleet *galaxy.Fleet) bool {
+		return fleet.Movement == nil || fleet.Movement.ArrivalTime > now
+	})
+	slices.SortStableFunc(arriving, func(a, b *galaxy.Fleet) int {
+		return cmp.Compare(a.Movement.ArrivalTime, b.Movement.ArrivalTime)
+	})
+
+	battles := []*galaxy.Battle{}
+	for _, fleet := range arriving {
+		arrivalTime := fleet.Movement.ArrivalTime
+		fleet.Location = fleet.Movement.To
+		fleet.Movement = nil
+		s.fleetRepository.Upsert(fleet)
+		s.fleetRepository.AddEvent(&galaxy.FleetEvent{FleetId: fleet.ID, Type: galaxy.FLEET_EVENT_ARRIVED, MapTime: arrivalTime, Ships: len(fleet.Ships), PlanetId: fleet.Location})
+
+		battles = append(battles, s.fightAt(fleet, arrivalTime)...)
+	}
+
+	s.mapRepository.SetTime(divisionId, now)
+
+	return battles, nil
+}
+
+// fightAt executes the battles of the fleet with every hostile fleet standing at its location.
+// The ships destroyed in a battle are removed from the fleets, the survivors fight the next battle.
+func (s *MapService) fightAt(fleet *galaxy.Fleet, time float64) []*galaxy.Battle {
+	battles := []*galaxy.Battle{}
+
+	for _, other := range s.fleetRepository.FindByDivision(fleet.DivisionId) {
+		if len(fleet.Ships) == 0 {
+			break
+		}
+		if other.ID == fleet.ID || other.Location != fleet.Location || !fleet.IsHostile(other) || len(other.Ships) == 0 {
+			continue
+		}
+
+		// the battle keeps snapshots, the fleets are changed by the battle results
+		battle := s.executeBattle(fleet.Snapshot(), other.Snapshot())
+		battle.DivisionId = fleet.DivisionId
+		battle.Location = fleet.Location
+		battle.Time = time
+		s.battleRepository.Upsert(battle)
+		s.ratingService.RecordBattle(battle.DivisionId, battle)
+		s.creditLoot(battle, fleet, other)
+
+		s.applyBattle(fleet, battle.PostSideA, battle, "attacked "+other.ID)
+		s.applyBattle(other, battle.PostSideB, battle, "attacked by "+fleet.ID)
+
+		battles = append(battles, battle)
+	}
+
+	return battles
+}
+
+// creditLoot credits the loot captured by the winner of the battle to the budget of its race, in the current turn.
+// Nobody loots after a draw.
+func (s *MapService) creditLoot(battle *galaxy.Battle, fleetA *galaxy.Fleet, fleetB *galaxy.Fleet) {
+	winner, loot := fleetA, battle.LootA
+	switch galaxy.BattleScore(battle) {
+	case galaxy.SCORE_WIN:
+	case galaxy.SCORE_LOSS:
+		winner, loot = fleetB, battle.LootB
+	default:
+		return
+	}
+
+	division := s.divisionRepository.Get(battle.DivisionId)
+	if loot <= 0 || division == nil {
+		return
+	}
+	turn := 0
+	if state := s.turnRepository.GetState(division.ID); state != nil {
+		turn = state.Number
+	}
+
+	s.economyService.Credit(division, winner.Owner, turn, loot, "loot of battle "+battle.ID)
+}
+
+// applyBattle writes the surviving ships back to the fleet and logs the battle to the fleet history.
+func (s *MapService) applyBattle(fleet *galaxy.Fleet, post *galaxy.Fleet, battle *galaxy.Battle, description string) {
+	lost := fleet.ApplyBattle(post)
+	s.fleetRepository.Upsert(fleet)
+	s.fleetRepository.AddEvent(&galaxy.FleetEvent{
+		FleetId:     fleet.ID,
+		Type:        galaxy.FLEET_EVENT_BATTLE,
+		MapTime:     battle.Time,
+		Ships:       len(fleet.Ships),
+		Lost:        lost,
+		PlanetId:    battle.Location,
+		BattleId:    battle.ID,
+		Description: description,
+	})
+}
+
+func (s *MapService) executeBattle(fleetA *galaxy.Fleet, fleetB *galaxy.Fleet) *galaxy.Battle {
+	return executeBattle(s.battleLimits, s.idGenerator, s.rng, fleetA, fleetB)
+}
+
+// executeBattle fights the battle of the fleets with the runtime decisions drawn from the generator.
+func executeBattle(limits BattleLimits, idGenerator util.IdGenerator, rng gamemath.RandomGenerator, fleetA *galaxy.Fleet, fleetB *galaxy.Fleet) *galaxy.Battle {
+	battleHandler := NewBattleHandler(idGenerator, nil, limits)
+	battleHandler.initializeBattleState(fleetA, fleetB)
+	battleHandler.decisionProducer = NewRuntimeDecisionProducer(rng, battleHandler)
+
+	return battleHandler.ExecuteBattle(fleetA, fleetB)
+}
+
+// executeBattleWithDestruction fights the battle like executeBattle with another destruction chance of the shots.
+func executeBattleWithDestruction(limits BattleLimits, idGenerator util.IdGenerator, rng gamemath.RandomGenerator,
+	destruction *gamemath.ConfigurableFunction, fleetA *galaxy.Fleet, fleetB *galaxy.Fleet) *galaxy.Battle {
+	battleHandler := NewBattleHandler(idGenerator, nil, limits)
+	battleHandler.initializeBattleState(fleetA, fleetB)
+	battleHandler.decisionProducer = newRuntimeDecisionProducer(rng, battleHandler, destruction)
+
+	return battleHandler.ExecuteBattle(fleetA, fleetB)
+}
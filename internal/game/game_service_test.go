@@ -0,0 +1,173 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+)
+
+func newMapTestFleet(id, owner string, speed float64) *galaxy.Fleet {
+	fleet := galaxy.NewFleet([]*galaxy.Ship{
+		{ID: id + "-ship", Owner: owner, Tech: galaxy.ShipTech{Guns: 1, Attack: 1, Defense: 1, Speed: speed, Mass: 1}},
+	})
+	fleet.ID = id
+	fleet.Owner = owner
+	fleet.DivisionId = "d1"
+
+	return fleet
+}
+
+func newTestMapService() (*MapService, *dao.FleetRepository, *dao.MapRepository, *dao.BudgetRepository) {
+	mapRepository := dao.NewMapRepository()
+	mapRepository.UpsertPlanet(&galaxy.Planet{ID: "p1", DivisionId: "d1", X: 0, Y: 0})
+	mapRepository.UpsertPlanet(&galaxy.Planet{ID: "p2", DivisionId: "d1", X: 3, Y: 4})
+	mapRepository.UpsertPlanet(&galaxy.Planet{ID: "other", DivisionId: "d2", X: 1, Y: 1})
+
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1"})
+
+	fleetRepository := dao.NewFleetRepository()
+	budgetRepository := dao.NewBudgetRepository()
+	service := NewMapService(mapRepository, fleetRepository, dao.NewBattleRepository(), divisionRepository, dao.NewTurnRepository(),
+		NewRatingService(dao.NewRatingRepository(), fleetRepository), NewEconomyService(budgetRepository, mapRepository),
+		DefaultBattleLimits(), &util.SimpleIdGenerator{CurrentId: 100}, gamemath.NewStdRandomGenerator(1))
+
+	return service, fleetRepository, mapRepository, budgetRepository
+}
+
+func TestMapService_MoveFleet(t *testing.T) {
+	tests := []struct {
+		name            string
+		speed           float64
+		deployTo        string
+		moveTo          string
+		wantErr         bool
+		wantArrivalTime float64
+	}{
+		{name: "travel time from the fleet speed", speed: 2, deployTo: "p1", moveTo: "p2", wantArrivalTime: 2.5},
+		{name: "planet of another division", speed: 2, deployTo: "p1", moveTo: "other", wantErr: true},
+		{name: "same planet", speed: 2, deployTo: "p1", moveTo: "p1", wantErr: true},
+		{name: "fleet not deployed", speed: 2, moveTo: "p2", wantErr: true},
+		{name: "fleet without engines", speed: 0, deployTo: "p1", moveTo: "p2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := newTestMapService()
+			fleet := newMapTestFleet("f1", "race-a", tt.speed)
+
+			if tt.deployTo != "" {
+				if _, err := service.DeployFleet(fleet, tt.deployTo); err != nil {
+					t.Fatalf("DeployFleet() error = %v", err)
+				}
+			}
+
+			movement, err := service.MoveFleet(fleet, tt.moveTo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MoveFleet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if movement.ArrivalTime != tt.wantArrivalTime || fleet.Location != "" {
+				t.Errorf("unexpected movement %+v, location %q", movement, fleet.Location)
+			}
+		})
+	}
+}
+
+func TestMapService_AdvanceTime(t *testing.T) {
+	service, fleetRepository, mapRepository, _ := newTestMapService()
+
+	attacker := newMapTestFleet("f1", "race-a", 1)
+	defender := newMapTestFleet("f2", "race-b", 1)
+	ally := newMapTestFleet("f3", "race-a", 1)
+	for _, fleet := range []*galaxy.Fleet{attacker, defender, ally} {
+		fleetRepository.Upsert(fleet)
+	}
+
+	if battles, _ := service.DeployFleet(attacker, "p1"); len(battles) != 0 {
+		t.Fatalf("expected no battles on an empty planet, got %d", len(battles))
+	}
+	if battles, _ := service.DeployFleet(ally, "p1"); len(battles) != 0 {
+		t.Fatalf("expected allies not to fight, got %d battles", len(battles))
+	}
+	if _, err := service.DeployFleet(defender, "p2"); err != nil {
+		t.Fatalf("DeployFleet() error = %v", err)
+	}
+
+	if _, err := service.MoveFleet(attacker, "p2"); err != nil {
+		t.Fatalf("MoveFleet() error = %v", err)
+	}
+
+	battles, err := service.AdvanceTime("d1", 4)
+	if err != nil || len(battles) != 0 || attacker.Movement == nil {
+		t.Fatalf("expected fleet still moving, battles %d, error %v", len(battles), err)
+	}
+
+	battles, err = service.AdvanceTime("d1", 2)
+	if err != nil {
+		t.Fatalf("AdvanceTime() error = %v", err)
+	}
+	if len(battles) != 1 {
+		t.Fatalf("expected 1 battle, got %d", len(battles))
+	}
+	battle := battles[0]
+	attacker, defender = fleetRepository.Get(attacker.ID), fleetRepository.Get(defender.ID)
+	if battle.SideA.ID != "f1" || battle.SideB.ID != "f2" || battle.Location != "p2" || battle.Time != 5 || battle.DivisionId != "d1" {
+		t.Errorf("unexpected battle %+v", battle)
+	}
+	if attacker.Location != "p2" || attacker.Movement != nil {
+		t.Errorf("expected fleet to arrive, location %q, movement %+v", attacker.Location, attacker.Movement)
+	}
+	for _, fleet := range []*galaxy.Fleet{attacker, defender} {
+		history := fleetRepository.GetHistory(fleet.ID)
+		event := history[len(history)-1]
+		if event.Type != galaxy.FLEET_EVENT_BATTLE || event.BattleId != battle.ID || event.Ships != len(fleet.Ships) {
+			t.Errorf("unexpected last event of fleet %s: %+v", fleet.ID, event)
+		}
+		if event.Ships+event.Lost != 1 {
+			t.Errorf("expected survivors and losses of fleet %s to match its ship, got %+v", fleet.ID, event)
+		}
+	}
+	if len(battle.SideA.Ships) != 1 || len(battle.SideB.Ships) != 1 {
+		t.Errorf("expected the battle to keep the fleets before the battle, got %+v", battle)
+	}
+	if mapRepository.GetTime("d1") != 6 {
+		t.Errorf("expected map time 6, got %v", mapRepository.GetTime("d1"))
+	}
+
+	if _, err := service.AdvanceTime("d1", -1); err == nil {
+		t.Errorf("expected error for negative time")
+	}
+}
+
+func TestMapService_Loot(t *testing.T) {
+	service, _, _, budgetRepository := newTestMapService()
+
+	// the unarmed defender is destroyed, the winner carries away half of its mass
+	defender := galaxy.NewFleet([]*galaxy.Ship{{ID: "f2-ship", Owner: "race-b", Tech: galaxy.ShipTech{Defense: 1, Speed: 1, Mass: 8}}})
+	defender.ID, defender.Owner, defender.DivisionId = "f2", "race-b", "d1"
+	attacker := galaxy.NewFleet([]*galaxy.Ship{{ID: "f1-ship", Owner: "race-a", Tech: galaxy.ShipTech{Guns: 1, Attack: 100, Defense: 100, Speed: 1, CargoCapacity: 10, Mass: 20}}})
+	attacker.ID, attacker.Owner, attacker.DivisionId = "f1", "race-a", "d1"
+
+	if _, err := service.DeployFleet(defender, "p1"); err != nil {
+		t.Fatalf("DeployFleet() error = %v", err)
+	}
+	battles, err := service.DeployFleet(attacker, "p1")
+	if err != nil || len(battles) != 1 {
+		t.Fatalf("expected 1 battle, got %d, error %v", len(battles), err)
+	}
+
+	if battles[0].LootA != 4 {
+		t.Fatalf("expected loot 4, got %v", battles[0].LootA)
+	}
+	if budget := budgetRepository.Get("d1", "race-a"); budget == nil || budget.Balance != 4 {
+		t.Errorf("expected the loot to be credited to the winner, got %+v", budget)
+	}
+	if budget := budgetRepository.Get("d1", "race-b"); budget != nil {
+		t.Errorf("expected the loser not to get loot, got %+v", budget)
+	}
+}
@@ -0,0 +1,291 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Share of the larger strength two entries may differ by to be paired. The window grows from
+// the initial to the max share until the entry which waits the longest times out.
+const (
+	MATCHMAKING_WINDOW     = 0.1
+	MATCHMAKING_MAX_WINDOW = 0.5
+)
+
+var ErrQueueEntryNotFound = errors.New("Queue entry not found")
+
+// Matchmaker pairs the division fleets waiting in the matchmaking queue and fights their battles.
+// The battles are fought by snapshots of the fleets, the stored fleets are not changed. The battles
+// of two races are ranked, the battles against the AI are not.
+type Matchmaker struct {
+	mutex sync.Mutex
+
+	matchmakingRepository *dao.MatchmakingRepository
+	fleetRepository       *dao.FleetRepository
+	divisionRepository    *dao.DivisionRepository
+	battleRepository      *dao.BattleRepository
+	ratingService         *RatingService
+	opponentGenerator     OpponentGenerator
+	battleLimits          BattleLimits
+	idGenerator           util.IdGenerator
+	// time an entry waits for an opponent
+	timeout time.Duration
+
+	// clock of the queue, replaced in tests
+	now func() time.Time
+}
+
+func NewMatchmaker(
+	matchmakingRepository *dao.MatchmakingRepository,
+	fleetRepository *dao.FleetRepository,
+	divisionRepository *dao.DivisionRepository,
+	battleRepository *dao.BattleRepository,
+	ratingService *RatingService,
+	opponentGenerator OpponentGenerator,
+	battleLimits BattleLimits,
+	idGenerator util.IdGenerator,
+	timeout time.Duration,
+) *Matchmaker {
+	return &Matchmaker{
+		matchmakingRepository: matchmakingRepository,
+		fleetRepository:       fleetRepository,
+		divisionRepository:    divisionRepository,
+		battleRepository:      battleRepository,
+		ratingService:         ratingService,
+		opponentGenerator:     opponentGenerator,
+		battleLimits:          battleLimits,
+		idGenerator:           idGenerator,
+		timeout:               timeout,
+		now:                   time.Now,
+	}
+}
+
+// Enqueue puts the division fleet of the race into the queue. A race waits with one fleet at a time.
+func (m *Matchmaker) Enqueue(divisionId string, raceId string, matchBy string, allowAI bool) (*galaxy.QueueEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.divisionRepository.Get(divisionId) == nil {
+		return nil, ErrDivisionNotFound
+	}
+
+	divisionFleet := m.fleetRepository.GetDivisionFleet(divisionId, raceId)
+	if divisionFleet == nil {
+		return nil, ErrFleetNotFound
+	}
+	fleet := m.fleetRepository.Get(divisionFleet.FleetId)
+	if fleet == nil {
+		return nil, ErrFleetNotFound
+	}
+	if len(fleet.Ships) == 0 {
+		return nil, errors.New("fleet has no ships")
+	}
+
+	for _, entry := range m.matchmakingRepository.FindEntries(raceId) {
+		if entry.Status == galaxy.QUEUE_STATUS_WAITING {
+			return nil, fmt.Errorf("race is already waiting in the queue of division %s", entry.DivisionId)
+		}
+	}
+
+	now := m.now()
+	entry := &galaxy.QueueEntry{
+		ID:         m.idGenerator.NextId(),
+		DivisionId: divisionId,
+		RaceId:     raceId,
+		FleetId:    fleet.ID,
+		MatchBy:    matchBy,
+		Rating:     m.ratingService.Rating(divisionId, raceId).Rating,
+		Resources:  galaxy.FleetResources(fleet),
+		AllowAI:    allowAI,
+		Status:     galaxy.QUEUE_STATUS_WAITING,
+		EnqueuedAt: now,
+		ExpiresAt:  now.Add(m.timeout),
+	}
+	if entry.MatchBy == "" {
+		entry.MatchBy = galaxy.MATCH_BY_RATING
+	}
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.matchmakingRepository.Upsert(entry)
+
+	return entry, nil
+}
+
+// Cancel removes the waiting entry of the race from the queue.
+func (m *Matchmaker) Cancel(entryId string, raceId string) (*galaxy.QueueEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry := m.matchmakingRepository.Get(entryId)
+	if entry == nil || entry.RaceId != raceId {
+		return nil, ErrQueueEntryNotFound
+	}
+	if entry.Status != galaxy.QUEUE_STATUS_WAITING {
+		return nil, fmt.Errorf("entry is %s, only waiting entries can be cancelled", entry.Status)
+	}
+
+	entry.Status = galaxy.QUEUE_STATUS_CANCELLED
+	m.matchmakingRepository.Upsert(entry)
+
+	return entry, nil
+}
+
+// Waiting returns the number of the entries waiting in the queue.
+func (m *Matchmaker) Waiting() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return len(m.matchmakingRepository.FindWaiting())
+}
+
+// window returns the strength difference the entry accepts after waiting until now.
+func (m *Matchmaker) window(entry *galaxy.QueueEntry, now time.Time) float64 {
+	waited := 1.0
+	if m.timeout > 0 {
+		waited = math.Min(1, float64(now.Sub(entry.EnqueuedAt))/float64(m.timeout))
+	}
+
+	return MATCHMAKING_WINDOW + waited*(MATCHMAKING_MAX_WINDOW-MATCHMAKING_WINDOW)
+}
+
+// acceptable tells whether the entries may be paired: the same division and criterion,
+// and the strengths within the window of the entry which waits longer.
+func (m *Matchmaker) acceptable(a, b *galaxy.QueueEntry, now time.Time) bool {
+	if a.DivisionId != b.DivisionId || a.MatchBy != b.MatchBy || a.RaceId == b.RaceId {
+		return false
+	}
+
+	difference := math.Abs(a.Strength() - b.Strength())
+	larger := math.Max(math.Abs(a.Strength()), math.Abs(b.Strength()))
+
+	return difference <= larger*m.window(a, now)
+}
+
+// MatchQueued pairs the waiting entries, the longest waiting first with the closest strength.
+// The entries which waited too long fight the AI when they allow it, otherwise they time out.
+// Returns the entries which left the queue.
+func (m *Matchmaker) MatchQueued() []*galaxy.QueueEntry {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	done := []*galaxy.QueueEntry{}
+
+	waiting := m.matchmakingRepository.FindWaiting()
+	for i, entry := range waiting {
+		if entry.Status != galaxy.QUEUE_STATUS_WAITING {
+			continue
+		}
+
+		var opponent *galaxy.QueueEntry
+		for _, other := range waiting[i+1:] {
+			if other.Status != galaxy.QUEUE_STATUS_WAITING || !m.acceptable(entry, other, now) {
+				continue
+			}
+			if opponent == nil || math.Abs(other.Strength()-entry.Strength()) < math.Abs(opponent.Strength()-entry.Strength()) {
+				opponent = other
+			}
+		}
+
+		switch {
+		case opponent != nil:
+			m.fight(entry, opponent, now)
+			done = append(done, entry, opponent)
+		case now.Before(entry.ExpiresAt):
+			// keeps waiting
+		case entry.AllowAI:
+			m.fightAI(entry, now)
+			done = append(done, entry)
+		default:
+			entry.Status = galaxy.QUEUE_STATUS_TIMED_OUT
+			m.matchmakingRepository.Upsert(entry)
+			m.notify(entry.RaceId, "No opponent was found for your fleet in division "+entry.DivisionId, "", now)
+			done = append(done, entry)
+		}
+	}
+
+	return done
+}
+
+// entryFleet returns a snapshot of the queued fleet, an empty fleet when it was deleted.
+func (m *Matchmaker) entryFleet(entry *galaxy.QueueEntry) *galaxy.Fleet {
+	if fleet := m.fleetRepository.Get(entry.FleetId); fleet != nil {
+		return fleet.Snapshot()
+	}
+
+	fleet := galaxy.NewFleet([]*galaxy.Ship{})
+	fleet.ID = entry.FleetId
+	fleet.Owner = entry.RaceId
+	fleet.DivisionId = entry.DivisionId
+
+	return fleet
+}
+
+// matchSeed makes the battle of the queue entries repeatable, every battle has its own generator.
+func matchSeed(entryIds ...string) uint64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprint(hash, strings.Join(entryIds, "/"))
+
+	// seed 0 would be replaced by a random seed
+	return hash.Sum64() | 1
+}
+
+func (m *Matchmaker) fight(a, b *galaxy.QueueEntry, now time.Time) {
+	rng := gamemath.NewStdRandomGenerator(matchSeed(a.ID, b.ID))
+	battle := executeBattle(m.battleLimits, m.idGenerator, rng, m.entryFleet(a), m.entryFleet(b))
+	m.battleRepository.Upsert(battle)
+	m.ratingService.RecordBattle(a.DivisionId, battle)
+
+	score := galaxy.BattleScore(battle)
+	m.matched(a, b.RaceId, battle.ID, score, now)
+	m.matched(b, a.RaceId, battle.ID, 1-score, now)
+}
+
+func (m *Matchmaker) fightAI(entry *galaxy.QueueEntry, now time.Time) {
+	fleet := m.entryFleet(entry)
+	rng := gamemath.NewStdRandomGenerator(matchSeed(entry.ID))
+	battle := executeBattle(m.battleLimits, m.idGenerator, rng, fleet, m.opponentGenerator.GenerateOpponent(fleet, rng))
+	m.battleRepository.Upsert(battle)
+
+	m.matched(entry, galaxy.AI_RACE_ID, battle.ID, galaxy.BattleScore(battle), now)
+}
+
+// matched stores the result of the entry and notifies its race.
+func (m *Matchmaker) matched(entry *galaxy.QueueEntry, opponentId string, battleId string, score float64, now time.Time) {
+	entry.Status = galaxy.QUEUE_STATUS_MATCHED
+	entry.OpponentId = opponentId
+	entry.BattleId = battleId
+	entry.Score = &score
+	entry.MatchedAt = &now
+	m.matchmakingRepository.Upsert(entry)
+
+	result := "drew"
+	switch score {
+	case galaxy.SCORE_WIN:
+		result = "won"
+	case galaxy.SCORE_LOSS:
+		result = "lost"
+	}
+	m.notify(entry.RaceId, fmt.Sprintf("Your fleet %s %s the battle against %s", entry.FleetId, result, opponentId), battleId, now)
+}
+
+func (m *Matchmaker) notify(raceId string, message string, battleId string, now time.Time) {
+	m.matchmakingRepository.AddNotification(&galaxy.Notification{
+		ID:        m.idGenerator.NextId(),
+		RaceId:    raceId,
+		Message:   message,
+		BattleId:  battleId,
+		CreatedAt: now,
+	})
+}
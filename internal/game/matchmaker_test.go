@@ -0,0 +1,162 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+	"time"
+)
+
+type matchmakerTestSetup struct {
+	matchmaker            *Matchmaker
+	matchmakingRepository *dao.MatchmakingRepository
+	ratingRepository      *dao.RatingRepository
+	now                   time.Time
+}
+
+func newMatchmakerTestSetup(races ...string) *matchmakerTestSetup {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1"})
+
+	fleetRepository := dao.NewFleetRepository()
+	for _, raceId := range races {
+		fleet := newMapTestFleet("fleet-"+raceId, raceId, 1)
+		fleetRepository.Upsert(fleet)
+		fleetRepository.UpsertDivisionFleet(&galaxy.DivisionFleet{DivisionId: "d1", UserId: raceId, FleetId: fleet.ID})
+	}
+
+	matchmakingRepository := dao.NewMatchmakingRepository()
+	ratingRepository := dao.NewRatingRepository()
+	idGenerator := &util.SimpleIdGenerator{CurrentId: 100}
+	matchmaker := NewMatchmaker(matchmakingRepository, fleetRepository, divisionRepository, dao.NewBattleRepository(),
+		NewRatingService(ratingRepository, fleetRepository), &MirrorOpponentGenerator{IdGenerator: idGenerator}, DefaultBattleLimits(), idGenerator, time.Minute)
+
+	setup := &matchmakerTestSetup{
+		matchmaker:            matchmaker,
+		matchmakingRepository: matchmakingRepository,
+		ratingRepository:      ratingRepository,
+		now:                   time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	matchmaker.now = func() time.Time { return setup.now }
+
+	return setup
+}
+
+func TestMatchmaker_Enqueue(t *testing.T) {
+	setup := newMatchmakerTestSetup("race-a")
+
+	entry, err := setup.matchmaker.Enqueue("d1", "race-a", "", false)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if entry.Status != galaxy.QUEUE_STATUS_WAITING || entry.MatchBy != galaxy.MATCH_BY_RATING || entry.Rating != galaxy.RATING_INITIAL ||
+		entry.Resources != 1 || !entry.ExpiresAt.Equal(setup.now.Add(time.Minute)) {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	if _, err := setup.matchmaker.Enqueue("d1", "race-a", "", false); err == nil {
+		t.Errorf("expected error when the race is already waiting")
+	}
+	if _, err := setup.matchmaker.Enqueue("d1", "race-b", "", false); err != ErrFleetNotFound {
+		t.Errorf("expected ErrFleetNotFound, got %v", err)
+	}
+	if _, err := setup.matchmaker.Enqueue("unknown", "race-a", "", false); err != ErrDivisionNotFound {
+		t.Errorf("expected ErrDivisionNotFound, got %v", err)
+	}
+
+	if _, err := setup.matchmaker.Cancel(entry.ID, "race-b"); err != ErrQueueEntryNotFound {
+		t.Errorf("expected ErrQueueEntryNotFound for another race, got %v", err)
+	}
+	if cancelled, err := setup.matchmaker.Cancel(entry.ID, "race-a"); err != nil || cancelled.Status != galaxy.QUEUE_STATUS_CANCELLED {
+		t.Errorf("Cancel() = %+v, error %v", cancelled, err)
+	}
+	if _, err := setup.matchmaker.Enqueue("d1", "race-a", "strength", false); err == nil {
+		t.Errorf("expected error for an unknown criterion")
+	}
+}
+
+func TestMatchmaker_MatchQueued(t *testing.T) {
+	setup := newMatchmakerTestSetup("race-a", "race-b", "race-c")
+	// race-c is far stronger than the others
+	setup.ratingRepository.Upsert(&galaxy.Rating{DivisionId: "d1", RaceId: "race-b", Rating: 1600})
+	setup.ratingRepository.Upsert(&galaxy.Rating{DivisionId: "d1", RaceId: "race-c", Rating: 2400})
+
+	entryA, _ := setup.matchmaker.Enqueue("d1", "race-a", galaxy.MATCH_BY_RATING, false)
+	entryC, _ := setup.matchmaker.Enqueue("d1", "race-c", galaxy.MATCH_BY_RATING, false)
+
+	// 1500 and 2400 are too far apart for the window after half of the timeout
+	setup.now = setup.now.Add(30 * time.Second)
+	if done := setup.matchmaker.MatchQueued(); len(done) != 0 {
+		t.Fatalf("expected no match, got %+v", done)
+	}
+
+	entryB, _ := setup.matchmaker.Enqueue("d1", "race-b", galaxy.MATCH_BY_RATING, false)
+	done := setup.matchmaker.MatchQueued()
+	if len(done) != 2 || entryA.Status != galaxy.QUEUE_STATUS_MATCHED || entryB.Status != galaxy.QUEUE_STATUS_MATCHED {
+		t.Fatalf("expected race-a and race-b to be matched, got %+v", done)
+	}
+	if entryA.OpponentId != "race-b" || entryB.OpponentId != "race-a" || entryA.BattleId == "" || entryA.BattleId != entryB.BattleId ||
+		*entryA.Score+*entryB.Score != 1 {
+		t.Errorf("unexpected match results %+v, %+v", entryA, entryB)
+	}
+	for _, raceId := range []string{"race-a", "race-b"} {
+		notifications := setup.matchmakingRepository.GetNotifications(raceId)
+		if len(notifications) != 1 || notifications[0].BattleId != entryA.BattleId {
+			t.Errorf("expected a notification of %s about the battle, got %+v", raceId, notifications)
+		}
+	}
+	if rating := setup.ratingRepository.Get("d1", "race-a"); rating == nil || rating.Games != 1 {
+		t.Errorf("expected the match to be ranked, got %+v", rating)
+	}
+
+	setup.now = setup.now.Add(time.Minute)
+	if done := setup.matchmaker.MatchQueued(); len(done) != 1 || entryC.Status != galaxy.QUEUE_STATUS_TIMED_OUT {
+		t.Errorf("expected race-c to time out, got %+v", entryC)
+	}
+	if notifications := setup.matchmakingRepository.GetNotifications("race-c"); len(notifications) != 1 {
+		t.Errorf("expected race-c to be notified, got %+v", notifications)
+	}
+}
+
+func TestMatchmaker_MatchQueuedAI(t *testing.T) {
+	setup := newMatchmakerTestSetup("race-a")
+
+	entry, _ := setup.matchmaker.Enqueue("d1", "race-a", galaxy.MATCH_BY_RESOURCES, true)
+	if done := setup.matchmaker.MatchQueued(); len(done) != 0 {
+		t.Fatalf("expected the entry to wait until the timeout, got %+v", done)
+	}
+
+	setup.now = setup.now.Add(time.Minute)
+	if done := setup.matchmaker.MatchQueued(); len(done) != 1 {
+		t.Fatalf("expected the entry to fight the AI, got %+v", done)
+	}
+	if entry.Status != galaxy.QUEUE_STATUS_MATCHED || entry.OpponentId != galaxy.AI_RACE_ID || entry.BattleId == "" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if rating := setup.ratingRepository.Get("d1", "race-a"); rating != nil {
+		t.Errorf("expected the battle against the AI not to be ranked, got %+v", rating)
+	}
+}
+
+func TestAIOpponentGenerator_GenerateOpponent(t *testing.T) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1"})
+	generator := NewAIOpponentGenerator(divisionRepository, &util.SimpleIdGenerator{CurrentId: 100})
+
+	fleet := newMapTestFleet("f1", "race-a", 20)
+	fleet.DivisionId = "d1"
+
+	for seed := uint64(1); seed <= 8; seed++ {
+		opponent := generator.GenerateOpponent(fleet, gamemath.NewStdRandomGenerator(seed))
+		if opponent.Owner != galaxy.AI_RACE_ID || opponent.DivisionId != "d1" || len(opponent.Ships) == 0 {
+			t.Fatalf("unexpected opponent %+v", opponent)
+		}
+		for _, ship := range opponent.Ships {
+			if ship.Owner != galaxy.AI_RACE_ID {
+				t.Errorf("expected the ships of the AI, got %+v", ship)
+			}
+		}
+	}
+}
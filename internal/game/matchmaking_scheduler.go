@@ -0,0 +1,40 @@
+package game
+
+import (
+	"log/slog"
+	"time"
+)
+
+// MatchmakingScheduler periodically pairs the fleets waiting in the matchmaking queue.
+type MatchmakingScheduler struct {
+	matchmaker *Matchmaker
+	worker     *periodicWorker
+}
+
+func NewMatchmakingScheduler(matchmaker *Matchmaker, interval time.Duration) *MatchmakingScheduler {
+	s := &MatchmakingScheduler{matchmaker: matchmaker}
+	s.worker = newPeriodicWorker(interval, s.matchQueued)
+
+	return s
+}
+
+func (s *MatchmakingScheduler) matchQueued() {
+	for _, entry := range s.matchmaker.MatchQueued() {
+		slog.Info("matchmaking entry resolved", "entry_id", entry.ID, "race_id", entry.RaceId, "division_id", entry.DivisionId, "status", entry.Status, "battle_id", entry.BattleId)
+	}
+}
+
+// Start runs the matcher in a goroutine until Stop is called.
+func (s *MatchmakingScheduler) Start() {
+	s.worker.Start()
+}
+
+// Stop stops the matcher and waits until the running matching ends.
+func (s *MatchmakingScheduler) Stop() {
+	s.worker.Stop()
+}
+
+// Running reports whether the matcher goroutine runs, used by the readiness check.
+func (s *MatchmakingScheduler) Running() bool {
+	return s.worker.Running()
+}
@@ -0,0 +1,30 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// The matcher ranks the matches in its goroutine while the handlers read the same repositories,
+// run with -race to check the repositories are synchronized.
+func TestMatchmakingScheduler_ConcurrentReads(t *testing.T) {
+	setup := newMatchmakerTestSetup("race-a", "race-b")
+
+	scheduler := NewMatchmakingScheduler(setup.matchmaker, time.Millisecond)
+	scheduler.Start()
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		// the races are queued again once their previous match is played
+		setup.matchmaker.Enqueue("d1", "race-a", "", false)
+		setup.matchmaker.Enqueue("d1", "race-b", "", false)
+		setup.ratingRepository.FindByDivision("d1")
+		setup.ratingRepository.GetHistory("race-a")
+		setup.matchmakingRepository.FindEntries("race-b")
+		setup.matchmakingRepository.GetNotifications("race-a")
+	}
+	scheduler.Stop()
+
+	if len(setup.ratingRepository.GetHistory("race-a")) == 0 {
+		t.Error("expected the matcher to rank matches")
+	}
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/metrics"
+	"time"
+)
+
+// Outcomes of the executed battles
+const (
+	// one side is destroyed or no side has guns left
+	BATTLE_OUTCOME_FINISHED = "finished"
+	// too many shots in a row destroyed nothing
+	BATTLE_OUTCOME_STALEMATE = "stalemate"
+	// the battle reached the maximal number of shots
+	BATTLE_OUTCOME_SHOT_LIMIT = "shot_limit"
+)
+
+// The battles of the map, the turns, the tournaments and the matchmaking are counted with the battles
+// simulated by the fleet build optimizations.
+var (
+	battlesExecuted = metrics.Default.Counter("galaktika_battles_total",
+		"Battles executed by their outcome, the stalemate rate is the share of the stalemate outcome.", "outcome")
+	battleShots = metrics.Default.Histogram("galaktika_battle_shots",
+		"Shots fired in a battle, the average is the sum divided by the count.", metrics.COUNT_BUCKETS)
+	battleDuration = metrics.Default.Histogram("galaktika_battle_duration_seconds",
+		"Time of executing a battle.", metrics.DURATION_BUCKETS)
+)
+
+func observeBattle(outcome string, shots int, duration time.Duration) {
+	battlesExecuted.Inc(outcome)
+	battleShots.Observe(float64(shots))
+	battleDuration.Observe(duration.Seconds())
+}
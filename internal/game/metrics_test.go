@@ -0,0 +1,52 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+)
+
+// missingDecisionProducer fires shots which never destroy the target.
+type missingDecisionProducer struct{}
+
+func (missingDecisionProducer) ProduceNextShot() *ShotDecision {
+	return &ShotDecision{Side: 0, ShooterId: "a1", TargetId: "b1"}
+}
+
+func TestBattleHandler_ExecuteBattle_Metrics(t *testing.T) {
+	newFleets := func() (*galaxy.Fleet, *galaxy.Fleet) {
+		return galaxy.NewFleet([]*galaxy.Ship{{ID: "a1", Tech: galaxy.ShipTech{Guns: 1, Attack: 1}}}),
+			galaxy.NewFleet([]*galaxy.Ship{{ID: "b1", Tech: galaxy.ShipTech{Guns: 1, Attack: 1}}})
+	}
+
+	tests := []struct {
+		name            string
+		limits          BattleLimits
+		expectedOutcome string
+		expectedShots   int
+	}{
+		{name: "stalemate", limits: BattleLimits{MaxShots: 100, StalemateShots: 10}, expectedOutcome: BATTLE_OUTCOME_STALEMATE, expectedShots: 10},
+		{name: "shot limit", limits: BattleLimits{MaxShots: 5, StalemateShots: 10}, expectedOutcome: BATTLE_OUTCOME_SHOT_LIMIT, expectedShots: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			battles := battlesExecuted.Value(tt.expectedOutcome)
+			shotsSum, count := battleShots.Sum()
+
+			battleHandler := NewBattleHandler(&util.SimpleIdGenerator{}, missingDecisionProducer{}, tt.limits)
+			fleetA, fleetB := newFleets()
+			battle := battleHandler.ExecuteBattle(fleetA, fleetB)
+
+			if len(battle.Shots) != tt.expectedShots {
+				t.Errorf("expected %d shots, got %d", tt.expectedShots, len(battle.Shots))
+			}
+			if outcome := battlesExecuted.Value(tt.expectedOutcome); outcome != battles+1 {
+				t.Errorf("expected the battle counted as %s", tt.expectedOutcome)
+			}
+			if sum, c := battleShots.Sum(); sum != shotsSum+float64(tt.expectedShots) || c != count+1 {
+				t.Errorf("expected %d shots observed, got %g in %d battles", tt.expectedShots, sum-shotsSum, c-count)
+			}
+		})
+	}
+}
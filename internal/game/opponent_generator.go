@@ -0,0 +1,84 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"math"
+)
+
+// OpponentGenerator creates the fleet of the AI for a match when no race is available.
+type OpponentGenerator interface {
+	// GenerateOpponent returns a fleet of the AI race of about the strength of the given fleet.
+	GenerateOpponent(fleet *galaxy.Fleet, rng gamemath.RandomGenerator) *galaxy.Fleet
+}
+
+// MirrorOpponentGenerator opposes a copy of the fleet.
+type MirrorOpponentGenerator struct {
+	IdGenerator util.IdGenerator
+}
+
+func (g *MirrorOpponentGenerator) GenerateOpponent(fleet *galaxy.Fleet, _ gamemath.RandomGenerator) *galaxy.Fleet {
+	ships := make([]*galaxy.Ship, len(fleet.Ships))
+	for i, ship := range fleet.Ships {
+		ships[i] = &galaxy.Ship{
+			ID:    g.IdGenerator.NextId(),
+			Name:  ship.Name,
+			Tech:  ship.Tech,
+			Owner: galaxy.AI_RACE_ID,
+		}
+	}
+
+	opponent := galaxy.NewFleet(ships)
+	opponent.ID = g.IdGenerator.NextId()
+	opponent.Owner = galaxy.AI_RACE_ID
+	opponent.DivisionId = fleet.DivisionId
+
+	return opponent
+}
+
+// AIOpponentGenerator opposes a fleet of a random archetype, generated with the ship resources
+// of the fleet and the research on top. The copy of the fleet is opposed when no fleet can be generated.
+type AIOpponentGenerator struct {
+	divisionRepository *dao.DivisionRepository
+	idGenerator        util.IdGenerator
+}
+
+func NewAIOpponentGenerator(divisionRepository *dao.DivisionRepository, idGenerator util.IdGenerator) *AIOpponentGenerator {
+	return &AIOpponentGenerator{divisionRepository: divisionRepository, idGenerator: idGenerator}
+}
+
+func (g *AIOpponentGenerator) GenerateOpponent(fleet *galaxy.Fleet, rng gamemath.RandomGenerator) *galaxy.Fleet {
+	division := g.divisionRepository.Get(fleet.DivisionId)
+	if division == nil {
+		division = &galaxy.Division{ID: fleet.DivisionId}
+	}
+
+	archetype := galaxy.Archetypes[min(int(rng.NextRandom()*float64(len(galaxy.Archetypes))), len(galaxy.Archetypes)-1)]
+	budget := int(math.Ceil(galaxy.FleetResources(fleet) / (1 - archetype.ResearchShare)))
+
+	fleetBuild, err := galaxy.GenerateFleetBuild(division, archetype, budget, g.idGenerator)
+	if err != nil {
+		return (&MirrorOpponentGenerator{IdGenerator: g.idGenerator}).GenerateOpponent(fleet, rng)
+	}
+
+	return GenerateFleet(fleetBuild, g.idGenerator)
+}
+
+// GenerateFleet builds the ships of a generated fleet build for the race of the fleet build.
+func GenerateFleet(fleetBuild *galaxy.FleetBuild, idGenerator util.IdGenerator) *galaxy.Fleet {
+	technologies := fleetBuild.CalculateTechnologies()
+
+	ships := []*galaxy.Ship{}
+	for _, assignment := range fleetBuild.AssignedShipModels {
+		ships = append(ships, assignment.ShipModel.GenerateShips(technologies, assignment.Amount, idGenerator, fleetBuild.RaceId)...)
+	}
+
+	fleet := galaxy.NewFleet(ships)
+	fleet.ID = idGenerator.NextId()
+	fleet.Owner = fleetBuild.RaceId
+	fleet.DivisionId = fleetBuild.DivisionId
+
+	return fleet
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrOptimizationNotFound = errors.New("Optimization not found")
+var ErrOptimizationsStopped = errors.New("Optimizations are stopped, the server is shutting down")
+
+// OptimizationService runs the fleet build optimizations as background jobs, one job of a race at a time.
+type OptimizationService struct {
+	mutex sync.Mutex
+	// running jobs
+	running sync.WaitGroup
+	active  int
+	// no jobs are started after the shutdown
+	stopped bool
+	// cancels the running jobs when the shutdown can not wait for them
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	optimizationRepository *dao.OptimizationRepository
+	divisionRepository     *dao.DivisionRepository
+	fleetRepository        *dao.FleetRepository
+	optimizer              *FleetOptimizer
+	idGenerator            util.IdGenerator
+
+	// clock of the jobs, replaced in tests
+	now func() time.Time
+}
+
+func NewOptimizationService(
+	optimizationRepository *dao.OptimizationRepository,
+	divisionRepository *dao.DivisionRepository,
+	fleetRepository *dao.FleetRepository,
+	optimizer *FleetOptimizer,
+	idGenerator util.IdGenerator,
+) *OptimizationService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &OptimizationService{
+		ctx:                    ctx,
+		cancel:                 cancel,
+		optimizationRepository: optimizationRepository,
+		divisionRepository:     divisionRepository,
+		fleetRepository:        fleetRepository,
+		optimizer:              optimizer,
+		idGenerator:            idGenerator,
+		now:                    time.Now,
+	}
+}
+
+// Start starts the search for the fleet builds of the race beating the opponent fleet of the division.
+func (s *OptimizationService) Start(divisionId string, raceId string, opponentFleetId string, options galaxy.OptimizationOptions) (*galaxy.OptimizationJob, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return nil, ErrOptimizationsStopped
+	}
+	division := s.divisionRepository.Get(divisionId)
+	if division == nil {
+		return nil, ErrDivisionNotFound
+	}
+	opponent := s.fleetRepository.Get(opponentFleetId)
+	if opponent == nil || opponent.DivisionId != divisionId {
+		return nil, ErrFleetNotFound
+	}
+	if len(opponent.Ships) == 0 {
+		return nil, errors.New("opponent fleet has no ships")
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if err := options.ValidateBudget(division); err != nil {
+		return nil, err
+	}
+
+	for _, job := range s.optimizationRepository.FindByRace(raceId) {
+		if job.Status == galaxy.OPTIMIZATION_STATUS_RUNNING {
+			return nil, errors.New("an optimization of the race is already running")
+		}
+	}
+
+	job := &galaxy.OptimizationJob{
+		ID:              s.idGenerator.NextId(),
+		DivisionId:      divisionId,
+		RaceId:          raceId,
+		OpponentFleetId: opponentFleetId,
+		Options:         options.WithDefaults(),
+		Status:          galaxy.OPTIMIZATION_STATUS_RUNNING,
+		CreatedAt:       s.now(),
+	}
+	s.optimizationRepository.Upsert(job)
+
+	// the job works with copies, the division and the fleet may change meanwhile
+	divisionCopy := *division
+	s.running.Add(1)
+	s.active++
+	go s.run(job, &divisionCopy, opponent.Snapshot())
+
+	return s.copy(job), nil
+}
+
+// run optimizes in the background, only the progress and the result fields of the job are changed.
+func (s *OptimizationService) run(job *galaxy.OptimizationJob, division *galaxy.Division, opponent *galaxy.Fleet) {
+	defer s.running.Done()
+
+	results, err := s.optimizer.Optimize(s.ctx, division, job.RaceId, opponent, job.Options, func(iteration int) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		job.Iteration = iteration
+	})
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.active--
+	finishedAt := s.now()
+	job.FinishedAt = &finishedAt
+	if errors.Is(err, context.Canceled) {
+		err = ErrOptimizationsStopped
+	}
+	if err != nil {
+		job.Status = galaxy.OPTIMIZATION_STATUS_FAILED
+		job.Error = err.Error()
+		slog.Warn("optimization failed", "optimization_id", job.ID, "race_id", job.RaceId, "error", err)
+		return
+	}
+
+	job.Status = galaxy.OPTIMIZATION_STATUS_FINISHED
+	job.Results = results
+}
+
+// copy returns a copy of the job which is safe to read while the optimization runs.
+func (s *OptimizationService) copy(job *galaxy.OptimizationJob) *galaxy.OptimizationJob {
+	jobCopy := *job
+	return &jobCopy
+}
+
+// Get returns the job of the race.
+func (s *OptimizationService) Get(id string, raceId string) (*galaxy.OptimizationJob, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job := s.optimizationRepository.Get(id)
+	if job == nil || job.RaceId != raceId {
+		return nil, ErrOptimizationNotFound
+	}
+
+	return s.copy(job), nil
+}
+
+// FindByRace returns the jobs of the race, the newest first.
+func (s *OptimizationService) FindByRace(raceId string) []*galaxy.OptimizationJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	jobs := s.optimizationRepository.FindByRace(raceId)
+	for i, job := range jobs {
+		jobs[i] = s.copy(job)
+	}
+
+	return jobs
+}
+
+// Wait waits until the running jobs finish.
+func (s *OptimizationService) Wait() {
+	s.running.Wait()
+}
+
+// Running returns the number of the running jobs.
+func (s *OptimizationService) Running() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.active
+}
+
+// Accepting reports whether new jobs can be started.
+func (s *OptimizationService) Accepting() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return !s.stopped
+}
+
+// Shutdown stops starting new jobs and waits until the running jobs finish. When the context ends first,
+// the running jobs are cancelled and fail once their current iteration ends.
+func (s *OptimizationService) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.stopped = true
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
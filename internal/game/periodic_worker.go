@@ -0,0 +1,58 @@
+package game
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// periodicWorker calls tick in a goroutine every interval until it is stopped.
+type periodicWorker struct {
+	interval time.Duration
+	tick     func()
+
+	stop    chan struct{}
+	done    chan struct{}
+	running atomic.Bool
+}
+
+func newPeriodicWorker(interval time.Duration, tick func()) *periodicWorker {
+	return &periodicWorker{interval: interval, tick: tick}
+}
+
+func (w *periodicWorker) Start() {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	w.running.Store(true)
+	go func() {
+		defer close(w.done)
+		defer w.running.Store(false)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.tick()
+			}
+		}
+	}()
+}
+
+// Stop stops the worker and waits until the running tick ends.
+func (w *periodicWorker) Stop() {
+	if w.stop == nil {
+		return
+	}
+
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+func (w *periodicWorker) Running() bool {
+	return w.running.Load()
+}
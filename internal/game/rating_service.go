@@ -0,0 +1,140 @@
+package game
+
+import (
+	"cmp"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RatingService keeps the Elo ratings of the races, in every division and globally, updated
+// after the ranked battles: the battles on the division maps and the tournament matches.
+type RatingService struct {
+	mutex sync.Mutex
+
+	ratingRepository *dao.RatingRepository
+	fleetRepository  *dao.FleetRepository
+
+	// clock of the rating changes, replaced in tests
+	now func() time.Time
+}
+
+func NewRatingService(ratingRepository *dao.RatingRepository, fleetRepository *dao.FleetRepository) *RatingService {
+	return &RatingService{
+		ratingRepository: ratingRepository,
+		fleetRepository:  fleetRepository,
+		now:              time.Now,
+	}
+}
+
+// Rating returns the rating of the race in the division, the global rating for an empty division.
+// The races which have not fought a ranked battle yet have the initial rating.
+func (s *RatingService) Rating(divisionId, raceId string) *galaxy.Rating {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rating(divisionId, raceId)
+}
+
+func (s *RatingService) rating(divisionId, raceId string) *galaxy.Rating {
+	if rating := s.ratingRepository.Get(divisionId, raceId); rating != nil {
+		return rating
+	}
+
+	return galaxy.NewRating(divisionId, raceId)
+}
+
+// RecordBattle updates the division and the global ratings of the races of the battle.
+// Battles of fleets without owners or of the same race are not ranked.
+func (s *RatingService) RecordBattle(divisionId string, battle *galaxy.Battle) []*galaxy.RatingChange {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	raceA, raceB := battle.SideA.Owner, battle.SideB.Owner
+	if raceA == "" || raceB == "" || raceA == raceB {
+		return nil
+	}
+
+	score := galaxy.BattleScore(battle)
+	now := s.now()
+
+	changes := []*galaxy.RatingChange{}
+	for _, ratingDivision := range []string{divisionId, ""} {
+		ratingA, ratingB := s.rating(ratingDivision, raceA), s.rating(ratingDivision, raceB)
+		opponentA, opponentB := ratingB.Rating, ratingA.Rating
+
+		changes = append(changes,
+			s.apply(ratingA, raceB, opponentA, score, battle.ID, now),
+			s.apply(ratingB, raceA, opponentB, 1-score, battle.ID, now),
+		)
+	}
+
+	return changes
+}
+
+func (s *RatingService) apply(rating *galaxy.Rating, opponentId string, opponent float64, score float64, battleId string, now time.Time) *galaxy.RatingChange {
+	before := rating.Apply(opponent, score, now)
+	s.ratingRepository.Upsert(rating)
+
+	change := &galaxy.RatingChange{
+		RaceId:     rating.RaceId,
+		DivisionId: rating.DivisionId,
+		OpponentId: opponentId,
+		BattleId:   battleId,
+		Score:      score,
+		Before:     before,
+		After:      rating.Rating,
+		Time:       now,
+	}
+	s.ratingRepository.AddChange(change)
+
+	return change
+}
+
+// Leaderboard returns the ranked ratings of the division, the global ratings for an empty division.
+func (s *RatingService) Leaderboard(divisionId string) []*galaxy.LeaderboardEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ratings := s.ratingRepository.FindByDivision(divisionId)
+	leaderboard := make([]*galaxy.LeaderboardEntry, len(ratings))
+	for i, rating := range ratings {
+		leaderboard[i] = &galaxy.LeaderboardEntry{Rank: i + 1, Rating: rating}
+	}
+
+	return leaderboard
+}
+
+// FindOpponents returns the races with a fleet in the division, the closest rating to the race first.
+func (s *RatingService) FindOpponents(divisionId, raceId string) []*galaxy.MatchCandidate {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	own := s.rating(divisionId, raceId).Rating
+	candidates := []*galaxy.MatchCandidate{}
+	for _, divisionFleet := range s.fleetRepository.FindDivisionFleets(divisionId) {
+		fleet := s.fleetRepository.Get(divisionFleet.FleetId)
+		if divisionFleet.UserId == raceId || fleet == nil || len(fleet.Ships) == 0 {
+			continue
+		}
+
+		rating := s.rating(divisionId, divisionFleet.UserId).Rating
+		candidates = append(candidates, &galaxy.MatchCandidate{
+			RaceId:           divisionFleet.UserId,
+			FleetId:          fleet.ID,
+			Rating:           rating,
+			RatingDifference: math.Abs(rating - own),
+			ExpectedScore:    galaxy.ExpectedScore(own, rating),
+		})
+	}
+
+	slices.SortFunc(candidates, func(a, b *galaxy.MatchCandidate) int {
+		return cmp.Or(cmp.Compare(a.RatingDifference, b.RatingDifference), strings.Compare(a.RaceId, b.RaceId))
+	})
+
+	return candidates
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"testing"
+)
+
+func newRatingTestBattle(id, winner, loser string) *galaxy.Battle {
+	sideA := galaxy.NewFleet([]*galaxy.Ship{{ID: winner + "-ship", Owner: winner}})
+	sideA.Owner = winner
+	sideB := galaxy.NewFleet([]*galaxy.Ship{{ID: loser + "-ship", Owner: loser}})
+	sideB.Owner = loser
+
+	postB := sideB.Snapshot()
+	postB.Ships[0].Destroyed = true
+
+	return &galaxy.Battle{ID: id, SideA: sideA, SideB: sideB, PostSideA: sideA.Snapshot(), PostSideB: postB}
+}
+
+func TestRatingService_RecordBattle(t *testing.T) {
+	ratingRepository := dao.NewRatingRepository()
+	service := NewRatingService(ratingRepository, dao.NewFleetRepository())
+
+	changes := service.RecordBattle("d1", newRatingTestBattle("b1", "race-a", "race-b"))
+	if len(changes) != 4 {
+		t.Fatalf("expected the division and the global changes of both races, got %d", len(changes))
+	}
+
+	for _, divisionId := range []string{"d1", ""} {
+		winner, loser := service.Rating(divisionId, "race-a"), service.Rating(divisionId, "race-b")
+		if winner.Rating != 1516 || loser.Rating != 1484 || winner.Wins != 1 || loser.Losses != 1 {
+			t.Errorf("unexpected ratings in division %q: %+v, %+v", divisionId, winner, loser)
+		}
+	}
+
+	// the other division starts from the initial rating, the global rating keeps growing
+	service.RecordBattle("d2", newRatingTestBattle("b2", "race-a", "race-b"))
+	if rating := service.Rating("d2", "race-a"); rating.Rating != 1516 {
+		t.Errorf("expected rating 1516 in d2, got %v", rating.Rating)
+	}
+	if rating := service.Rating("", "race-a"); rating.Rating <= 1516 || rating.Games != 2 {
+		t.Errorf("expected the global rating to grow, got %+v", rating)
+	}
+
+	history := ratingRepository.GetHistory("race-b")
+	if len(history) != 4 || history[0].OpponentId != "race-a" || history[0].Score != galaxy.SCORE_LOSS || history[0].After != 1484 {
+		t.Errorf("unexpected history %+v", history)
+	}
+
+	leaderboard := service.Leaderboard("")
+	if len(leaderboard) != 2 || leaderboard[0].Rank != 1 || leaderboard[0].RaceId != "race-a" {
+		t.Errorf("unexpected leaderboard %+v", leaderboard)
+	}
+
+	if changes := service.RecordBattle("d1", newRatingTestBattle("b3", "race-a", "race-a")); changes != nil {
+		t.Errorf("expected a battle of the same race not to be ranked, got %+v", changes)
+	}
+}
+
+func TestRatingService_FindOpponents(t *testing.T) {
+	fleetRepository := dao.NewFleetRepository()
+	for _, raceId := range []string{"race-a", "race-b", "race-c", "race-d"} {
+		fleet := newMapTestFleet("fleet-"+raceId, raceId, 1)
+		fleetRepository.Upsert(fleet)
+		fleetRepository.UpsertDivisionFleet(&galaxy.DivisionFleet{DivisionId: "d1", UserId: raceId, FleetId: fleet.ID})
+	}
+
+	ratingRepository := dao.NewRatingRepository()
+	ratingRepository.Upsert(&galaxy.Rating{DivisionId: "d1", RaceId: "race-a", Rating: 1600})
+	ratingRepository.Upsert(&galaxy.Rating{DivisionId: "d1", RaceId: "race-b", Rating: 1400})
+	ratingRepository.Upsert(&galaxy.Rating{DivisionId: "d1", RaceId: "race-c", Rating: 1580})
+	service := NewRatingService(ratingRepository, fleetRepository)
+
+	candidates := service.FindOpponents("d1", "race-a")
+
+	// race-d has the initial rating
+	want := []string{"race-c", "race-d", "race-b"}
+	if len(candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %d", len(want), len(candidates))
+	}
+	for i, raceId := range want {
+		if candidates[i].RaceId != raceId || candidates[i].FleetId != "fleet-"+raceId {
+			t.Errorf("candidate %d: got %+v, want %s", i, candidates[i], raceId)
+		}
+	}
+	if candidates[0].RatingDifference != 20 || candidates[0].ExpectedScore <= 0.5 {
+		t.Errorf("unexpected closest candidate %+v", candidates[0])
+	}
+}
@@ -19,19 +19,42 @@ type RuntimeDecisionProducer struct {
 	shotsMade   int
 }
 
+// DestructionCurve gives the chance of a shot to destroy the target by the ratio of the target defense to the attack.
+type DestructionCurve struct {
+	Ratios  []float64 `json:"ratios"`
+	Chances []float64 `json:"chances"`
+}
+
+// DefaultDestructionCurve returns the destruction curve of the game.
+func DefaultDestructionCurve() DestructionCurve {
+	return DestructionCurve{Ratios: []float64{0.25, 1, 4}, Chances: []float64{1, 0.5, 0}}
+}
+
+func (curve DestructionCurve) function() (*gamemath.ConfigurableFunction, error) {
+	return gamemath.NewConfigurableFunction(curve.Ratios, curve.Chances)
+}
+
 // NewRuntimeDecisionProducer creates a new runtime decision producer
 func NewRuntimeDecisionProducer(
 	rng gamemath.RandomGenerator,
 	battleState ReadonlyBattleStateInterface,
 ) *RuntimeDecisionProducer {
 
-	f, err := gamemath.NewConfigurableFunction([]float64{0.25, 1, 4}, []float64{1, 0.5, 0})
+	f, err := DefaultDestructionCurve().function()
 	if err != nil {
 		panic(err)
 	}
+	return newRuntimeDecisionProducer(rng, battleState, f)
+}
+
+func newRuntimeDecisionProducer(
+	rng gamemath.RandomGenerator,
+	battleState ReadonlyBattleStateInterface,
+	destructionFunction *gamemath.ConfigurableFunction,
+) *RuntimeDecisionProducer {
 	return &RuntimeDecisionProducer{
 		randomGenerator:     rng,
-		destructionFunction: f,
+		destructionFunction: destructionFunction,
 		currentSide:         0,
 		battleState:         battleState,
 	}
@@ -61,7 +84,9 @@ func (r *RuntimeDecisionProducer) ProduceNextShot() *ShotDecision {
 	targetIndex := int(math.Floor(r.randomGenerator.NextRandom() * float64(r.battleState.GetAliveShipCount(r.currentSide.Flip()))))
 	r.target = r.battleState.GetShipAt(r.currentSide.Flip(), targetIndex)
 
-	destroyed := r.randomGenerator.NextRandom() < r.destructionFunction.CalculateRatio(r.target.Tech.Defense, r.shooter.Tech.Attack)
+	// sensors of the shooter improve the aim of every gun
+	attack := r.shooter.Tech.GunAttack(r.shotsMade) * (1 + r.shooter.Tech.Sensors)
+	destroyed := r.randomGenerator.NextRandom() < r.destructionFunction.CalculateRatio(r.target.Tech.Defense, attack)
 
 	shotDecision := &ShotDecision{
 		Side:      r.currentSide,
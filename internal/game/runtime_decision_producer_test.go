@@ -411,3 +411,43 @@ func TestProduceNextShotShooterWithOneGun(t *testing.T) {
 		t.Errorf("Shot 2: Expected shotsMade reset to 1, got %d", producer.shotsMade)
 	}
 }
+
+func TestProduceNextShotBatteries(t *testing.T) {
+	// Random values per shot: side, shooter, target, destruction; then target, destruction for the next guns
+	// destruction probability with the curve (0.25 -> 1, 1 -> 0.5, 4 -> 0):
+	// heavy gun: defense 8 / attack 16 = 0.5 -> 0.8333
+	// light gun: defense 8 / attack 4 = 2 -> 0.3333
+	rng := gamemath.NewPredefinedRandomGenerator([]float64{0.3, 0.0, 0.0, 0.5, 0.0, 0.5})
+
+	shooter := galaxy.Ship{
+		ID: "ship-a1",
+		Tech: galaxy.ShipTech{
+			Attack: 8,
+			Guns:   2,
+			Batteries: []galaxy.WeaponBattery{
+				{Guns: 1, Attack: 16},
+				{Guns: 1, Attack: 4},
+			},
+		},
+	}
+	target := createTestShip("ship-b1", 0, 0, 8)
+
+	battleState := MockReadonlyBattleState{
+		AliveShipCount:       []int{1, 1},
+		AliveGunnedShipCount: []int{1, 0},
+		AliveShips:           [][]galaxy.Ship{{shooter}, {target}},
+		AliveGunnedShips:     [][]galaxy.Ship{{shooter}, {}},
+	}
+
+	producer := NewRuntimeDecisionProducer(rng, battleState)
+
+	heavyShot := producer.ProduceNextShot()
+	if heavyShot == nil || !heavyShot.Destroyed {
+		t.Errorf("Expected the heavy gun shot to destroy the target, got %+v", heavyShot)
+	}
+
+	lightShot := producer.ProduceNextShot()
+	if lightShot == nil || lightShot.Destroyed {
+		t.Errorf("Expected the light gun shot to miss the target, got %+v", lightShot)
+	}
+}
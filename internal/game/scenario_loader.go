@@ -0,0 +1,167 @@
+package game
+
+import (
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"os"
+)
+
+// TokenRegistry signs in the races of a scenario.
+type TokenRegistry interface {
+	AddToken(token string, race *galaxy.Race)
+}
+
+// ScenarioLoader stores the data of scenarios, either from a file at the startup or imported by a game master.
+type ScenarioLoader struct {
+	divisionRepository   *dao.DivisionRepository
+	shipModelRepository  *dao.ShipModelRepository
+	fleetBuildRepository *dao.FleetBuildRepository
+	tokenRegistry        TokenRegistry
+	// clears the game data before a resetting scenario
+	reset func()
+}
+
+func NewScenarioLoader(
+	divisionRepository *dao.DivisionRepository,
+	shipModelRepository *dao.ShipModelRepository,
+	fleetBuildRepository *dao.FleetBuildRepository,
+	tokenRegistry TokenRegistry,
+	reset func(),
+) *ScenarioLoader {
+	return &ScenarioLoader{
+		divisionRepository:   divisionRepository,
+		shipModelRepository:  shipModelRepository,
+		fleetBuildRepository: fleetBuildRepository,
+		tokenRegistry:        tokenRegistry,
+		reset:                reset,
+	}
+}
+
+// LoadFile loads the YAML or JSON scenario file.
+func (loader *ScenarioLoader) LoadFile(path string) (*galaxy.ScenarioSummary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario, err := galaxy.ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	summary, err := loader.Load(scenario)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return summary, nil
+}
+
+// Load validates the whole scenario first and stores it only when it is valid,
+// so a broken scenario leaves the game data unchanged.
+func (loader *ScenarioLoader) Load(scenario *galaxy.Scenario) (*galaxy.ScenarioSummary, error) {
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	if violations := loader.checkReferences(scenario); len(violations) > 0 {
+		return nil, &galaxy.ValidationError{Violations: violations}
+	}
+
+	if scenario.Reset && loader.reset != nil {
+		loader.reset()
+	}
+
+	summary := &galaxy.ScenarioSummary{Name: scenario.Name}
+	for _, division := range scenario.Divisions {
+		loader.divisionRepository.Upsert(division)
+		summary.Divisions++
+	}
+	for _, race := range scenario.Races {
+		loader.tokenRegistry.AddToken(race.Token, &galaxy.Race{ID: race.ID, Name: race.Name, Role: race.Role})
+		summary.Races++
+	}
+	for _, shipModel := range scenario.ShipModels {
+		loader.shipModelRepository.Upsert(shipModel)
+		summary.ShipModels++
+	}
+	for _, scenarioFleetBuild := range scenario.FleetBuilds {
+		loader.fleetBuildRepository.Upsert(scenarioFleetBuild.FleetBuild())
+		summary.FleetBuilds++
+
+		for _, assignment := range scenarioFleetBuild.ShipModels {
+			shipModel := loader.shipModelRepository.GetVersion(assignment.ShipModelId, assignment.ShipModelVersion)
+			fleetBuildToShipModel := &galaxy.FleetBuildToShipModel{
+				FleetBuildID:     scenarioFleetBuild.ID,
+				ShipModelID:      shipModel.ID,
+				ShipModelVersion: shipModel.Version,
+				Amount:           assignment.Amount,
+				ShipModel:        shipModel,
+			}
+			fleetBuildToShipModel.ResultMass = fleetBuildToShipModel.CalculateResultMass()
+			loader.fleetBuildRepository.AssignShipModel(fleetBuildToShipModel)
+			summary.Assignments++
+		}
+	}
+
+	return summary, nil
+}
+
+// checkReferences finds the divisions and the ship models used by the fleet builds, either in the scenario
+// or in the stored data unless the scenario resets it, and validates the assigned ship models by the division rules.
+func (loader *ScenarioLoader) checkReferences(scenario *galaxy.Scenario) []galaxy.Violation {
+	divisions := map[string]*galaxy.Division{}
+	for _, division := range scenario.Divisions {
+		divisions[division.ID] = division
+	}
+	shipModels := map[string]*galaxy.ShipModel{}
+	for _, shipModel := range scenario.ShipModels {
+		migrated := *shipModel
+		migrated.MigrateModules()
+		shipModels[shipModel.ID] = &migrated
+	}
+
+	violations := []galaxy.Violation{}
+	for i, scenarioFleetBuild := range scenario.FleetBuilds {
+		field := fmt.Sprintf("fleet_builds[%d]", i)
+
+		division := divisions[scenarioFleetBuild.DivisionId]
+		if division == nil && !scenario.Reset {
+			division = loader.divisionRepository.Get(scenarioFleetBuild.DivisionId)
+		}
+		if division == nil {
+			violations = append(violations, galaxy.Violation{Field: field + ".division_id", Code: galaxy.VIOLATION_UNKNOWN_REFERENCE,
+				Message: "division " + scenarioFleetBuild.DivisionId + " is not found"})
+			continue
+		}
+
+		fleetBuild := scenarioFleetBuild.FleetBuild()
+		fleetBuild.ApplyDivision(division)
+
+		for j, assignment := range scenarioFleetBuild.ShipModels {
+			assignmentField := fmt.Sprintf("%s.ship_models[%d]", field, j)
+
+			shipModel := shipModels[assignment.ShipModelId]
+			switch {
+			case shipModel != nil && assignment.ShipModelVersion != 0:
+				violations = append(violations, galaxy.Violation{Field: assignmentField + ".ship_model_version", Code: galaxy.VIOLATION_INVALID,
+					Message: "ship model " + assignment.ShipModelId + " of the scenario is assigned in its latest version only"})
+				continue
+			case shipModel == nil && !scenario.Reset:
+				shipModel = loader.shipModelRepository.GetVersion(assignment.ShipModelId, assignment.ShipModelVersion)
+			}
+			if shipModel == nil {
+				violations = append(violations, galaxy.Violation{Field: assignmentField + ".ship_model_id", Code: galaxy.VIOLATION_UNKNOWN_REFERENCE,
+					Message: fmt.Sprintf("ship model %s version %d is not found", assignment.ShipModelId, assignment.ShipModelVersion)})
+				continue
+			}
+
+			if err := fleetBuild.ValidateShipModel(shipModel); err != nil {
+				violations = append(violations, galaxy.Violation{Field: assignmentField, Code: galaxy.VIOLATION_INVALID,
+					Message: "ship model " + shipModel.ID + " breaks the rules of division " + division.ID + ": " + err.Error()})
+			}
+		}
+	}
+
+	return violations
+}
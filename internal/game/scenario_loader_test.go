@@ -0,0 +1,172 @@
+package game
+
+import (
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"testing"
+)
+
+type testTokenRegistry map[string]*galaxy.Race
+
+func (registry testTokenRegistry) AddToken(token string, race *galaxy.Race) {
+	registry[token] = race
+}
+
+func newTestScenarioLoader() (*ScenarioLoader, *dao.DivisionRepository, *dao.ShipModelRepository, *dao.FleetBuildRepository, testTokenRegistry) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "stored", ValidationRules: &galaxy.ValidationRules{MaxGuns: 2}})
+	shipModelRepository := dao.NewShipModelRepository()
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "stored-model", OwnerId: "r1", Guns: 1, OneGunMass: 1, EngineMass: 1})
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	tokens := testTokenRegistry{}
+
+	reset := func() {
+		divisionRepository.ResetData()
+		shipModelRepository.ResetData()
+		fleetBuildRepository.ResetData()
+	}
+
+	return NewScenarioLoader(divisionRepository, shipModelRepository, fleetBuildRepository, tokens, reset),
+		divisionRepository, shipModelRepository, fleetBuildRepository, tokens
+}
+
+func TestScenarioLoader_Load(t *testing.T) {
+	loader, divisionRepository, shipModelRepository, fleetBuildRepository, tokens := newTestScenarioLoader()
+
+	summary, err := loader.Load(&galaxy.Scenario{
+		Name:       "test",
+		Divisions:  []*galaxy.Division{{ID: "d1", ResourcesAmount: 100}},
+		Races:      []*galaxy.ScenarioRace{{ID: "r1", Name: "Red", Role: galaxy.ROLE_ADMIN, Token: "t1"}},
+		ShipModels: []*galaxy.ShipModel{{ID: "m1", OwnerId: "r1", Guns: 2, OneGunMass: 1, DefenseMass: 2, EngineMass: 1}},
+		FleetBuilds: []*galaxy.ScenarioFleetBuild{
+			{ID: "b1", DivisionId: "d1", RaceId: "r1", AttackResources: 10, ShipModels: []*galaxy.ScenarioAssignment{{ShipModelId: "m1", Amount: 3}}},
+			{ID: "b2", DivisionId: "stored", RaceId: "r1", ShipModels: []*galaxy.ScenarioAssignment{{ShipModelId: "stored-model", ShipModelVersion: 1, Amount: 1}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	expected := galaxy.ScenarioSummary{Name: "test", Divisions: 1, Races: 1, ShipModels: 1, FleetBuilds: 2, Assignments: 2}
+	if *summary != expected {
+		t.Errorf("expected summary %+v, got %+v", expected, *summary)
+	}
+	if divisionRepository.Get("d1") == nil || divisionRepository.Get("stored") == nil {
+		t.Error("expected the scenario division next to the stored one")
+	}
+	if race := tokens["t1"]; race == nil || race.ID != "r1" || !race.IsAdmin() {
+		t.Errorf("expected the token of race r1, got %+v", race)
+	}
+	if shipModel := shipModelRepository.Get("m1"); shipModel == nil || shipModel.Version != 1 || len(shipModel.Modules) == 0 {
+		t.Errorf("expected the migrated ship model m1, got %+v", shipModel)
+	}
+	if fleetBuild := fleetBuildRepository.Get("b1"); fleetBuild == nil || fleetBuild.AttackResources != 10 {
+		t.Errorf("expected fleet build b1, got %+v", fleetBuild)
+	}
+
+	assignment := fleetBuildRepository.FindAssignedShipModel("b1", "m1")
+	if assignment == nil || assignment.Amount != 3 || assignment.ShipModelVersion != 1 || assignment.ResultMass != 3*shipModelRepository.Get("m1").CalculateTotalMass() {
+		t.Errorf("unexpected assignment %+v", assignment)
+	}
+}
+
+func TestScenarioLoader_Load_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario *galaxy.Scenario
+		expected []string
+	}{
+		{
+			name:     "invalid part",
+			scenario: &galaxy.Scenario{Divisions: []*galaxy.Division{{ID: "d1"}}, Races: []*galaxy.ScenarioRace{{ID: "r1"}}},
+			expected: []string{"races[0].token"},
+		},
+		{
+			name: "unknown references",
+			scenario: &galaxy.Scenario{
+				Divisions: []*galaxy.Division{{ID: "d1"}},
+				FleetBuilds: []*galaxy.ScenarioFleetBuild{
+					{ID: "b1", DivisionId: "unknown", RaceId: "r1"},
+					{ID: "b2", DivisionId: "d1", RaceId: "r1", ShipModels: []*galaxy.ScenarioAssignment{
+						{ShipModelId: "unknown", Amount: 1},
+						{ShipModelId: "stored-model", ShipModelVersion: 2, Amount: 1},
+					}},
+				},
+			},
+			expected: []string{"fleet_builds[0].division_id", "fleet_builds[1].ship_models[0].ship_model_id", "fleet_builds[1].ship_models[1].ship_model_id"},
+		},
+		{
+			name: "stored data is cleared by a reset",
+			scenario: &galaxy.Scenario{
+				Reset:       true,
+				FleetBuilds: []*galaxy.ScenarioFleetBuild{{ID: "b1", DivisionId: "stored", RaceId: "r1"}},
+			},
+			expected: []string{"fleet_builds[0].division_id"},
+		},
+		{
+			name: "broken division rules",
+			scenario: &galaxy.Scenario{
+				ShipModels: []*galaxy.ShipModel{{ID: "m1", OwnerId: "r1", Guns: 3, OneGunMass: 1, EngineMass: 1}},
+				FleetBuilds: []*galaxy.ScenarioFleetBuild{{ID: "b1", DivisionId: "stored", RaceId: "r1",
+					ShipModels: []*galaxy.ScenarioAssignment{{ShipModelId: "m1", Amount: 1}}}},
+			},
+			expected: []string{"fleet_builds[0].ship_models[0]"},
+		},
+		{
+			name: "older version of a scenario ship model",
+			scenario: &galaxy.Scenario{
+				ShipModels: []*galaxy.ShipModel{{ID: "m1", OwnerId: "r1", Guns: 1, OneGunMass: 1, EngineMass: 1}},
+				FleetBuilds: []*galaxy.ScenarioFleetBuild{{ID: "b1", DivisionId: "stored", RaceId: "r1",
+					ShipModels: []*galaxy.ScenarioAssignment{{ShipModelId: "m1", ShipModelVersion: 1, Amount: 1}}}},
+			},
+			expected: []string{"fleet_builds[0].ship_models[0].ship_model_version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader, divisionRepository, shipModelRepository, fleetBuildRepository, tokens := newTestScenarioLoader()
+
+			_, err := loader.Load(tt.scenario)
+			var validationError *galaxy.ValidationError
+			if !errors.As(err, &validationError) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			fields := []string{}
+			for _, violation := range validationError.Violations {
+				fields = append(fields, violation.Field)
+			}
+			if len(fields) != len(tt.expected) {
+				t.Fatalf("expected violations of %v, got %v", tt.expected, validationError.Violations)
+			}
+			for i := range fields {
+				if fields[i] != tt.expected[i] {
+					t.Errorf("expected violations of %v, got %v", tt.expected, validationError.Violations)
+				}
+			}
+
+			// nothing is stored from an invalid scenario
+			if len(divisionRepository.GetAll()) != 1 || len(shipModelRepository.GetAll("")) != 1 ||
+				len(fleetBuildRepository.GetAll("", "")) != 0 || len(tokens) != 0 {
+				t.Error("expected the stored data unchanged")
+			}
+		})
+	}
+}
+
+func TestScenarioLoader_Load_Reset(t *testing.T) {
+	loader, divisionRepository, shipModelRepository, _, _ := newTestScenarioLoader()
+
+	_, err := loader.Load(&galaxy.Scenario{Reset: true, Divisions: []*galaxy.Division{{ID: "d1"}}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if divisions := divisionRepository.GetAll(); len(divisions) != 1 || divisions[0].ID != "d1" {
+		t.Errorf("expected only the scenario division, got %v", divisions)
+	}
+	if shipModelRepository.Get("stored-model") != nil {
+		t.Error("expected the stored ship model cleared")
+	}
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+)
+
+// Shipyard repairs the wrecks of the stored fleets and refits their ships, the work is paid from the race budget.
+type Shipyard struct {
+	fleetRepository     *dao.FleetRepository
+	shipModelRepository *dao.ShipModelRepository
+	divisionRepository  *dao.DivisionRepository
+	mapRepository       *dao.MapRepository
+	turnRepository      *dao.TurnRepository
+	fleetBuilder        *FleetBuilder
+	economyService      *EconomyService
+}
+
+func NewShipyard(
+	fleetRepository *dao.FleetRepository,
+	shipModelRepository *dao.ShipModelRepository,
+	divisionRepository *dao.DivisionRepository,
+	mapRepository *dao.MapRepository,
+	turnRepository *dao.TurnRepository,
+	fleetBuilder *FleetBuilder,
+	economyService *EconomyService,
+) *Shipyard {
+	return &Shipyard{
+		fleetRepository:     fleetRepository,
+		shipModelRepository: shipModelRepository,
+		divisionRepository:  divisionRepository,
+		mapRepository:       mapRepository,
+		turnRepository:      turnRepository,
+		fleetBuilder:        fleetBuilder,
+		economyService:      economyService,
+	}
+}
+
+// fleet returns the fleet of the race with its division.
+func (s *Shipyard) fleet(fleetId string, raceId string) (*galaxy.Fleet, *galaxy.Division, error) {
+	fleet := s.fleetRepository.Get(fleetId)
+	if fleet == nil || fleet.Owner != raceId {
+		return nil, nil, ErrFleetNotFound
+	}
+
+	division := s.divisionRepository.Get(fleet.DivisionId)
+	if division == nil {
+		return nil, nil, ErrDivisionNotFound
+	}
+
+	return fleet, division, nil
+}
+
+// PreviewRepair returns the cost of the repair of all the wrecks of the fleet.
+func (s *Shipyard) PreviewRepair(fleetId string, raceId string) (*galaxy.FleetWork, error) {
+	fleet, division, err := s.fleet(fleetId, raceId)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := s.economyService.Budget(division, raceId)
+
+	return galaxy.NewFleetWork(fleet, galaxy.FLEET_WORK_REPAIR, fleet.PlanRepair(), budget.Balance), nil
+}
+
+// Repair returns all the wrecks of the fleet to service, the work is paid once the fleet is checked.
+func (s *Shipyard) Repair(fleetId string, raceId string) (*galaxy.FleetWork, error) {
+	work, err := s.PreviewRepair(fleetId, raceId)
+	if err != nil {
+		return nil, err
+	}
+	if len(work.Ships) == 0 {
+		return nil, errors.New("fleet has no wrecks to repair")
+	}
+
+	fleet := s.fleetRepository.Get(fleetId)
+	if err := checkServiced(fleet); err != nil {
+		return nil, err
+	}
+	if err := s.pay(fleet, work, "repair of "+fleet.ID); err != nil {
+		return nil, err
+	}
+
+	repaired := fleet.Repair()
+	s.fleetRepository.Upsert(fleet)
+	s.addEvent(fleet, galaxy.FLEET_EVENT_REPAIRED, repaired, fmt.Sprintf("%d wrecks repaired", repaired))
+
+	return work, nil
+}
+
+// PreviewRefit returns the refit of the fleet ships to the latest versions of their ship models
+// and to the technologies researched by the fleet build.
+func (s *Shipyard) PreviewRefit(fleetId string, raceId string, fleetBuildId string) (*galaxy.FleetWork, error) {
+	fleet, division, err := s.fleet(fleetId, raceId)
+	if err != nil {
+		return nil, err
+	}
+
+	fleetBuild, _, err := s.fleetBuilder.Prepare(fleetBuildId)
+	if err != nil {
+		return nil, err
+	}
+	if fleetBuild.RaceId != raceId || fleetBuild.DivisionId != fleet.DivisionId {
+		return nil, ErrFleetBuildNotFound
+	}
+
+	var shipModelErr error
+	works := fleet.PlanRefit(
+		func(ship *galaxy.Ship) *galaxy.ShipModel {
+			// the ships of the deleted ship models keep their version
+			shipModel := s.shipModelRepository.Get(ship.ShipModelID)
+			if shipModel == nil {
+				shipModel = s.shipModelRepository.GetVersion(ship.ShipModelID, ship.ShipModelVersion)
+			}
+			if shipModel != nil && shipModelErr == nil {
+				if err := fleetBuild.ValidateShipModel(shipModel); err != nil {
+					shipModelErr = &ShipModelError{ShipModelID: shipModel.ID, Err: err}
+				}
+			}
+			return shipModel
+		},
+		fleetBuild.CalculateShipTech,
+	)
+	if shipModelErr != nil {
+		return nil, shipModelErr
+	}
+
+	budget := s.economyService.Budget(division, raceId)
+
+	return galaxy.NewFleetWork(fleet, galaxy.FLEET_WORK_REFIT, works, budget.Balance), nil
+}
+
+// Refit upgrades the fleet ships as shown by the preview. The refit is checked before it is paid,
+// a refit which can not be applied costs nothing.
+func (s *Shipyard) Refit(fleetId string, raceId string, fleetBuildId string) (*galaxy.FleetWork, error) {
+	work, err := s.PreviewRefit(fleetId, raceId, fleetBuildId)
+	if err != nil {
+		return nil, err
+	}
+	if len(work.Ships) == 0 {
+		return nil, errors.New("fleet ships are up to date")
+	}
+
+	fleet := s.fleetRepository.Get(fleetId)
+	if err := checkServiced(fleet); err != nil {
+		return nil, err
+	}
+	if err := fleet.CheckRefit(work.Ships); err != nil {
+		return nil, err
+	}
+	if err := s.pay(fleet, work, "refit of "+fleet.ID); err != nil {
+		return nil, err
+	}
+
+	if err := fleet.Refit(work.Ships); err != nil {
+		return nil, err
+	}
+	s.fleetRepository.Upsert(fleet)
+	s.addEvent(fleet, galaxy.FLEET_EVENT_REFITTED, 0, fmt.Sprintf("%d ships refitted with %s", len(work.Ships), fleetBuildId))
+
+	return work, nil
+}
+
+// checkServiced checks the fleet can be serviced, it must stand at a planet.
+func checkServiced(fleet *galaxy.Fleet) error {
+	if fleet.Movement != nil {
+		return errors.New("fleet is moving, it can be serviced only at a planet")
+	}
+
+	return nil
+}
+
+// pay debits the cost of the checked work in the current turn.
+func (s *Shipyard) pay(fleet *galaxy.Fleet, work *galaxy.FleetWork, description string) error {
+	division := s.divisionRepository.Get(fleet.DivisionId)
+	turn := 0
+	if state := s.turnRepository.GetState(fleet.DivisionId); state != nil {
+		turn = state.Number
+	}
+
+	return s.economyService.Pay(division, fleet.Owner, turn, work.TotalCost, description)
+}
+
+func (s *Shipyard) addEvent(fleet *galaxy.Fleet, eventType string, added int, description string) {
+	s.fleetRepository.AddEvent(&galaxy.FleetEvent{
+		FleetId:     fleet.ID,
+		Type:        eventType,
+		MapTime:     s.mapRepository.GetTime(fleet.DivisionId),
+		Ships:       len(fleet.Ships),
+		Added:       added,
+		PlanetId:    fleet.Location,
+		Description: description,
+	})
+}
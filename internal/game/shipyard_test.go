@@ -0,0 +1,163 @@
+package game
+
+import (
+	"errors"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+)
+
+type shipyardTestSetup struct {
+	shipyard            *Shipyard
+	fleetRepository     *dao.FleetRepository
+	shipModelRepository *dao.ShipModelRepository
+	budgetRepository    *dao.BudgetRepository
+	fleet               *galaxy.Fleet
+}
+
+func newShipyardTestSetup(t *testing.T, resources int) *shipyardTestSetup {
+	divisionRepository := dao.NewDivisionRepository()
+	// the fleet of 2 ships of mass 8 is paid first, the resources are left for the work
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: resources + 16})
+
+	shipModelRepository := dao.NewShipModelRepository()
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter", Guns: 1, OneGunMass: 2, DefenseMass: 2, EngineMass: 4})
+
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	fleetBuildRepository.Upsert(&galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a"})
+	fleetBuildRepository.AssignShipModel(&galaxy.FleetBuildToShipModel{FleetBuildID: "fb1", ShipModelID: "sm1", ShipModelVersion: 1, Amount: 2})
+
+	mapRepository := dao.NewMapRepository()
+	fleetRepository := dao.NewFleetRepository()
+	budgetRepository := dao.NewBudgetRepository()
+	turnRepository := dao.NewTurnRepository()
+	economyService := NewEconomyService(budgetRepository, mapRepository)
+	fleetBuilder := NewFleetBuilder(fleetBuildRepository, fleetRepository, shipModelRepository, divisionRepository, mapRepository,
+		turnRepository, economyService, &util.SimpleIdGenerator{})
+	shipyard := NewShipyard(fleetRepository, shipModelRepository, divisionRepository, mapRepository, turnRepository,
+		fleetBuilder, economyService)
+
+	fleet, err := fleetBuilder.Build("fb1", "race-a")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	return &shipyardTestSetup{
+		shipyard:            shipyard,
+		fleetRepository:     fleetRepository,
+		shipModelRepository: shipModelRepository,
+		budgetRepository:    budgetRepository,
+		fleet:               fleet,
+	}
+}
+
+func TestShipyard_Repair(t *testing.T) {
+	tests := []struct {
+		name        string
+		resources   int
+		wantErr     bool
+		wantBalance float64
+	}{
+		// the wreck of mass 8 costs 4 resources
+		{name: "repair paid from the budget", resources: 10, wantBalance: 6},
+		{name: "not enough resources", resources: 3, wantErr: true, wantBalance: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup := newShipyardTestSetup(t, tt.resources)
+			post := setup.fleet.Snapshot()
+			post.Ships[0].Destroyed = true
+			setup.fleet.ApplyBattle(post)
+			setup.fleetRepository.Upsert(setup.fleet)
+
+			preview, err := setup.shipyard.PreviewRepair(setup.fleet.ID, "race-a")
+			if err != nil {
+				t.Fatalf("PreviewRepair() error = %v", err)
+			}
+			if len(preview.Ships) != 1 || preview.TotalCost != 4 || preview.Affordable == tt.wantErr {
+				t.Errorf("unexpected preview %+v", preview)
+			}
+
+			_, err = setup.shipyard.Repair(setup.fleet.ID, "race-a")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Repair() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if balance := setup.budgetRepository.Get("d1", "race-a").Balance; balance != tt.wantBalance {
+				t.Errorf("expected balance %v, got %v", tt.wantBalance, balance)
+			}
+
+			wantShips := 2
+			if tt.wantErr {
+				wantShips = 1
+			}
+			if fleet := setup.fleetRepository.Get(setup.fleet.ID); len(fleet.Ships) != wantShips {
+				t.Errorf("expected %d ships, got %d", wantShips, len(fleet.Ships))
+			}
+		})
+	}
+
+	setup := newShipyardTestSetup(t, 10)
+	if _, err := setup.shipyard.Repair(setup.fleet.ID, "race-b"); !errors.Is(err, ErrFleetNotFound) {
+		t.Errorf("expected ErrFleetNotFound for the fleet of another race, got %v", err)
+	}
+	if _, err := setup.shipyard.Repair(setup.fleet.ID, "race-a"); err == nil {
+		t.Errorf("expected error without wrecks")
+	}
+}
+
+func TestShipyard_Refit(t *testing.T) {
+	setup := newShipyardTestSetup(t, 10)
+
+	if _, err := setup.shipyard.Refit(setup.fleet.ID, "race-a", "fb1"); err == nil {
+		t.Errorf("expected error when the ships are up to date")
+	}
+
+	setup.shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter II", Guns: 1, OneGunMass: 4, DefenseMass: 2, EngineMass: 4})
+
+	work, err := setup.shipyard.Refit(setup.fleet.ID, "race-a", "fb1")
+	if err != nil {
+		t.Fatalf("Refit() error = %v", err)
+	}
+	// 2 ships of mass 8 refitted to mass 10, 2 for the added mass and 2.5 for the refit of each
+	if len(work.Ships) != 2 || work.TotalCost != 9 {
+		t.Errorf("unexpected refit %+v", work)
+	}
+	for _, ship := range setup.fleetRepository.Get(setup.fleet.ID).Ships {
+		if ship.ShipModelVersion != 2 || ship.Tech.Mass != 10 {
+			t.Errorf("unexpected refitted ship %+v", ship)
+		}
+	}
+
+	history := setup.fleetRepository.GetHistory(setup.fleet.ID)
+	if event := history[len(history)-1]; event.Type != galaxy.FLEET_EVENT_REFITTED {
+		t.Errorf("unexpected last event %+v", event)
+	}
+
+	if _, err := setup.shipyard.PreviewRefit(setup.fleet.ID, "race-a", "unknown"); !errors.Is(err, ErrFleetBuildNotFound) {
+		t.Errorf("expected ErrFleetBuildNotFound, got %v", err)
+	}
+}
+
+func TestShipyard_RefitFailed(t *testing.T) {
+	setup := newShipyardTestSetup(t, 10)
+	setup.shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter II", Guns: 1, OneGunMass: 4, DefenseMass: 2, EngineMass: 4})
+
+	fleet := setup.fleetRepository.Get(setup.fleet.ID)
+	fleet.Movement = &galaxy.FleetMovement{From: "p1", To: "p2", ArrivalTime: 5}
+	setup.fleetRepository.Upsert(fleet)
+	balance := setup.budgetRepository.Get("d1", "race-a").Balance
+
+	if _, err := setup.shipyard.Refit(setup.fleet.ID, "race-a", "fb1"); err == nil {
+		t.Fatalf("expected error when the fleet is moving")
+	}
+	if budget := setup.budgetRepository.Get("d1", "race-a"); budget.Balance != balance {
+		t.Errorf("expected the failed refit to keep balance %v, got %v", balance, budget.Balance)
+	}
+	for _, ship := range setup.fleetRepository.Get(setup.fleet.ID).Ships {
+		if ship.ShipModelVersion != 1 {
+			t.Errorf("expected the ships not to be refitted, got %+v", ship)
+		}
+	}
+}
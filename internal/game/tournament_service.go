@@ -0,0 +1,237 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var ErrTournamentNotFound = errors.New("Tournament not found")
+
+// TournamentService runs the tournaments of the races of a division. The matches are fought
+// by snapshots of the division fleets, the stored fleets are not changed. The matches are ranked.
+type TournamentService struct {
+	mutex sync.Mutex
+
+	tournamentRepository *dao.TournamentRepository
+	divisionRepository   *dao.DivisionRepository
+	fleetRepository      *dao.FleetRepository
+	battleRepository     *dao.BattleRepository
+	ratingService        *RatingService
+	battleLimits         BattleLimits
+	idGenerator          util.IdGenerator
+
+	// clock of the tournaments, replaced in tests
+	now func() time.Time
+}
+
+func NewTournamentService(
+	tournamentRepository *dao.TournamentRepository,
+	divisionRepository *dao.DivisionRepository,
+	fleetRepository *dao.FleetRepository,
+	battleRepository *dao.BattleRepository,
+	ratingService *RatingService,
+	battleLimits BattleLimits,
+	idGenerator util.IdGenerator,
+) *TournamentService {
+	return &TournamentService{
+		tournamentRepository: tournamentRepository,
+		divisionRepository:   divisionRepository,
+		fleetRepository:      fleetRepository,
+		battleRepository:     battleRepository,
+		ratingService:        ratingService,
+		battleLimits:         battleLimits,
+		idGenerator:          idGenerator,
+		now:                  time.Now,
+	}
+}
+
+func tournamentSeed(tournamentId string) uint64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "tournament/%s", tournamentId)
+
+	// seed 0 would be replaced by a random seed
+	return hash.Sum64() | 1
+}
+
+// Create collects the fleets of the races in the division and schedules the matches.
+// The seed decides the bracket and the battles, a seed is derived from the ID when it is 0.
+func (s *TournamentService) Create(divisionId string, name string, format string, seed uint64) (*galaxy.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.divisionRepository.Get(divisionId) == nil {
+		return nil, ErrDivisionNotFound
+	}
+
+	tournament := &galaxy.Tournament{
+		ID:           s.idGenerator.NextId(),
+		DivisionId:   divisionId,
+		Name:         name,
+		Format:       format,
+		Seed:         seed,
+		Status:       galaxy.TOURNAMENT_STATUS_SCHEDULED,
+		Participants: []*galaxy.TournamentParticipant{},
+		Standings:    []*galaxy.Standing{},
+		CreatedAt:    s.now(),
+	}
+	if tournament.Seed == 0 {
+		tournament.Seed = tournamentSeed(tournament.ID)
+	}
+
+	for _, divisionFleet := range s.fleetRepository.FindDivisionFleets(divisionId) {
+		fleet := s.fleetRepository.Get(divisionFleet.FleetId)
+		if fleet == nil || len(fleet.Ships) == 0 {
+			continue
+		}
+		tournament.Participants = append(tournament.Participants, &galaxy.TournamentParticipant{
+			RaceId:  divisionFleet.UserId,
+			FleetId: fleet.ID,
+		})
+	}
+
+	if err := tournament.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the seeds are drawn, the races are sorted by their ID before
+	rng := gamemath.NewStdRandomGenerator(tournament.Seed)
+	order := tournament.Participants
+	for i := len(order) - 1; i > 0; i-- {
+		j := int(rng.NextRandom() * float64(i+1))
+		order[i], order[j] = order[j], order[i]
+	}
+	for i, participant := range order {
+		participant.Seed = i + 1
+	}
+
+	if tournament.Format == galaxy.TOURNAMENT_ROUND_ROBIN {
+		tournament.Matches = galaxy.ScheduleRoundRobin(tournament.Participants)
+	} else {
+		tournament.Matches = galaxy.ScheduleBracket(tournament.Participants)
+	}
+	tournament.Standings = galaxy.CalculateStandings(tournament.Participants, tournament.Matches)
+
+	s.tournamentRepository.Upsert(tournament)
+
+	return tournament, nil
+}
+
+// Run plays all the matches of the tournament and publishes the standings.
+func (s *TournamentService) Run(tournamentId string) (*galaxy.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament := s.tournamentRepository.Get(tournamentId)
+	if tournament == nil {
+		return nil, ErrTournamentNotFound
+	}
+	if tournament.Status == galaxy.TOURNAMENT_STATUS_FINISHED {
+		return nil, errors.New("tournament is already finished")
+	}
+
+	// the battles are drawn from a generator of its own, so that the bracket draw does not change them
+	rng := gamemath.NewStdRandomGenerator(tournament.Seed + 1)
+	round := tournament.Matches
+	for len(round) > 0 {
+		for _, match := range round {
+			s.play(tournament, match, rng)
+		}
+
+		if tournament.Format != galaxy.TOURNAMENT_SINGLE_ELIMINATION {
+			break
+		}
+		round = galaxy.NextBracketRound(round)
+		tournament.Matches = append(tournament.Matches, round...)
+	}
+
+	tournament.Standings = galaxy.CalculateStandings(tournament.Participants, tournament.Matches)
+	if tournament.Format == galaxy.TOURNAMENT_SINGLE_ELIMINATION {
+		tournament.WinnerId = tournament.Matches[len(tournament.Matches)-1].WinnerId
+	} else {
+		tournament.WinnerId = tournament.Standings[0].RaceId
+	}
+
+	finishedAt := s.now()
+	tournament.Status = galaxy.TOURNAMENT_STATUS_FINISHED
+	tournament.FinishedAt = &finishedAt
+	s.tournamentRepository.Upsert(tournament)
+
+	return tournament, nil
+}
+
+// participantFleet returns a snapshot of the fleet of the participant, an empty fleet when it was deleted.
+func (s *TournamentService) participantFleet(tournament *galaxy.Tournament, raceId string) *galaxy.Fleet {
+	for _, participant := range tournament.Participants {
+		if participant.RaceId != raceId {
+			continue
+		}
+		if fleet := s.fleetRepository.Get(participant.FleetId); fleet != nil {
+			return fleet.Snapshot()
+		}
+
+		fleet := galaxy.NewFleet([]*galaxy.Ship{})
+		fleet.ID = participant.FleetId
+		fleet.Owner = raceId
+		return fleet
+	}
+
+	return nil
+}
+
+func (s *TournamentService) seed(tournament *galaxy.Tournament, raceId string) int {
+	for _, participant := range tournament.Participants {
+		if participant.RaceId == raceId {
+			return participant.Seed
+		}
+	}
+
+	return 0
+}
+
+// play fights the battle of the match. A match of the round-robin is a draw unless a fleet is destroyed,
+// in the bracket the fleet with more survivors, then the better seed, advances.
+func (s *TournamentService) play(tournament *galaxy.Tournament, match *galaxy.TournamentMatch, rng gamemath.RandomGenerator) {
+	match.Played = true
+	if match.IsBye() {
+		match.WinnerId = match.RaceA
+		match.DecidedBy = galaxy.MATCH_DECIDED_BY_BYE
+		return
+	}
+
+	fleetA := s.participantFleet(tournament, match.RaceA)
+	fleetB := s.participantFleet(tournament, match.RaceB)
+	battle := executeBattle(s.battleLimits, s.idGenerator, rng, fleetA, fleetB)
+	s.battleRepository.Upsert(battle)
+	s.ratingService.RecordBattle(tournament.DivisionId, battle)
+
+	match.BattleId = battle.ID
+	match.ShipsA = len(fleetA.Ships)
+	match.ShipsB = len(fleetB.Ships)
+	match.SurvivorsA, match.SurvivorsB = battle.Survivors()
+
+	switch {
+	case match.SurvivorsA > 0 && match.SurvivorsB == 0:
+		match.WinnerId, match.DecidedBy = match.RaceA, galaxy.MATCH_DECIDED_BY_DESTRUCTION
+	case match.SurvivorsB > 0 && match.SurvivorsA == 0:
+		match.WinnerId, match.DecidedBy = match.RaceB, galaxy.MATCH_DECIDED_BY_DESTRUCTION
+	case tournament.Format != galaxy.TOURNAMENT_SINGLE_ELIMINATION:
+		// draw
+	case match.SurvivorsA != match.SurvivorsB:
+		match.WinnerId, match.DecidedBy = match.RaceA, galaxy.MATCH_DECIDED_BY_SURVIVORS
+		if match.SurvivorsB > match.SurvivorsA {
+			match.WinnerId = match.RaceB
+		}
+	default:
+		match.WinnerId, match.DecidedBy = match.RaceA, galaxy.MATCH_DECIDED_BY_SEED
+		if s.seed(tournament, match.RaceB) < s.seed(tournament, match.RaceA) {
+			match.WinnerId = match.RaceB
+		}
+	}
+}
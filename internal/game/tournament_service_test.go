@@ -0,0 +1,116 @@
+package game
+
+import (
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+)
+
+func newTestTournamentService(races int) (*TournamentService, *dao.FleetRepository) {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1"})
+
+	fleetRepository := dao.NewFleetRepository()
+	for i := 1; i <= races; i++ {
+		raceId := fmt.Sprintf("race-%d", i)
+		fleet := newMapTestFleet(fmt.Sprintf("f%d", i), raceId, 1)
+		fleet.AddShips([]*galaxy.Ship{{ID: fleet.ID + "-ship2", Owner: raceId, Tech: galaxy.ShipTech{Guns: i, Attack: 1, Defense: 1, Speed: 1, Mass: 1}}})
+		fleetRepository.Upsert(fleet)
+		fleetRepository.UpsertDivisionFleet(&galaxy.DivisionFleet{DivisionId: "d1", UserId: raceId, FleetId: fleet.ID})
+	}
+
+	service := NewTournamentService(dao.NewTournamentRepository(), divisionRepository, fleetRepository, dao.NewBattleRepository(),
+		NewRatingService(dao.NewRatingRepository(), fleetRepository), DefaultBattleLimits(), &util.SimpleIdGenerator{CurrentId: 100})
+
+	return service, fleetRepository
+}
+
+func TestTournamentService_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		races       int
+		wantMatches int
+	}{
+		{name: "round robin", format: galaxy.TOURNAMENT_ROUND_ROBIN, races: 4, wantMatches: 6},
+		{name: "bracket with a bye", format: galaxy.TOURNAMENT_SINGLE_ELIMINATION, races: 3, wantMatches: 3},
+		{name: "full bracket", format: galaxy.TOURNAMENT_SINGLE_ELIMINATION, races: 4, wantMatches: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, fleetRepository := newTestTournamentService(tt.races)
+
+			tournament, err := service.Create("d1", "Cup", tt.format, 42)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if len(tournament.Participants) != tt.races {
+				t.Fatalf("expected %d participants, got %d", tt.races, len(tournament.Participants))
+			}
+
+			tournament, err = service.Run(tournament.ID)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if tournament.Status != galaxy.TOURNAMENT_STATUS_FINISHED || len(tournament.Matches) != tt.wantMatches || tournament.WinnerId == "" {
+				t.Errorf("unexpected tournament: status %s, %d matches, winner %q", tournament.Status, len(tournament.Matches), tournament.WinnerId)
+			}
+			for _, match := range tournament.Matches {
+				if !match.Played || (!match.IsBye() && service.battleRepository.GetBattle(match.BattleId) == nil) {
+					t.Errorf("expected the match to be played with a stored battle: %+v", match)
+				}
+			}
+			if len(tournament.Standings) != tt.races || tournament.Standings[0].Rank != 1 {
+				t.Errorf("unexpected standings %+v", tournament.Standings)
+			}
+
+			// the tournament fights with snapshots of the fleets
+			if fleet := fleetRepository.Get("f1"); len(fleet.Ships) != 2 || len(fleet.Wrecks) != 0 {
+				t.Errorf("expected the stored fleet not to change, got %+v", fleet)
+			}
+
+			if _, err := service.Run(tournament.ID); err == nil {
+				t.Errorf("expected error when running a finished tournament")
+			}
+		})
+	}
+}
+
+func TestTournamentService_Seed(t *testing.T) {
+	results := make([][]string, 2)
+	for i := range results {
+		service, _ := newTestTournamentService(4)
+		tournament, err := service.Create("d1", "Cup", galaxy.TOURNAMENT_SINGLE_ELIMINATION, 7)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		tournament, _ = service.Run(tournament.ID)
+
+		for _, match := range tournament.Matches {
+			results[i] = append(results[i], fmt.Sprintf("%s-%s:%s/%d/%d", match.RaceA, match.RaceB, match.WinnerId, match.SurvivorsA, match.SurvivorsB))
+		}
+	}
+
+	if fmt.Sprint(results[0]) != fmt.Sprint(results[1]) {
+		t.Errorf("expected the same seed to give the same tournament, got %v and %v", results[0], results[1])
+	}
+}
+
+func TestTournamentService_Create(t *testing.T) {
+	service, _ := newTestTournamentService(1)
+
+	if _, err := service.Create("d1", "Cup", galaxy.TOURNAMENT_ROUND_ROBIN, 1); err == nil {
+		t.Errorf("expected error for a single participant")
+	}
+	if _, err := service.Create("unknown", "Cup", galaxy.TOURNAMENT_ROUND_ROBIN, 1); err != ErrDivisionNotFound {
+		t.Errorf("expected ErrDivisionNotFound, got %v", err)
+	}
+
+	service, _ = newTestTournamentService(2)
+	if _, err := service.Create("d1", "Cup", "swiss", 1); err == nil {
+		t.Errorf("expected error for an unknown format")
+	}
+}
@@ -0,0 +1,40 @@
+package game
+
+import (
+	"log/slog"
+	"time"
+)
+
+// TurnScheduler periodically resolves the turns whose window is over.
+type TurnScheduler struct {
+	turnService *TurnService
+	worker      *periodicWorker
+}
+
+func NewTurnScheduler(turnService *TurnService, interval time.Duration) *TurnScheduler {
+	s := &TurnScheduler{turnService: turnService}
+	s.worker = newPeriodicWorker(interval, s.advanceDueTurns)
+
+	return s
+}
+
+func (s *TurnScheduler) advanceDueTurns() {
+	for _, report := range s.turnService.AdvanceDueTurns() {
+		slog.Info("turn resolved", "division_id", report.DivisionId, "turn", report.Turn, "orders", len(report.Orders), "battles", len(report.Battles))
+	}
+}
+
+// Start runs the scheduler in a goroutine until Stop is called.
+func (s *TurnScheduler) Start() {
+	s.worker.Start()
+}
+
+// Stop stops the scheduler and waits until the running turn resolution ends.
+func (s *TurnScheduler) Stop() {
+	s.worker.Stop()
+}
+
+// Running reports whether the scheduler goroutine runs, used by the readiness check.
+func (s *TurnScheduler) Running() bool {
+	return s.worker.Running()
+}
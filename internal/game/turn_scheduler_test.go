@@ -0,0 +1,34 @@
+package game
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The scheduler resolves the turns in its goroutine while the handlers read the same repositories,
+// run with -race to check the repositories are synchronized.
+func TestTurnScheduler_ConcurrentReads(t *testing.T) {
+	setup := newTurnTestSetup(60)
+	// every reading of the clock is a minute later, each tick resolves a turn
+	var minutes atomic.Int64
+	setup.service.now = func() time.Time { return setup.now.Add(time.Duration(minutes.Add(1)) * time.Minute) }
+
+	scheduler := NewTurnScheduler(setup.service, time.Millisecond)
+	scheduler.Start()
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		setup.fleetRepository.FindByDivision("d1")
+		setup.turnRepository.GetReports("d1")
+		setup.budgetRepository.FindByDivision("d1")
+		setup.fleetBuildRepository.GetAll("d1", "")
+		// the metrics scrape counts the repositories
+		setup.fleetRepository.Count()
+		setup.fleetBuildRepository.Count()
+	}
+	scheduler.Stop()
+
+	if len(setup.turnRepository.GetReports("d1")) == 0 {
+		t.Error("expected the scheduler to resolve turns")
+	}
+}
@@ -0,0 +1,407 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"glaktika.eu/galaktika/pkg/util"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Map time the fleets fly during one turn
+const MAP_TIME_PER_TURN = 1.0
+
+// TurnService collects the orders of the races and resolves the turns of the divisions.
+type TurnService struct {
+	mutex sync.Mutex
+
+	turnRepository       *dao.TurnRepository
+	divisionRepository   *dao.DivisionRepository
+	fleetRepository      *dao.FleetRepository
+	fleetBuildRepository *dao.FleetBuildRepository
+	mapRepository        *dao.MapRepository
+	battleRepository     *dao.BattleRepository
+	fleetBuilder         *FleetBuilder
+	economyService       *EconomyService
+	ratingService        *RatingService
+	battleLimits         BattleLimits
+	idGenerator          util.IdGenerator
+
+	// clock of the turn windows, replaced in tests
+	now func() time.Time
+}
+
+func NewTurnService(
+	turnRepository *dao.TurnRepository,
+	divisionRepository *dao.DivisionRepository,
+	fleetRepository *dao.FleetRepository,
+	fleetBuildRepository *dao.FleetBuildRepository,
+	mapRepository *dao.MapRepository,
+	battleRepository *dao.BattleRepository,
+	fleetBuilder *FleetBuilder,
+	economyService *EconomyService,
+	ratingService *RatingService,
+	battleLimits BattleLimits,
+	idGenerator util.IdGenerator,
+) *TurnService {
+	return &TurnService{
+		turnRepository:       turnRepository,
+		divisionRepository:   divisionRepository,
+		fleetRepository:      fleetRepository,
+		fleetBuildRepository: fleetBuildRepository,
+		mapRepository:        mapRepository,
+		battleRepository:     battleRepository,
+		fleetBuilder:         fleetBuilder,
+		economyService:       economyService,
+		ratingService:        ratingService,
+		battleLimits:         battleLimits,
+		idGenerator:          idGenerator,
+		now:                  time.Now,
+	}
+}
+
+// startTurn creates the state of the turn with the given number, starting now.
+func (s *TurnService) startTurn(division *galaxy.Division, number int) *galaxy.TurnState {
+	now := s.now()
+	state := &galaxy.TurnState{DivisionId: division.ID, Number: number, StartedAt: now}
+	if division.TurnDuration > 0 {
+		endsAt := now.Add(time.Duration(division.TurnDuration) * time.Second)
+		state.EndsAt = &endsAt
+	}
+	s.turnRepository.UpsertState(state)
+
+	return state
+}
+
+func (s *TurnService) currentTurn(division *galaxy.Division) *galaxy.TurnState {
+	if state := s.turnRepository.GetState(division.ID); state != nil {
+		return state
+	}
+
+	return s.startTurn(division, 1)
+}
+
+// CurrentTurn returns the turn the division is in, the first turn starts with the first request.
+func (s *TurnService) CurrentTurn(divisionId string) (*galaxy.TurnState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	division := s.divisionRepository.Get(divisionId)
+	if division == nil {
+		return nil, ErrDivisionNotFound
+	}
+
+	return s.currentTurn(division), nil
+}
+
+// SubmitOrder checks the order of the race and stores it for the current turn of the division.
+func (s *TurnService) SubmitOrder(order *galaxy.Order) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	division := s.divisionRepository.Get(order.DivisionId)
+	if division == nil {
+		return ErrDivisionNotFound
+	}
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkOwnership(order); err != nil {
+		return err
+	}
+
+	order.ID = s.idGenerator.NextId()
+	order.Turn = s.currentTurn(division).Number
+	order.Status = galaxy.ORDER_STATUS_PENDING
+	order.Error = ""
+	s.turnRepository.UpsertOrder(order)
+
+	return nil
+}
+
+// checkOwnership checks that the fleet or the fleet build of the order belongs to the race and the division.
+func (s *TurnService) checkOwnership(order *galaxy.Order) error {
+	if order.FleetId != "" {
+		fleet := s.fleetRepository.Get(order.FleetId)
+		if fleet == nil || fleet.Owner != order.RaceId || fleet.DivisionId != order.DivisionId {
+			return fmt.Errorf("fleet %q is not a fleet of the race in the division", order.FleetId)
+		}
+	}
+
+	if order.FleetBuildId != "" {
+		fleetBuild := s.fleetBuildRepository.Get(order.FleetBuildId)
+		if fleetBuild == nil || fleetBuild.RaceId != order.RaceId || fleetBuild.DivisionId != order.DivisionId {
+			return fmt.Errorf("fleet build %q is not a fleet build of the race in the division", order.FleetBuildId)
+		}
+	}
+
+	return nil
+}
+
+// GetOrders returns the orders the race submitted for the current turn of the division.
+func (s *TurnService) GetOrders(divisionId string, raceId string) ([]*galaxy.Order, error) {
+	state, err := s.CurrentTurn(divisionId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return slices.DeleteFunc(s.turnRepository.FindOrders(divisionId, state.Number), func(order *galaxy.Order) bool {
+		return order.RaceId != raceId
+	}), nil
+}
+
+// CancelOrder removes a pending order of the race from the current turn.
+func (s *TurnService) CancelOrder(divisionId string, raceId string, orderId string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	order := s.turnRepository.GetOrder(orderId)
+	if order == nil || order.DivisionId != divisionId || order.RaceId != raceId {
+		return errors.New("order not found")
+	}
+	if order.Status != galaxy.ORDER_STATUS_PENDING {
+		return errors.New("order is already resolved")
+	}
+
+	s.turnRepository.DeleteOrder(orderId)
+
+	return nil
+}
+
+// AdvanceTurn ends the current turn of the division and resolves its orders.
+func (s *TurnService) AdvanceTurn(divisionId string) (*galaxy.TurnReport, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	division := s.divisionRepository.Get(divisionId)
+	if division == nil {
+		return nil, ErrDivisionNotFound
+	}
+
+	return s.resolveTurn(division), nil
+}
+
+// AdvanceDueTurns resolves the turns whose window is over. Called by the scheduler.
+func (s *TurnService) AdvanceDueTurns() []*galaxy.TurnReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	reports := []*galaxy.TurnReport{}
+	for _, division := range s.divisionRepository.GetAll() {
+		if division.TurnDuration <= 0 {
+			continue
+		}
+
+		state := s.currentTurn(division)
+		if state.EndsAt != nil && !s.now().Before(*state.EndsAt) {
+			reports = append(reports, s.resolveTurn(division))
+		}
+	}
+
+	return reports
+}
+
+// mapService returns the map service fighting the battles with the generator of the seed, seed 0 draws a random seed.
+func (s *TurnService) mapService(seed uint64) *MapService {
+	return NewMapService(s.mapRepository, s.fleetRepository, s.battleRepository, s.divisionRepository, s.turnRepository, s.ratingService,
+		s.economyService, s.battleLimits, s.idGenerator, gamemath.NewStdRandomGenerator(seed))
+}
+
+// DeployFleet positions the fleet at a planet of its division, see MapService.DeployFleet.
+// The fleets change under the turn lock, so they do not change while a turn is resolved.
+func (s *TurnService) DeployFleet(fleetId string, planetId string) ([]*galaxy.Battle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fleet := s.fleetRepository.Get(fleetId)
+	if fleet == nil {
+		return nil, ErrFleetNotFound
+	}
+
+	return s.mapService(0).DeployFleet(fleet, planetId)
+}
+
+// MoveFleet orders the fleet to fly to another planet of its division, see MapService.MoveFleet.
+func (s *TurnService) MoveFleet(fleetId string, planetId string) (*galaxy.FleetMovement, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fleet := s.fleetRepository.Get(fleetId)
+	if fleet == nil {
+		return nil, ErrFleetNotFound
+	}
+
+	return s.mapService(0).MoveFleet(fleet, planetId)
+}
+
+// AdvanceMapTime moves the map clock of the division forward without resolving a turn, see MapService.AdvanceTime.
+func (s *TurnService) AdvanceMapTime(divisionId string, delta float64) ([]*galaxy.Battle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.mapService(0).AdvanceTime(divisionId, delta)
+}
+
+// BuildFleet builds the fleet of the fleet build for the race under the turn lock, see FleetBuilder.Build.
+func (s *TurnService) BuildFleet(fleetBuildId string, raceId string) (*galaxy.Fleet, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.fleetBuilder.Build(fleetBuildId, raceId)
+}
+
+// ReinforceFleet reinforces the fleet of the race under the turn lock, see FleetBuilder.Reinforce.
+func (s *TurnService) ReinforceFleet(fleetBuildId string, raceId string) (*galaxy.Fleet, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.fleetBuilder.Reinforce(fleetBuildId, raceId)
+}
+
+// turnSeed makes the battles of a turn repeatable.
+func turnSeed(divisionId string, turn int) uint64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "%s/%d", divisionId, turn)
+
+	// seed 0 would be replaced by a random seed
+	return hash.Sum64() | 1
+}
+
+// resolveTurn locks the orders of the current turn and resolves them in phases: movement with
+// the battles, production of the planets and the fleets, and research. Then the next turn starts.
+func (s *TurnService) resolveTurn(division *galaxy.Division) *galaxy.TurnReport {
+	state := s.currentTurn(division)
+
+	orders := s.turnRepository.FindOrders(division.ID, state.Number)
+	galaxy.SortOrders(orders)
+
+	mapService := s.mapService(turnSeed(division.ID, state.Number))
+
+	report := &galaxy.TurnReport{
+		DivisionId: division.ID,
+		Turn:       state.Number,
+		Orders:     orders,
+		Battles:    []*galaxy.Battle{},
+	}
+
+	s.resolveOrders(report, mapService, galaxy.ORDER_MOVE)
+	report.Battles = append(report.Battles, s.advanceMap(mapService, division.ID)...)
+
+	s.economyService.CollectIncome(division, state.Number)
+	s.resolveOrders(report, mapService, galaxy.ORDER_BUILD)
+	s.resolveOrders(report, mapService, galaxy.ORDER_REINFORCE)
+	s.resolveOrders(report, mapService, galaxy.ORDER_RESEARCH)
+
+	report.ResolvedAt = s.now()
+	report.MapTime = s.mapRepository.GetTime(division.ID)
+	s.turnRepository.AddReport(report)
+	s.startTurn(division, state.Number+1)
+
+	return report
+}
+
+// resolveOrders resolves the orders of the given type and records their results.
+func (s *TurnService) resolveOrders(report *galaxy.TurnReport, mapService *MapService, orderType string) {
+	for _, order := range report.Orders {
+		if order.Type != orderType {
+			continue
+		}
+
+		battles, err := s.resolveOrder(mapService, order)
+		report.Battles = append(report.Battles, battles...)
+		if err != nil {
+			order.Status = galaxy.ORDER_STATUS_FAILED
+			order.Error = err.Error()
+		} else {
+			order.Status = galaxy.ORDER_STATUS_DONE
+		}
+	}
+}
+
+func (s *TurnService) advanceMap(mapService *MapService, divisionId string) []*galaxy.Battle {
+	battles, _ := mapService.AdvanceTime(divisionId, MAP_TIME_PER_TURN)
+
+	return battles
+}
+
+func (s *TurnService) resolveOrder(mapService *MapService, order *galaxy.Order) ([]*galaxy.Battle, error) {
+	// the fleets and the fleet builds may have changed since the order was submitted
+	if err := s.checkOwnership(order); err != nil {
+		return nil, err
+	}
+
+	switch order.Type {
+	case galaxy.ORDER_MOVE:
+		fleet := s.fleetRepository.Get(order.FleetId)
+		if fleet.Location == "" && fleet.Movement == nil {
+			return mapService.DeployFleet(fleet, order.PlanetId)
+		}
+		_, err := mapService.MoveFleet(fleet, order.PlanetId)
+		return nil, err
+	case galaxy.ORDER_BUILD, galaxy.ORDER_REINFORCE:
+		return nil, s.build(order)
+	case galaxy.ORDER_RESEARCH:
+		return nil, s.research(order)
+	}
+
+	return nil, fmt.Errorf("unknown order type %q", order.Type)
+}
+
+// build builds or reinforces the fleet of the order, the fleet builder pays the new ships from the budget of the race.
+func (s *TurnService) build(order *galaxy.Order) error {
+	var err error
+	if order.Type == galaxy.ORDER_REINFORCE {
+		_, err = s.fleetBuilder.Reinforce(order.FleetBuildId, order.RaceId)
+	} else {
+		_, err = s.fleetBuilder.Build(order.FleetBuildId, order.RaceId)
+	}
+
+	return err
+}
+
+// research spends the resources of the order on a technology or researches a tech tree node.
+// The resources are paid from the budget of the race and the fleet build must stay within its total income.
+// The research changes a copy of the fleet build, it is stored only when the research is paid.
+func (s *TurnService) research(order *galaxy.Order) error {
+	fleetBuild, division, err := s.fleetBuilder.Prepare(order.FleetBuildId)
+	if err != nil {
+		return err
+	}
+
+	cost := order.Resources
+	switch order.Technology {
+	case galaxy.TECHNOLOGY_ATTACK:
+		fleetBuild.AttackResources += order.Resources
+	case galaxy.TECHNOLOGY_DEFENSE:
+		fleetBuild.DefenseResources += order.Resources
+	case galaxy.TECHNOLOGY_ENGINE:
+		fleetBuild.EngineResources += order.Resources
+	case galaxy.TECHNOLOGY_CARGO:
+		fleetBuild.CargoResources += order.Resources
+	default:
+		fleetBuild.ResearchedNodes = append(fleetBuild.ResearchedNodes, order.Technology)
+		if err := fleetBuild.ValidateResearchedNodes(); err != nil {
+			return err
+		}
+		cost = fleetBuild.NodeCost(order.Technology)
+	}
+
+	budget := s.economyService.Budget(division, order.RaceId)
+	if statistics := fleetBuild.CalculateStatistics(budget.TotalIncome()); statistics.ExceedingResources > 0 {
+		return fmt.Errorf("research exceeds the resources of the race by %d", statistics.ExceedingResources)
+	}
+	if err := s.economyService.Pay(division, order.RaceId, order.Turn, cost, order.Technology+" research"); err != nil {
+		return err
+	}
+	s.fleetBuildRepository.Upsert(fleetBuild)
+
+	return nil
+}
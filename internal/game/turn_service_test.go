@@ -0,0 +1,209 @@
+package game
+
+import (
+	"glaktika.eu/galaktika/internal/dao"
+	"glaktika.eu/galaktika/pkg/galaxy"
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+	"time"
+)
+
+type turnTestSetup struct {
+	service              *TurnService
+	fleetRepository      *dao.FleetRepository
+	fleetBuildRepository *dao.FleetBuildRepository
+	turnRepository       *dao.TurnRepository
+	budgetRepository     *dao.BudgetRepository
+	now                  time.Time
+}
+
+func newTurnTestSetup(turnDuration int) *turnTestSetup {
+	divisionRepository := dao.NewDivisionRepository()
+	divisionRepository.Upsert(&galaxy.Division{ID: "d1", ResourcesAmount: 100, TurnDuration: turnDuration})
+
+	mapRepository := dao.NewMapRepository()
+	mapRepository.UpsertPlanet(&galaxy.Planet{ID: "p1", DivisionId: "d1", X: 0, Y: 0})
+	mapRepository.UpsertPlanet(&galaxy.Planet{ID: "p2", DivisionId: "d1", X: 0, Y: 1, OwnerId: "race-a", Population: 50, Industry: 5})
+
+	shipModelRepository := dao.NewShipModelRepository()
+	shipModelRepository.Upsert(&galaxy.ShipModel{ID: "sm1", Name: "Fighter", Guns: 1, OneGunMass: 2, DefenseMass: 2, EngineMass: 4})
+
+	fleetBuildRepository := dao.NewFleetBuildRepository()
+	fleetBuildRepository.Upsert(&galaxy.FleetBuild{ID: "fb1", DivisionId: "d1", RaceId: "race-a"})
+	fleetBuildRepository.AssignShipModel(&galaxy.FleetBuildToShipModel{FleetBuildID: "fb1", ShipModelID: "sm1", ShipModelVersion: 1, Amount: 5})
+
+	fleetRepository := dao.NewFleetRepository()
+	fleet := newMapTestFleet("f1", "race-a", 1)
+	fleetRepository.Upsert(fleet)
+
+	turnRepository := dao.NewTurnRepository()
+	budgetRepository := dao.NewBudgetRepository()
+	idGenerator := &util.SimpleIdGenerator{}
+	economyService := NewEconomyService(budgetRepository, mapRepository)
+	fleetBuilder := NewFleetBuilder(fleetBuildRepository, fleetRepository, shipModelRepository, divisionRepository, mapRepository,
+		turnRepository, economyService, idGenerator)
+	service := NewTurnService(turnRepository, divisionRepository, fleetRepository, fleetBuildRepository, mapRepository,
+		dao.NewBattleRepository(), fleetBuilder, economyService,
+		NewRatingService(dao.NewRatingRepository(), fleetRepository), DefaultBattleLimits(), idGenerator)
+
+	setup := &turnTestSetup{
+		service:              service,
+		fleetRepository:      fleetRepository,
+		fleetBuildRepository: fleetBuildRepository,
+		turnRepository:       turnRepository,
+		budgetRepository:     budgetRepository,
+		now:                  time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	service.now = func() time.Time { return setup.now }
+
+	return setup
+}
+
+func TestTurnService_SubmitOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   galaxy.Order
+		wantErr bool
+	}{
+		{name: "move own fleet", order: galaxy.Order{Type: galaxy.ORDER_MOVE, FleetId: "f1", PlanetId: "p1", RaceId: "race-a"}},
+		{name: "move fleet of another race", order: galaxy.Order{Type: galaxy.ORDER_MOVE, FleetId: "f1", PlanetId: "p1", RaceId: "race-b"}, wantErr: true},
+		{name: "research without resources", order: galaxy.Order{Type: galaxy.ORDER_RESEARCH, FleetBuildId: "fb1", Technology: "attack", RaceId: "race-a"}, wantErr: true},
+		{name: "build other fleet build", order: galaxy.Order{Type: galaxy.ORDER_BUILD, FleetBuildId: "fb1", RaceId: "race-b"}, wantErr: true},
+		{name: "unknown type", order: galaxy.Order{Type: "retreat", RaceId: "race-a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup := newTurnTestSetup(0)
+			order := tt.order
+			order.DivisionId = "d1"
+
+			err := setup.service.SubmitOrder(&order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SubmitOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (order.Turn != 1 || order.Status != galaxy.ORDER_STATUS_PENDING || order.ID == "") {
+				t.Errorf("unexpected order %+v", order)
+			}
+		})
+	}
+}
+
+func TestTurnService_AdvanceTurn(t *testing.T) {
+	setup := newTurnTestSetup(0)
+
+	orders := []*galaxy.Order{
+		{Type: galaxy.ORDER_RESEARCH, FleetBuildId: "fb1", Technology: galaxy.TECHNOLOGY_ATTACK, Resources: 30},
+		{Type: galaxy.ORDER_RESEARCH, FleetBuildId: "fb1", Technology: galaxy.TECHNOLOGY_DEFENSE, Resources: 60},
+		{Type: galaxy.ORDER_BUILD, FleetBuildId: "fb1"},
+		{Type: galaxy.ORDER_MOVE, FleetId: "f1", PlanetId: "p1"},
+	}
+	for _, order := range orders {
+		order.DivisionId = "d1"
+		order.RaceId = "race-a"
+		if err := setup.service.SubmitOrder(order); err != nil {
+			t.Fatalf("SubmitOrder() error = %v", err)
+		}
+	}
+
+	report, err := setup.service.AdvanceTurn("d1")
+	if err != nil {
+		t.Fatalf("AdvanceTurn() error = %v", err)
+	}
+
+	// move, build, then the research orders. The budget of 100 resources and 10 income
+	// pays 40 for the ships and 30 for the first research, the second research is not affordable.
+	expected := []struct {
+		orderType string
+		status    string
+	}{
+		{galaxy.ORDER_MOVE, galaxy.ORDER_STATUS_DONE},
+		{galaxy.ORDER_BUILD, galaxy.ORDER_STATUS_DONE},
+		{galaxy.ORDER_RESEARCH, galaxy.ORDER_STATUS_DONE},
+		{galaxy.ORDER_RESEARCH, galaxy.ORDER_STATUS_FAILED},
+	}
+	if len(report.Orders) != len(expected) {
+		t.Fatalf("expected %d orders in the report, got %d", len(expected), len(report.Orders))
+	}
+	for i, order := range report.Orders {
+		if order.Type != expected[i].orderType || order.Status != expected[i].status {
+			t.Errorf("order %d: got %s %s (%s), want %s %s", i, order.Type, order.Status, order.Error, expected[i].orderType, expected[i].status)
+		}
+	}
+
+	if fleetBuild := setup.fleetBuildRepository.Get("fb1"); fleetBuild.AttackResources != 30 || fleetBuild.DefenseResources != 0 {
+		t.Errorf("unexpected research %+v", fleetBuild)
+	}
+	budget := setup.budgetRepository.Get("d1", "race-a")
+	if budget.Balance != 40 || budget.TotalIncome() != 110 || len(budget.Entries) != 4 {
+		t.Errorf("unexpected budget balance %v, income %d, %d entries", budget.Balance, budget.TotalIncome(), len(budget.Entries))
+	}
+	if report.Turn != 1 || report.MapTime != MAP_TIME_PER_TURN {
+		t.Errorf("unexpected report turn %d, map time %v", report.Turn, report.MapTime)
+	}
+	if state, _ := setup.service.CurrentTurn("d1"); state.Number != 2 {
+		t.Errorf("expected turn 2, got %d", state.Number)
+	}
+	if setup.turnRepository.GetReport("d1", 1) != report {
+		t.Errorf("expected the report to be archived")
+	}
+	if divisionFleet := setup.fleetRepository.GetDivisionFleet("d1", "race-a"); divisionFleet == nil {
+		t.Errorf("expected the fleet to be built")
+	}
+
+	// orders of the resolved turn can not be cancelled
+	if err := setup.service.CancelOrder("d1", "race-a", orders[0].ID); err == nil {
+		t.Errorf("expected error when cancelling a resolved order")
+	}
+}
+
+func TestTurnService_ResearchNode(t *testing.T) {
+	setup := newTurnTestSetup(0)
+	// the fleet build researches the tree of its division, not the default one
+	techTree, err := galaxy.NewTechTree([]*galaxy.TechNode{{ID: "lasers", Name: "Lasers", Cost: 25}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fleetBuild := setup.fleetBuildRepository.Get("fb1")
+	fleetBuild.TechTree = techTree
+	setup.fleetBuildRepository.Upsert(fleetBuild)
+
+	order := &galaxy.Order{Type: galaxy.ORDER_RESEARCH, DivisionId: "d1", RaceId: "race-a", FleetBuildId: "fb1", Technology: "lasers"}
+	if err := setup.service.SubmitOrder(order); err != nil {
+		t.Fatalf("SubmitOrder() error = %v", err)
+	}
+	report, err := setup.service.AdvanceTurn("d1")
+	if err != nil {
+		t.Fatalf("AdvanceTurn() error = %v", err)
+	}
+
+	if report.Orders[0].Status != galaxy.ORDER_STATUS_DONE {
+		t.Fatalf("expected the research to be done, got %s (%s)", report.Orders[0].Status, report.Orders[0].Error)
+	}
+	if budget := setup.budgetRepository.Get("d1", "race-a"); budget.Balance != 85 {
+		t.Errorf("expected the node cost of the tree of the fleet build, got balance %v", budget.Balance)
+	}
+}
+
+func TestTurnService_AdvanceDueTurns(t *testing.T) {
+	setup := newTurnTestSetup(60)
+
+	state, _ := setup.service.CurrentTurn("d1")
+	if state.EndsAt == nil || !state.EndsAt.Equal(setup.now.Add(time.Minute)) {
+		t.Fatalf("unexpected turn end %v", state.EndsAt)
+	}
+
+	setup.now = setup.now.Add(59 * time.Second)
+	if reports := setup.service.AdvanceDueTurns(); len(reports) != 0 {
+		t.Fatalf("expected no turn to be resolved, got %d", len(reports))
+	}
+
+	setup.now = setup.now.Add(time.Second)
+	reports := setup.service.AdvanceDueTurns()
+	if len(reports) != 1 || reports[0].Turn != 1 {
+		t.Fatalf("expected turn 1 to be resolved, got %+v", reports)
+	}
+	if state, _ := setup.service.CurrentTurn("d1"); state.Number != 2 || !state.EndsAt.Equal(setup.now.Add(time.Minute)) {
+		t.Errorf("unexpected next turn %+v", state)
+	}
+}
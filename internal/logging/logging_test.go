@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		format   string
+		expected string
+		err      bool
+	}{
+		{name: "text", level: "info", format: FORMAT_TEXT, expected: "level=INFO msg=shown"},
+		{name: "json", level: "info", format: FORMAT_JSON, expected: `"level":"INFO","msg":"shown"`},
+		{name: "level", level: "error", format: FORMAT_TEXT},
+		{name: "unknown level", level: "loud", format: FORMAT_TEXT, err: true},
+		{name: "unknown format", level: "info", format: "xml", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			logger, err := New(&output, tt.level, tt.format)
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			logger.Debug("hidden")
+			logger.Info("shown")
+			if !strings.Contains(output.String(), tt.expected) || strings.Contains(output.String(), "hidden") {
+				t.Errorf("expected %q logged, got %q", tt.expected, output.String())
+			}
+			if tt.expected == "" && output.Len() > 0 {
+				t.Errorf("expected nothing logged, got %q", output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TEXT_CONTENT_TYPE is the content type of the Prometheus text exposition format.
+const TEXT_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+// Buckets of the histograms
+var (
+	DURATION_BUCKETS = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	COUNT_BUCKETS    = []float64{1, 10, 50, 100, 500, 1000, 5000, 10000}
+)
+
+// Default is the registry of the server, the metrics of the packages are registered in it.
+var Default = NewRegistry()
+
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds the metrics exposed in the Prometheus text format. A metric is registered once by its name,
+// registering the name again returns the registered metric.
+type Registry struct {
+	mutex   sync.Mutex
+	metrics map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+func (r *Registry) register(name string, create func() metric) metric {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if m, ok := r.metrics[name]; ok {
+		return m
+	}
+	m := create()
+	r.metrics[name] = m
+
+	return m
+}
+
+// Counter registers a counter with the label names.
+func (r *Registry) Counter(name string, help string, labels ...string) *Counter {
+	return r.register(name, func() metric {
+		return &Counter{name: name, help: help, labels: labels, series: map[string]*counterSeries{}}
+	}).(*Counter)
+}
+
+// Histogram registers a histogram of the buckets, given by their upper bounds in ascending order.
+func (r *Registry) Histogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	return r.register(name, func() metric {
+		return &Histogram{name: name, help: help, buckets: buckets, labels: labels, series: map[string]*histogramSeries{}}
+	}).(*Histogram)
+}
+
+// GaugeFunc registers a gauge read when the metrics are written. The collect function returns the values
+// by the value of the label, or a single value under the "" key when the label is empty.
+// Registering the name again replaces the function, the services are recreated by the tests.
+func (r *Registry) GaugeFunc(name string, help string, label string, collect func() map[string]float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.metrics[name] = &gaugeFunc{name: name, help: help, label: label, collect: collect}
+}
+
+// WriteText writes all the metrics ordered by their names.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mutex.Lock()
+	names := slices.Sorted(maps.Keys(r.metrics))
+	metrics := make([]metric, len(names))
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mutex.Unlock()
+
+	writer := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(writer)
+	}
+
+	return writer.Flush()
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// Counter is a value which only goes up, one value for each combination of the label values.
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mutex  sync.Mutex
+	series map[string]*counterSeries
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(value float64, labelValues ...string) {
+	checkLabels(c.name, c.labels, labelValues)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := seriesKey(labelValues)
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labelValues: slices.Clone(labelValues)}
+		c.series[key] = s
+	}
+	s.value += value
+}
+
+// Value returns the value of the label values.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if s, ok := c.series[seriesKey(labelValues)]; ok {
+		return s.value
+	}
+
+	return 0
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	for _, key := range slices.Sorted(maps.Keys(c.series)) {
+		s := c.series[key]
+		writeSample(w, c.name, c.labels, s.labelValues, "", "", s.value)
+	}
+}
+
+type histogramSeries struct {
+	labelValues []string
+	// observations of each bucket, not cumulative, the last one is +Inf
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Histogram counts the observations in buckets and keeps their sum and count.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	labels  []string
+
+	mutex  sync.Mutex
+	series map[string]*histogramSeries
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	checkLabels(h.name, h.labels, labelValues)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	key := seriesKey(labelValues)
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: slices.Clone(labelValues), counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+
+	bucket, _ := slices.BinarySearch(h.buckets, value)
+	s.counts[bucket]++
+	s.sum += value
+	s.count++
+}
+
+// Sum returns the sum and the count of the observations of the label values.
+func (h *Histogram) Sum(labelValues ...string) (float64, uint64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if s, ok := h.series[seriesKey(labelValues)]; ok {
+		return s.sum, s.count
+	}
+
+	return 0, 0
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range slices.Sorted(maps.Keys(h.series)) {
+		s := h.series[key]
+		cumulative := uint64(0)
+		for i, count := range s.counts {
+			cumulative += count
+			bound := math.Inf(1)
+			if i < len(h.buckets) {
+				bound = h.buckets[i]
+			}
+			writeSample(w, h.name+"_bucket", h.labels, s.labelValues, "le", formatValue(bound), float64(cumulative))
+		}
+		writeSample(w, h.name+"_sum", h.labels, s.labelValues, "", "", s.sum)
+		writeSample(w, h.name+"_count", h.labels, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+type gaugeFunc struct {
+	name    string
+	help    string
+	label   string
+	collect func() map[string]float64
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	values := g.collect()
+
+	writeHeader(w, g.name, g.help, "gauge")
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if g.label == "" {
+			writeSample(w, g.name, nil, nil, "", "", values[key])
+			continue
+		}
+		writeSample(w, g.name, []string{g.label}, []string{key}, "", "", values[key])
+	}
+}
+
+// checkLabels panics on a wrong number of label values, it is a bug of the instrumented code.
+func checkLabels(name string, labels []string, labelValues []string) {
+	if len(labels) != len(labelValues) {
+		panic("BUG: metric " + name + " has labels " + strings.Join(labels, ",") + ", got " + strconv.Itoa(len(labelValues)) + " values")
+	}
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeHeader(w *bufio.Writer, name string, help string, kind string) {
+	w.WriteString("# HELP " + name + " " + strings.ReplaceAll(help, "\n", " ") + "\n")
+	w.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+// writeSample writes a line of the metric, the extra label is the le label of the histogram buckets.
+func writeSample(w *bufio.Writer, name string, labels []string, labelValues []string, extraLabel string, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		pairs := make([]string, 0, len(labels)+1)
+		for i, label := range labels {
+			pairs = append(pairs, label+`="`+labelEscaper.Replace(labelValues[i])+`"`)
+		}
+		if extraLabel != "" {
+			pairs = append(pairs, extraLabel+`="`+extraValue+`"`)
+		}
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	w.WriteString(" " + formatValue(value) + "\n")
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.Counter("requests_total", "Requests.", "route", "status")
+	requests.Inc("/a", "200")
+	requests.Add(2, "/a", "200")
+	requests.Inc(`/b"\`, "404")
+	durations := registry.Histogram("duration_seconds", "Durations.", []float64{0.1, 1})
+	durations.Observe(0.05)
+	durations.Observe(0.1)
+	durations.Observe(3)
+	registry.GaugeFunc("objects", "Objects.", "repository", func() map[string]float64 {
+		return map[string]float64{"fleets": 2, "battles": 1}
+	})
+	registry.GaugeFunc("waiting", "Waiting.", "", func() map[string]float64 { return map[string]float64{"": 4} })
+
+	// registering again returns the registered metric
+	if registry.Counter("requests_total", "Requests.", "route", "status") != requests {
+		t.Error("expected the registered counter")
+	}
+
+	var output bytes.Buffer
+	if err := registry.WriteText(&output); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	expected := `# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1"} 2
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 3.15
+duration_seconds_count 3
+# HELP objects Objects.
+# TYPE objects gauge
+objects{repository="battles"} 1
+objects{repository="fleets"} 2
+# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 3
+requests_total{route="/b\"\\",status="404"} 1
+# HELP waiting Waiting.
+# TYPE waiting gauge
+waiting 4
+`
+	if output.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, output.String())
+	}
+}
+
+func TestCounter_WrongLabels(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for the missing label value")
+		}
+	}()
+
+	NewRegistry().Counter("requests_total", "Requests.", "route").Inc()
+}
@@ -0,0 +1,153 @@
+package galaxy
+
+import (
+	"errors"
+	"fmt"
+	"glaktika.eu/galaktika/pkg/util"
+	"math"
+	"slices"
+	"strings"
+)
+
+// AI fleet archetypes
+const (
+	ARCHETYPE_SWARM        = "swarm"
+	ARCHETYPE_GLASS_CANNON = "glass_cannon"
+	ARCHETYPE_TANK         = "tank"
+	ARCHETYPE_BALANCED     = "balanced"
+)
+
+// Lightest ship the AI designs: one unit of mass for each of a gun, defense and engine
+const AI_MIN_SHIP_MASS = 3
+
+// Archetype is the plan of an AI fleet: how the budget is split between the research and the ships,
+// and how the ship design is split between the guns, the defense and the engines.
+type Archetype struct {
+	Name string `json:"name"`
+	// share of the budget spent on research, the rest builds the ships
+	ResearchShare float64 `json:"research_share"`
+	// split of the research resources
+	AttackResearch  float64 `json:"attack_research"`
+	DefenseResearch float64 `json:"defense_research"`
+	EngineResearch  float64 `json:"engine_research"`
+	CargoResearch   float64 `json:"cargo_research"`
+	// split of the ship mass
+	WeaponShare  float64 `json:"weapon_share"`
+	DefenseShare float64 `json:"defense_share"`
+	EngineShare  float64 `json:"engine_share"`
+	// planned number of ships, fewer ships are built when the ships would be too light
+	Ships int `json:"ships"`
+}
+
+var Archetypes = []*Archetype{
+	{Name: ARCHETYPE_SWARM, ResearchShare: 0.1, AttackResearch: 0.5, DefenseResearch: 0.2, EngineResearch: 0.3,
+		WeaponShare: 0.34, DefenseShare: 0.33, EngineShare: 0.33, Ships: 20},
+	{Name: ARCHETYPE_GLASS_CANNON, ResearchShare: 0.35, AttackResearch: 0.8, DefenseResearch: 0, EngineResearch: 0.2,
+		WeaponShare: 0.7, DefenseShare: 0.1, EngineShare: 0.2, Ships: 4},
+	{Name: ARCHETYPE_TANK, ResearchShare: 0.35, AttackResearch: 0.2, DefenseResearch: 0.7, EngineResearch: 0.1,
+		WeaponShare: 0.2, DefenseShare: 0.6, EngineShare: 0.2, Ships: 4},
+	{Name: ARCHETYPE_BALANCED, ResearchShare: 0.25, AttackResearch: 0.4, DefenseResearch: 0.4, EngineResearch: 0.2,
+		WeaponShare: 0.4, DefenseShare: 0.35, EngineShare: 0.25, Ships: 8},
+}
+
+// GetArchetype returns the archetype with the given name, or nil.
+func GetArchetype(name string) *Archetype {
+	for _, archetype := range Archetypes {
+		if archetype.Name == name {
+			return archetype
+		}
+	}
+
+	return nil
+}
+
+// DesignShip returns the ship model of the archetype with the given total mass. The design follows the rules:
+// the guns are light unless heavy guns are unlocked, the guns limit, the mass limit and the engine ratio.
+func (archetype *Archetype) DesignShip(mass int, rules *ValidationRules, unlocked ComponentSet) *ShipModel {
+	if rules == nil {
+		rules = DefaultValidationRules()
+	}
+	if rules.MaxTotalMass > 0 {
+		mass = min(mass, int(math.Floor(rules.MaxTotalMass)))
+	}
+	mass = max(mass, AI_MIN_SHIP_MASS)
+
+	engine := max(int(math.Round(float64(mass)*archetype.EngineShare)), int(math.Ceil(float64(mass)*rules.MinEngineRatio)), 1)
+	weapon := max(min(int(math.Round(float64(mass)*archetype.WeaponShare)), mass-engine), 1)
+
+	guns := 1
+	if !unlocked[COMPONENT_HEAVY_GUNS] {
+		guns = int(math.Ceil(float64(weapon) / LIGHT_GUN_MAX_MASS))
+	}
+	if rules.MaxGuns > 0 {
+		guns = min(guns, rules.MaxGuns)
+	}
+	gunMass := weapon / guns
+	if !unlocked[COMPONENT_HEAVY_GUNS] {
+		gunMass = min(gunMass, LIGHT_GUN_MAX_MASS)
+	}
+
+	defense := max(mass-engine-guns*gunMass, 0)
+
+	shipModel := &ShipModel{
+		Name: fmt.Sprintf("%s %d", strings.ReplaceAll(archetype.Name, "_", " "), mass),
+		Modules: []ShipModule{
+			{Type: MODULE_WEAPON, Count: guns, Mass: float64(gunMass)},
+			{Type: MODULE_DEFENSE, Mass: float64(defense)},
+			{Type: MODULE_ENGINE, Mass: float64(engine)},
+		},
+		OwnerId: AI_RACE_ID,
+	}
+	shipModel.Modules = slices.DeleteFunc(shipModel.Modules, func(module ShipModule) bool { return module.TotalMass() == 0 })
+	shipModel.MigrateModules()
+
+	return shipModel
+}
+
+// GenerateFleetBuild plans the fleet build of the AI in the division with the budget: the ships of the archetype
+// design and the research of the rest of the budget. The division resources are used when the budget is 0.
+func GenerateFleetBuild(division *Division, archetype *Archetype, budget int, idGenerator util.IdGenerator) (*FleetBuild, error) {
+	if budget == 0 {
+		budget = division.ResourcesAmount
+	}
+	if budget < AI_MIN_SHIP_MASS {
+		return nil, fmt.Errorf("budget %d is too small for a ship", budget)
+	}
+
+	fleetBuild := &FleetBuild{
+		ID:              idGenerator.NextId(),
+		DivisionId:      division.ID,
+		RaceId:          AI_RACE_ID,
+		ResearchedNodes: []string{},
+	}
+	fleetBuild.ApplyDivision(division)
+
+	shipBudget := int(math.Round(float64(budget) * (1 - archetype.ResearchShare)))
+	shipMass := max(shipBudget/max(archetype.Ships, 1), AI_MIN_SHIP_MASS)
+
+	shipModel := archetype.DesignShip(shipMass, fleetBuild.ValidationRules, fleetBuild.UnlockedComponents())
+	shipModel.ID = idGenerator.NextId()
+	shipModel.Version = 1
+	if err := fleetBuild.ValidateShipModel(shipModel); err != nil {
+		return nil, err
+	}
+
+	cost := int(shipModel.CalculateTotalMass())
+	amount := max(shipBudget, cost) / cost
+	for ; amount > 0; amount-- {
+		fleetBuild.AssignedShipModels = []ShipModelAssignment{{ShipModel: *shipModel, Amount: amount}}
+
+		// the rest of the budget is researched
+		research := float64(budget - amount*cost)
+		fleetBuild.AttackResources = math.Floor(research * archetype.AttackResearch)
+		fleetBuild.DefenseResources = math.Floor(research * archetype.DefenseResearch)
+		fleetBuild.EngineResources = math.Floor(research * archetype.EngineResearch)
+		fleetBuild.CargoResources = math.Floor(research * archetype.CargoResearch)
+
+		if statistics := fleetBuild.CalculateStatistics(budget); statistics.ExceedingResources == 0 {
+			return fleetBuild, nil
+		}
+	}
+
+	return nil, errors.New("no ship of the archetype fits the budget")
+}
@@ -0,0 +1,77 @@
+package galaxy
+
+import (
+	"glaktika.eu/galaktika/pkg/util"
+	"testing"
+)
+
+func TestGenerateFleetBuild(t *testing.T) {
+	divisions := []struct {
+		name     string
+		division *Division
+	}{
+		{name: "default rules", division: &Division{ID: "d1", ResourcesAmount: 300}},
+		{name: "strict rules", division: &Division{ID: "d2", ResourcesAmount: 300,
+			ValidationRules: &ValidationRules{MaxGuns: 1, MaxTotalMass: 12, MinEngineRatio: 0.4}}},
+		{name: "tech floor", division: &Division{ID: "d3", ResourcesAmount: 1000, TechAttack: 3, TechDefense: 2}},
+	}
+
+	for _, d := range divisions {
+		for _, archetype := range Archetypes {
+			t.Run(d.name+"/"+archetype.Name, func(t *testing.T) {
+				fleetBuild, err := GenerateFleetBuild(d.division, archetype, 0, &util.SimpleIdGenerator{})
+				if err != nil {
+					t.Fatalf("GenerateFleetBuild() error = %v", err)
+				}
+
+				statistics := fleetBuild.CalculateStatistics(d.division.ResourcesAmount)
+				if statistics.ExceedingResources != 0 {
+					t.Errorf("fleet build exceeds the budget: %+v", statistics)
+				}
+				if len(fleetBuild.AssignedShipModels) != 1 || fleetBuild.AssignedShipModels[0].Amount <= 0 {
+					t.Fatalf("expected ships to be assigned, got %+v", fleetBuild.AssignedShipModels)
+				}
+
+				shipModel := fleetBuild.AssignedShipModels[0].ShipModel
+				if err := fleetBuild.ValidateShipModel(&shipModel); err != nil {
+					t.Errorf("generated ship model is not valid: %v", err)
+				}
+				if fleetBuild.RaceId != AI_RACE_ID || shipModel.OwnerId != AI_RACE_ID {
+					t.Errorf("expected the fleet build of the AI, got %+v", fleetBuild)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateFleetBuild_Archetypes(t *testing.T) {
+	division := &Division{ID: "d1", ResourcesAmount: 500}
+	generate := func(name string) *FleetBuild {
+		fleetBuild, err := GenerateFleetBuild(division, GetArchetype(name), 0, &util.SimpleIdGenerator{})
+		if err != nil {
+			t.Fatalf("GenerateFleetBuild(%s) error = %v", name, err)
+		}
+		return fleetBuild
+	}
+
+	swarm := generate(ARCHETYPE_SWARM).AssignedShipModels[0]
+	glassCannon := generate(ARCHETYPE_GLASS_CANNON)
+	tank := generate(ARCHETYPE_TANK)
+
+	if swarm.Amount <= glassCannon.AssignedShipModels[0].Amount {
+		t.Errorf("expected the swarm to have more ships: %d, %d", swarm.Amount, glassCannon.AssignedShipModels[0].Amount)
+	}
+
+	glassCannonTech := glassCannon.CalculateShipTech(&glassCannon.AssignedShipModels[0].ShipModel)
+	tankTech := tank.CalculateShipTech(&tank.AssignedShipModels[0].ShipModel)
+	if glassCannonTech.Attack*float64(glassCannonTech.Guns) <= tankTech.Attack*float64(tankTech.Guns) || tankTech.Defense <= glassCannonTech.Defense {
+		t.Errorf("expected the glass cannon to hit harder and the tank to defend better: %+v, %+v", glassCannonTech, tankTech)
+	}
+
+	if _, err := GenerateFleetBuild(division, GetArchetype(ARCHETYPE_BALANCED), 2, &util.SimpleIdGenerator{}); err == nil {
+		t.Errorf("expected error for a budget too small for a ship")
+	}
+	if GetArchetype("unknown") != nil {
+		t.Errorf("expected no unknown archetype")
+	}
+}
@@ -0,0 +1,246 @@
+package galaxy
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Format and the current version of the division archives. Archives of newer versions are rejected.
+const (
+	ARCHIVE_FORMAT  = "galaktika-division"
+	ARCHIVE_VERSION = 1
+)
+
+// Handling of the archived ids which are already stored
+const (
+	// the import fails listing the conflicting ids
+	ARCHIVE_CONFLICT_FAIL = "fail"
+	// the archived objects get new ids
+	ARCHIVE_CONFLICT_RENAME = "rename"
+	// the stored objects are replaced, the stored ship models get the archived designs as new versions
+	ARCHIVE_CONFLICT_OVERWRITE = "overwrite"
+)
+
+// DivisionArchive is the complete state of a division, used for backups and for moving a division between servers.
+type DivisionArchive struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+
+	Division  *Division  `json:"division"`
+	MapTime   float64    `json:"map_time"`
+	TurnState *TurnState `json:"turn_state,omitempty"`
+	// races playing in the division, their tokens are not exported
+	Races []*ArchiveRace `json:"races"`
+	// all versions of the ship models of the races, ordered by id and version
+	ShipModels     []*ShipModel         `json:"ship_models"`
+	FleetBuilds    []*FleetBuild        `json:"fleet_builds"`
+	Assignments    []*ArchiveAssignment `json:"assignments"`
+	Planets        []*Planet            `json:"planets"`
+	Fleets         []*Fleet             `json:"fleets"`
+	DivisionFleets []*DivisionFleet     `json:"division_fleets"`
+	FleetEvents    []*FleetEvent        `json:"fleet_events"`
+	Battles        []*Battle            `json:"battles"`
+	Ratings        []*Rating            `json:"ratings"`
+	RatingChanges  []*RatingChange      `json:"rating_changes"`
+	Budgets        []*Budget            `json:"budgets"`
+}
+
+type ArchiveRace struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Role string `json:"role,omitempty"`
+}
+
+// ArchiveAssignment is a ship model assigned to a fleet build.
+type ArchiveAssignment struct {
+	FleetBuildId     string `json:"fleet_build_id"`
+	ShipModelId      string `json:"ship_model_id"`
+	ShipModelVersion int    `json:"ship_model_version"`
+	Amount           int    `json:"amount"`
+}
+
+// ArchiveImportOptions set how an archive is stored.
+type ArchiveImportOptions struct {
+	// id of the imported division, the archived id when empty
+	DivisionId string `json:"division_id"`
+	// ARCHIVE_CONFLICT_FAIL when empty
+	Conflict string `json:"conflict"`
+	// archived race id -> race id of the server, the races not listed keep their ids
+	Races map[string]string `json:"races"`
+}
+
+// ArchiveMapping lists the archived ids which were changed by the import, by the kind of the object.
+type ArchiveMapping struct {
+	Division    string            `json:"division"`
+	Races       map[string]string `json:"races,omitempty"`
+	ShipModels  map[string]string `json:"ship_models,omitempty"`
+	FleetBuilds map[string]string `json:"fleet_builds,omitempty"`
+	Planets     map[string]string `json:"planets,omitempty"`
+	Fleets      map[string]string `json:"fleets,omitempty"`
+	Battles     map[string]string `json:"battles,omitempty"`
+	// archived ship model id -> archived version -> stored version, the versions follow the stored history
+	ShipModelVersions map[string]map[int]int `json:"ship_model_versions,omitempty"`
+}
+
+// ArchiveConflictError lists the archived ids which are already stored.
+type ArchiveConflictError struct {
+	Conflicts []string `json:"conflicts"`
+}
+
+func (e *ArchiveConflictError) Error() string {
+	return fmt.Sprintf("%d archived ids are already stored: %v", len(e.Conflicts), e.Conflicts)
+}
+
+func NewArchiveMapping(divisionId string) *ArchiveMapping {
+	return &ArchiveMapping{
+		Division:    divisionId,
+		Races:       map[string]string{},
+		ShipModels:  map[string]string{},
+		FleetBuilds: map[string]string{},
+		Planets:     map[string]string{},
+		Fleets:      map[string]string{},
+		Battles:     map[string]string{},
+
+		ShipModelVersions: map[string]map[int]int{},
+	}
+}
+
+// mapId returns the mapped id, ids not in the mapping are kept.
+func mapId(mapping map[string]string, id string) string {
+	if mapped, ok := mapping[id]; ok {
+		return mapped
+	}
+
+	return id
+}
+
+// shipModelVersion returns the stored version of the archived ship model version.
+func (mapping *ArchiveMapping) shipModelVersion(shipModelId string, version int) int {
+	if mapped, ok := mapping.ShipModelVersions[shipModelId][version]; ok {
+		return mapped
+	}
+
+	return version
+}
+
+func (options *ArchiveImportOptions) Validate() error {
+	switch options.Conflict {
+	case "", ARCHIVE_CONFLICT_FAIL, ARCHIVE_CONFLICT_RENAME, ARCHIVE_CONFLICT_OVERWRITE:
+	default:
+		return fmt.Errorf("unknown conflict handling %q", options.Conflict)
+	}
+
+	for from, to := range options.Races {
+		if from == "" || to == "" {
+			return fmt.Errorf("race mapping %q -> %q must not have empty ids", from, to)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the archive can be imported by this version of the server.
+func (archive *DivisionArchive) Validate() error {
+	if archive.Format != ARCHIVE_FORMAT {
+		return fmt.Errorf("unknown archive format %q", archive.Format)
+	}
+	if archive.Version < 1 || archive.Version > ARCHIVE_VERSION {
+		return fmt.Errorf("archive version %d is not supported, the supported versions are 1 to %d", archive.Version, ARCHIVE_VERSION)
+	}
+	if archive.Division == nil || archive.Division.ID == "" {
+		return fmt.Errorf("archive has no division")
+	}
+
+	return archive.Division.Validate()
+}
+
+// Remap replaces the ids of the archive and all the references to them.
+func (archive *DivisionArchive) Remap(mapping *ArchiveMapping) {
+	archive.Division.ID = mapping.Division
+	if archive.TurnState != nil {
+		archive.TurnState.DivisionId = mapping.Division
+	}
+
+	for _, race := range archive.Races {
+		race.ID = mapId(mapping.Races, race.ID)
+	}
+	for _, shipModel := range archive.ShipModels {
+		shipModel.Version = mapping.shipModelVersion(shipModel.ID, shipModel.Version)
+		shipModel.ID = mapId(mapping.ShipModels, shipModel.ID)
+		shipModel.OwnerId = mapId(mapping.Races, shipModel.OwnerId)
+	}
+	for _, fleetBuild := range archive.FleetBuilds {
+		fleetBuild.ID = mapId(mapping.FleetBuilds, fleetBuild.ID)
+		fleetBuild.DivisionId = mapping.Division
+		fleetBuild.RaceId = mapId(mapping.Races, fleetBuild.RaceId)
+	}
+	for _, assignment := range archive.Assignments {
+		assignment.FleetBuildId = mapId(mapping.FleetBuilds, assignment.FleetBuildId)
+		assignment.ShipModelVersion = mapping.shipModelVersion(assignment.ShipModelId, assignment.ShipModelVersion)
+		assignment.ShipModelId = mapId(mapping.ShipModels, assignment.ShipModelId)
+	}
+	for _, planet := range archive.Planets {
+		planet.ID = mapId(mapping.Planets, planet.ID)
+		planet.DivisionId = mapping.Division
+		planet.OwnerId = mapId(mapping.Races, planet.OwnerId)
+	}
+	for _, fleet := range archive.Fleets {
+		remapFleet(fleet, mapping)
+	}
+	for _, divisionFleet := range archive.DivisionFleets {
+		divisionFleet.DivisionId = mapping.Division
+		divisionFleet.UserId = mapId(mapping.Races, divisionFleet.UserId)
+		divisionFleet.FleetId = mapId(mapping.Fleets, divisionFleet.FleetId)
+	}
+	for _, event := range archive.FleetEvents {
+		event.FleetId = mapId(mapping.Fleets, event.FleetId)
+		event.PlanetId = mapId(mapping.Planets, event.PlanetId)
+		event.BattleId = mapId(mapping.Battles, event.BattleId)
+	}
+	for _, battle := range archive.Battles {
+		battle.ID = mapId(mapping.Battles, battle.ID)
+		battle.DivisionId = mapping.Division
+		battle.Location = mapId(mapping.Planets, battle.Location)
+		for _, fleet := range []*Fleet{battle.SideA, battle.SideB, battle.PostSideA, battle.PostSideB} {
+			if fleet != nil {
+				remapFleet(fleet, mapping)
+			}
+		}
+	}
+	for _, rating := range archive.Ratings {
+		rating.DivisionId = mapping.Division
+		rating.RaceId = mapId(mapping.Races, rating.RaceId)
+	}
+	for _, change := range archive.RatingChanges {
+		change.DivisionId = mapping.Division
+		change.RaceId = mapId(mapping.Races, change.RaceId)
+		change.OpponentId = mapId(mapping.Races, change.OpponentId)
+		change.BattleId = mapId(mapping.Battles, change.BattleId)
+	}
+	for _, budget := range archive.Budgets {
+		budget.DivisionId = mapping.Division
+		budget.RaceId = mapId(mapping.Races, budget.RaceId)
+	}
+}
+
+// remapFleet replaces the ids of the fleet, its movement and its ships. The decoded fleets get their ship index.
+func remapFleet(fleet *Fleet, mapping *ArchiveMapping) {
+	fleet.ID = mapId(mapping.Fleets, fleet.ID)
+	fleet.Owner = mapId(mapping.Races, fleet.Owner)
+	if fleet.DivisionId != "" {
+		fleet.DivisionId = mapping.Division
+	}
+	fleet.Location = mapId(mapping.Planets, fleet.Location)
+	if fleet.Movement != nil {
+		fleet.Movement.From = mapId(mapping.Planets, fleet.Movement.From)
+		fleet.Movement.To = mapId(mapping.Planets, fleet.Movement.To)
+	}
+	for _, ship := range append(slices.Clone(fleet.Ships), fleet.Wrecks...) {
+		ship.Owner = mapId(mapping.Races, ship.Owner)
+		ship.ShipModelVersion = mapping.shipModelVersion(ship.ShipModelID, ship.ShipModelVersion)
+		ship.ShipModelID = mapId(mapping.ShipModels, ship.ShipModelID)
+	}
+	fleet.setShips(fleet.Ships)
+}
@@ -1,5 +1,7 @@
 package galaxy
 
+import "fmt"
+
 type Battle struct {
 	ID    string `json:"id"`
 	SideA *Fleet `json:"side_a"`
@@ -8,6 +10,15 @@ type Battle struct {
 	Shots     []*Shot `json:"shots"`
 	PostSideA *Fleet  `json:"post_side_a"`
 	PostSideB *Fleet  `json:"post_side_b"`
+
+	// Resources captured by each side from the destroyed enemy ships
+	LootA float64 `json:"loot_a"`
+	LootB float64 `json:"loot_b"`
+
+	// Where and when the battle happened on the map, empty for battles outside the map
+	DivisionId string  `json:"division_id,omitempty"`
+	Location   string  `json:"location,omitempty"`
+	Time       float64 `json:"time,omitempty"`
 }
 
 // CompareShots compares the shots of this battle with another battle's shots
@@ -18,7 +29,7 @@ func (b *Battle) CompareShots(other *Battle, logger Logger) bool {
 
 	if len(b.Shots) != len(other.Shots) {
 		if logger != nil {
-			logger.Printf("Battle shots count mismatch: %d vs %d", len(b.Shots), len(other.Shots))
+			logger.Info("battle shot count mismatch", "shots", len(b.Shots), "other_shots", len(other.Shots))
 		}
 		return false
 	}
@@ -26,9 +37,9 @@ func (b *Battle) CompareShots(other *Battle, logger Logger) bool {
 	for i, shot := range b.Shots {
 		if !shot.Equal(other.Shots[i]) {
 			if logger != nil {
-				logger.Printf("Shot[%d] mismatch: source=%s->%s (result=%t) does not equal source=%s->%s (result=%t)",
-					i, shot.Source, shot.Destination, shot.Result,
-					other.Shots[i].Source, other.Shots[i].Destination, other.Shots[i].Result)
+				logger.Info("shot mismatch", "index", i,
+					"shot", fmt.Sprintf("%s->%s (result=%t)", shot.Source, shot.Destination, shot.Result),
+					"other_shot", fmt.Sprintf("%s->%s (result=%t)", other.Shots[i].Source, other.Shots[i].Destination, other.Shots[i].Result))
 			}
 			return false
 		}
@@ -36,3 +47,23 @@ func (b *Battle) CompareShots(other *Battle, logger Logger) bool {
 
 	return true
 }
+
+// Survivors returns the number of the ships of both sides which were not destroyed in the battle.
+func (b *Battle) Survivors() (int, int) {
+	return aliveShips(b.PostSideA), aliveShips(b.PostSideB)
+}
+
+func aliveShips(fleet *Fleet) int {
+	if fleet == nil {
+		return 0
+	}
+
+	alive := 0
+	for _, ship := range fleet.Ships {
+		if !ship.Destroyed {
+			alive++
+		}
+	}
+
+	return alive
+}
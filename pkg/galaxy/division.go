@@ -1,5 +1,7 @@
 package galaxy
 
+import "errors"
+
 type Division struct {
 	ID              string `json:"id"`
 	ResourcesAmount int    `json:"resources_amount"`
@@ -7,4 +9,93 @@ type Division struct {
 	TechDefense     int    `json:"tech_defense"`
 	TechEngines     int    `json:"tech_engines"`
 	TechCargo       int    `json:"tech_cargo"`
+
+	// Research cost curves of the division, linear research is used when not set
+	ResearchRules *ResearchRules `json:"research_rules,omitempty"`
+	// Ship model rules of the division, default rules are used when not set
+	ValidationRules *ValidationRules `json:"validation_rules,omitempty"`
+	// Turn length in seconds, the turns are advanced only manually when 0
+	TurnDuration int `json:"turn_duration,omitempty"`
+}
+
+// Validate checks the division rules. Technology levels of 0 are not configured
+// and fall back to the default level 1, other values form the tech floor of the division.
+func (division *Division) Validate() error {
+	if division.ResourcesAmount < 0 {
+		return errors.New("ResourcesAmount must not be negative")
+	}
+
+	if division.TechAttack < 0 {
+		return errors.New("TechAttack must not be negative")
+	}
+
+	if division.TechDefense < 0 {
+		return errors.New("TechDefense must not be negative")
+	}
+
+	if division.TechEngines < 0 {
+		return errors.New("TechEngines must not be negative")
+	}
+
+	if division.TechCargo < 0 {
+		return errors.New("TechCargo must not be negative")
+	}
+
+	if division.TurnDuration < 0 {
+		return errors.New("TurnDuration must not be negative")
+	}
+
+	if division.ResearchRules != nil {
+		if err := division.ResearchRules.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if division.ValidationRules != nil {
+		if err := division.ValidationRules.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ShipModelRules returns the rules ship models used in the division must follow.
+func (division *Division) ShipModelRules() *ValidationRules {
+	if division == nil || division.ValidationRules == nil {
+		return DefaultValidationRules()
+	}
+
+	return division.ValidationRules
+}
+
+// BaseTechnologies returns the technology levels every fleet build of the division starts from.
+func (division *Division) BaseTechnologies() *Technologies {
+	tech := NewTechnologies()
+	if division == nil {
+		return tech
+	}
+
+	tech.Attack = max(tech.Attack, float64(division.TechAttack))
+	tech.Defense = max(tech.Defense, float64(division.TechDefense))
+	tech.Engine = max(tech.Engine, float64(division.TechEngines))
+	tech.Cargo = max(tech.Cargo, float64(division.TechCargo))
+
+	return tech
+}
+
+// ResourcesToReachLevel answers how many research resources a fleet build of the division
+// has to spend on the technology to reach the given level.
+func (division *Division) ResourcesToReachLevel(technology string, level float64) (float64, error) {
+	rules := division.ResearchRules
+	if rules == nil {
+		rules = NewLinearResearchRules()
+	}
+
+	curve, err := rules.Curve(technology)
+	if err != nil {
+		return 0, err
+	}
+
+	return curve.ResourcesForGain(level - division.BaseTechnologies().Level(technology))
 }
@@ -0,0 +1,60 @@
+package galaxy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDivisionBaseTechnologies(t *testing.T) {
+	tests := []struct {
+		name     string
+		division *Division
+		expected *Technologies
+	}{
+		{
+			name:     "nil division gives default levels",
+			division: nil,
+			expected: &Technologies{Attack: 1, Defense: 1, Engine: 1, Cargo: 1},
+		},
+		{
+			name:     "not configured levels fall back to default",
+			division: &Division{ID: "alpha"},
+			expected: &Technologies{Attack: 1, Defense: 1, Engine: 1, Cargo: 1},
+		},
+		{
+			name:     "configured tech floor",
+			division: &Division{ID: "beta", TechAttack: 3, TechDefense: 2, TechEngines: 1, TechCargo: 4},
+			expected: &Technologies{Attack: 3, Defense: 2, Engine: 1, Cargo: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tech := tt.division.BaseTechnologies()
+			if !reflect.DeepEqual(tt.expected, tech) {
+				t.Errorf("expected %+v, got %+v", tt.expected, tech)
+			}
+		})
+	}
+}
+
+func TestDivisionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		division  Division
+		wantError bool
+	}{
+		{name: "valid", division: Division{ID: "alpha", ResourcesAmount: 500, TechAttack: 2, TechDefense: 1}, wantError: false},
+		{name: "negative resources", division: Division{ID: "alpha", ResourcesAmount: -1}, wantError: true},
+		{name: "negative tech", division: Division{ID: "alpha", TechEngines: -2}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.division.Validate()
+			if (err != nil) != tt.wantError {
+				t.Errorf("Validate() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package galaxy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Resources produced every turn by one unit of planet industry and population
+const (
+	PRODUCTION_PER_INDUSTRY   = 1.0
+	PRODUCTION_PER_POPULATION = 0.1
+)
+
+// Budget entry kinds
+const (
+	BUDGET_INCOME   = "income"
+	BUDGET_SPENDING = "spending"
+)
+
+// ErrInsufficientResources is returned when the balance of a budget does not cover a spending.
+var ErrInsufficientResources = errors.New("not enough resources")
+
+// Production returns the resources the planet produces in one turn.
+func (planet *Planet) Production() float64 {
+	return planet.Industry*PRODUCTION_PER_INDUSTRY + planet.Population*PRODUCTION_PER_POPULATION
+}
+
+// BudgetEntry is one income or spending of a race.
+type BudgetEntry struct {
+	Turn        int     `json:"turn"`
+	Kind        string  `json:"kind"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+// Budget holds the spendable resources of a race in a division.
+type Budget struct {
+	DivisionId string         `json:"division_id"`
+	RaceId     string         `json:"race_id"`
+	Balance    float64        `json:"balance"`
+	Entries    []*BudgetEntry `json:"entries"`
+}
+
+func (budget *Budget) Credit(turn int, amount float64, description string) {
+	budget.Balance += amount
+	budget.Entries = append(budget.Entries, &BudgetEntry{Turn: turn, Kind: BUDGET_INCOME, Amount: amount, Description: description})
+}
+
+// Debit spends the resources, it fails when the balance is not enough.
+func (budget *Budget) Debit(turn int, amount float64, description string) error {
+	if amount > budget.Balance {
+		return fmt.Errorf("%w: %s costs %g resources, only %g available", ErrInsufficientResources, description, amount, budget.Balance)
+	}
+
+	budget.Balance -= amount
+	budget.Entries = append(budget.Entries, &BudgetEntry{Turn: turn, Kind: BUDGET_SPENDING, Amount: amount, Description: description})
+
+	return nil
+}
+
+// TotalIncome returns all the resources the race has received, this is the limit of its fleet builds.
+func (budget *Budget) TotalIncome() int {
+	total := 0.0
+	for _, entry := range budget.Entries {
+		if entry.Kind == BUDGET_INCOME {
+			total += entry.Amount
+		}
+	}
+
+	return int(math.Floor(total))
+}
+
+// EconomyReport shows the income and the spending of a race in a division.
+type EconomyReport struct {
+	DivisionId string  `json:"division_id"`
+	RaceId     string  `json:"race_id"`
+	Balance    float64 `json:"balance"`
+	// resources the owned planets produce every turn
+	IncomePerTurn float64        `json:"income_per_turn"`
+	Planets       []*Planet      `json:"planets"`
+	History       []*BudgetEntry `json:"history"`
+}
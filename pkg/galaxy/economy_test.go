@@ -0,0 +1,46 @@
+package galaxy
+
+import "testing"
+
+func TestPlanetProduction(t *testing.T) {
+	tests := []struct {
+		name     string
+		planet   Planet
+		expected float64
+	}{
+		{name: "empty planet", planet: Planet{}, expected: 0},
+		{name: "industry only", planet: Planet{Industry: 20}, expected: 20},
+		{name: "population and industry", planet: Planet{Population: 100, Industry: 20}, expected: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.planet.Production(); got != tt.expected {
+				t.Errorf("Production() = %v; want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBudget(t *testing.T) {
+	budget := &Budget{}
+	budget.Credit(0, 100, "division resources")
+	budget.Credit(1, 10.5, "production")
+
+	if err := budget.Debit(1, 60, "research"); err != nil {
+		t.Fatalf("Debit() error = %v", err)
+	}
+	if err := budget.Debit(1, 60, "fleet"); err == nil {
+		t.Errorf("expected error when the balance is not enough")
+	}
+
+	if budget.Balance != 50.5 {
+		t.Errorf("expected balance 50.5, got %v", budget.Balance)
+	}
+	if budget.TotalIncome() != 110 {
+		t.Errorf("expected total income 110, got %d", budget.TotalIncome())
+	}
+	if len(budget.Entries) != 3 || budget.Entries[2].Kind != BUDGET_SPENDING {
+		t.Errorf("unexpected entries %+v", budget.Entries)
+	}
+}
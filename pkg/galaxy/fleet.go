@@ -5,9 +5,9 @@ import (
 	"math"
 )
 
-// Logger interface for logging ship comparison mismatches
+// Logger logs the mismatches of the ship and shot comparisons, *slog.Logger implements it.
 type Logger interface {
-	Printf(format string, v ...interface{})
+	Info(msg string, args ...any)
 }
 
 type Fleet struct {
@@ -15,6 +15,13 @@ type Fleet struct {
 	Ships []*Ship `json:"ships"`
 	Owner string  `json:"owner"` // owner race id
 
+	// Position on the map of the division
+	DivisionId string         `json:"division_id,omitempty"`
+	Location   string         `json:"location,omitempty"` // planet id, empty while moving or not deployed
+	Movement   *FleetMovement `json:"movement,omitempty"`
+	// Ships destroyed in the battles, they can be repaired
+	Wrecks []*Ship `json:"wrecks,omitempty"`
+
 	shipMap map[string]*Ship
 }
 
@@ -57,7 +64,7 @@ func (fleet *Fleet) EqualShips(other *Fleet, logger Logger) bool {
 
 	if len(fleet.Ships) != len(other.Ships) {
 		if logger != nil {
-			logger.Printf("Fleet ship count mismatch: %d vs %d", len(fleet.Ships), len(other.Ships))
+			logger.Info("fleet ship count mismatch", "ships", len(fleet.Ships), "other_ships", len(other.Ships))
 		}
 		return false
 	}
@@ -65,7 +72,7 @@ func (fleet *Fleet) EqualShips(other *Fleet, logger Logger) bool {
 	for i, ship := range fleet.Ships {
 		if !ship.EqualFields(other.Ships[i]) {
 			if logger != nil {
-				logger.Printf("Ship[%d] mismatch: ship ID=%s does not equal ship ID=%s", i, ship.ID, other.Ships[i].ID)
+				logger.Info("ship mismatch", "index", i, "ship_id", ship.ID, "other_ship_id", other.Ships[i].ID)
 			}
 			return false
 		}
@@ -77,3 +84,70 @@ func (fleet *Fleet) EqualShips(other *Fleet, logger Logger) bool {
 func (fleet *Fleet) GetShipById(id string) *Ship {
 	return fleet.shipMap[id]
 }
+
+// Snapshot returns a copy of the fleet with copies of its ships, it is not changed when the fleet changes.
+func (fleet *Fleet) Snapshot() *Fleet {
+	ships := make([]*Ship, len(fleet.Ships))
+	for i, ship := range fleet.Ships {
+		shipCopy := *ship
+		ships[i] = &shipCopy
+	}
+
+	snapshot := NewFleet(ships)
+	snapshot.ID = fleet.ID
+	snapshot.Owner = fleet.Owner
+	snapshot.DivisionId = fleet.DivisionId
+	snapshot.Location = fleet.Location
+
+	return snapshot
+}
+
+// Copy returns a copy of the fleet with copies of its ships, wrecks and movement, it can be changed without changing this fleet.
+func (fleet *Fleet) Copy() *Fleet {
+	fleetCopy := fleet.Snapshot()
+	if fleet.Wrecks != nil {
+		fleetCopy.Wrecks = make([]*Ship, len(fleet.Wrecks))
+		for i, wreck := range fleet.Wrecks {
+			wreckCopy := *wreck
+			fleetCopy.Wrecks[i] = &wreckCopy
+		}
+	}
+	if fleet.Movement != nil {
+		movement := *fleet.Movement
+		fleetCopy.Movement = &movement
+	}
+
+	return fleetCopy
+}
+
+// ApplyBattle keeps the ships which survived the battle, given by the post battle state of the fleet.
+// The destroyed ships become wrecks. Returns the number of lost ships.
+func (fleet *Fleet) ApplyBattle(post *Fleet) int {
+	survivors := make([]*Ship, 0, len(post.Ships))
+	for _, ship := range post.Ships {
+		shipCopy := *ship
+		if ship.Destroyed {
+			fleet.Wrecks = append(fleet.Wrecks, &shipCopy)
+		} else {
+			survivors = append(survivors, &shipCopy)
+		}
+	}
+
+	lost := len(fleet.Ships) - len(survivors)
+	fleet.setShips(survivors)
+
+	return lost
+}
+
+// AddShips reinforces the fleet with new ships.
+func (fleet *Fleet) AddShips(ships []*Ship) {
+	fleet.setShips(append(fleet.Ships, ships...))
+}
+
+func (fleet *Fleet) setShips(ships []*Ship) {
+	fleet.Ships = ships
+	fleet.shipMap = make(map[string]*Ship)
+	for _, ship := range ships {
+		fleet.shipMap[ship.ID] = ship
+	}
+}
@@ -1,6 +1,9 @@
 package galaxy
 
-import "fmt"
+import (
+	"math"
+	"slices"
+)
 
 type ShipModelAssignment struct {
 	ShipModel ShipModel
@@ -14,6 +17,9 @@ type FleetBuildStatistics struct {
 	UsedResourcesForTechnologies int `json:"used_resources_for_technologies"`
 	RemainingResources           int `json:"remaining_resources"`
 	ExceedingResources           int `json:"exceeding_resources"`
+
+	// Effective technology levels: the division baseline with the research applied
+	Technologies *Technologies `json:"technologies"`
 }
 
 func (fleetBuild *FleetBuild) CalculateStatistics(maxResources int) FleetBuildStatistics {
@@ -22,7 +28,7 @@ func (fleetBuild *FleetBuild) CalculateStatistics(maxResources int) FleetBuildSt
 		usedForShips += int(assignment.ShipModel.CalculateTotalMass()) * assignment.Amount
 	}
 
-	usedForTech := int(fleetBuild.AttackResources + fleetBuild.DefenseResources + fleetBuild.EngineResources + fleetBuild.CargoResources)
+	usedForTech := int(fleetBuild.ResearchCost())
 
 	usedResources := usedForShips + usedForTech
 	remaining := maxResources - usedResources
@@ -39,6 +45,7 @@ func (fleetBuild *FleetBuild) CalculateStatistics(maxResources int) FleetBuildSt
 		UsedResourcesForTechnologies: usedForTech,
 		RemainingResources:           remaining,
 		ExceedingResources:           exceeding,
+		Technologies:                 fleetBuild.CalculateTechnologies(),
 	}
 }
 
@@ -56,28 +63,151 @@ type FleetBuild struct {
 	EngineResources  float64 `json:"engine_resources"`
 	CargoResources   float64 `json:"cargo_resources"`
 
+	// Researched nodes of the tech tree
+	ResearchedNodes []string `json:"researched_nodes"`
+
 	// not stored to DB directly
 
 	AssignedShipModels []ShipModelAssignment
 	UsedResources      float64
+	// Technology levels of the division, the research starts from them. Default levels are used when nil.
+	BaseTechnologies *Technologies `json:"-"`
+	// Research cost curves of the division. Linear research is used when nil.
+	ResearchRules *ResearchRules `json:"-"`
+	// Ship model rules of the division. Default rules are used when nil.
+	ValidationRules *ValidationRules `json:"-"`
+	// The default tech tree is used when nil.
+	TechTree *TechTree `json:"-"`
 }
 
-func (fleetBuild *FleetBuild) CalculateShipTech(shipModel *ShipModel) ShipTech {
+func (fleetBuild *FleetBuild) techTree() *TechTree {
+	if fleetBuild.TechTree == nil {
+		return DefaultTechTree()
+	}
+
+	return fleetBuild.TechTree
+}
+
+// ValidateResearchedNodes checks the researched nodes against the tech tree.
+func (fleetBuild *FleetBuild) ValidateResearchedNodes() error {
+	return fleetBuild.techTree().ValidateResearched(fleetBuild.ResearchedNodes)
+}
+
+// UnlockedComponents returns the ship components the ship models of this fleet build may use.
+func (fleetBuild *FleetBuild) UnlockedComponents() ComponentSet {
+	return fleetBuild.techTree().UnlockedComponents(fleetBuild.ResearchedNodes)
+}
+
+// ResearchCost returns the resources spent on the technologies and the researched nodes of the tech tree.
+func (fleetBuild *FleetBuild) ResearchCost() float64 {
+	return fleetBuild.AttackResources + fleetBuild.DefenseResources + fleetBuild.EngineResources + fleetBuild.CargoResources +
+		fleetBuild.techTree().ResearchCost(fleetBuild.ResearchedNodes)
+}
+
+// NodeCost returns the resources needed to research the node of the tech tree of this fleet build.
+func (fleetBuild *FleetBuild) NodeCost(id string) float64 {
+	return fleetBuild.techTree().ResearchCost([]string{id})
+}
+
+// ValidateShipModel checks the ship model against the division rules and the components unlocked by the fleet build.
+func (fleetBuild *FleetBuild) ValidateShipModel(shipModel *ShipModel) error {
+	return shipModel.ValidateModel(fleetBuild.ValidationRules, fleetBuild.UnlockedComponents())
+}
+
+// Copy returns a copy of the fleet build which can be changed without changing this fleet build.
+func (fleetBuild *FleetBuild) Copy() *FleetBuild {
+	fleetBuildCopy := *fleetBuild
+	fleetBuildCopy.ResearchedNodes = slices.Clone(fleetBuild.ResearchedNodes)
+	fleetBuildCopy.AssignedShipModels = slices.Clone(fleetBuild.AssignedShipModels)
+
+	return &fleetBuildCopy
+}
+
+// ApplyDivision sets the technology baseline, the research rules and the ship model rules
+// of the division the fleet build belongs to.
+func (fleetBuild *FleetBuild) ApplyDivision(division *Division) {
+	fleetBuild.BaseTechnologies = division.BaseTechnologies()
+	fleetBuild.ResearchRules = division.ResearchRules
+	fleetBuild.ValidationRules = division.ShipModelRules()
+}
+
+// CalculateTechnologies returns the base technologies of the fleet build improved by its research.
+func (fleetBuild *FleetBuild) CalculateTechnologies() *Technologies {
 	tech := NewTechnologies()
-	tech.Research(fleetBuild.AttackResources, fleetBuild.DefenseResources, fleetBuild.EngineResources, fleetBuild.CargoResources)
+	if fleetBuild.BaseTechnologies != nil {
+		*tech = *fleetBuild.BaseTechnologies
+	}
+	tech.ResearchWithRules(fleetBuild.ResearchRules, fleetBuild.AttackResources, fleetBuild.DefenseResources, fleetBuild.EngineResources, fleetBuild.CargoResources)
+
+	return tech
+}
 
-	return shipModel.CalculateShipTech(tech)
+// ResearchResources returns the resources spent on the technology with the given name.
+func (fleetBuild *FleetBuild) ResearchResources(technology string) float64 {
+	switch technology {
+	case TECHNOLOGY_ATTACK:
+		return fleetBuild.AttackResources
+	case TECHNOLOGY_DEFENSE:
+		return fleetBuild.DefenseResources
+	case TECHNOLOGY_ENGINE:
+		return fleetBuild.EngineResources
+	case TECHNOLOGY_CARGO:
+		return fleetBuild.CargoResources
+	}
+
+	return 0
+}
+
+// PreviewResearch shows for every technology the gain of spending extraResources more on it.
+func (fleetBuild *FleetBuild) PreviewResearch(extraResources float64) []ResearchPreview {
+	rules := fleetBuild.ResearchRules
+	if rules == nil {
+		rules = NewLinearResearchRules()
+	}
+	base := fleetBuild.BaseTechnologies
+	if base == nil {
+		base = NewTechnologies()
+	}
+
+	previews := make([]ResearchPreview, 0, len(TechnologyNames))
+	for _, technology := range TechnologyNames {
+		curve, _ := rules.Curve(technology)
+		spent := fleetBuild.ResearchResources(technology)
+		baseLevel := base.Level(technology)
+		level := baseLevel + curve.LevelGain(spent)
+		previewLevel := baseLevel + curve.LevelGain(spent+extraResources)
+
+		preview := ResearchPreview{
+			Technology:     technology,
+			SpentResources: spent,
+			Level:          level,
+			ExtraResources: extraResources,
+			PreviewLevel:   previewLevel,
+			MarginalGain:   previewLevel - level,
+		}
+
+		nextLevel := math.Floor(level) + 1
+		if needed, err := curve.ResourcesForGain(nextLevel - baseLevel); err == nil {
+			toNextLevel := max(needed-spent, 0)
+			preview.ResourcesToNextLevel = &toNextLevel
+		}
+
+		previews = append(previews, preview)
+	}
+
+	return previews
+}
+
+func (fleetBuild *FleetBuild) CalculateShipTech(shipModel *ShipModel) ShipTech {
+	return shipModel.CalculateShipTech(fleetBuild.CalculateTechnologies())
 }
 
 func (fleetBuild *FleetBuild) CalculateAllShipTechs() []*ShipTech {
-	tech := NewTechnologies()
-	tech.Research(fleetBuild.AttackResources, fleetBuild.DefenseResources, fleetBuild.EngineResources, fleetBuild.CargoResources)
+	tech := fleetBuild.CalculateTechnologies()
 
 	var rez = []*ShipTech{}
 
 	for _, assignedShipModel := range fleetBuild.AssignedShipModels {
-		fmt.Printf("Ship model: %v\n", assignedShipModel)
-
 		shipTech := assignedShipModel.ShipModel.CalculateShipTech(tech)
 		rez = append(rez, &shipTech)
 	}
@@ -283,6 +283,71 @@ func TestCalculateShipTech_GlassPerfo(t *testing.T) {
 	}
 }
 
+func TestCalculateShipTech_Cargo(t *testing.T) {
+	fleetBuild := &FleetBuild{
+		CargoResources: 100, // +1 tech => 2.0
+	}
+
+	shipModel := &ShipModel{
+		Guns:        1,
+		OneGunMass:  2,
+		DefenseMass: 0,
+		EngineMass:  4,
+		CargoMass:   10,
+	}
+
+	result := fleetBuild.CalculateShipTech(shipModel)
+
+	expected := ShipTech{
+		Guns:          1,
+		Attack:        2,
+		Defense:       0,
+		Speed:         0.25, // 4 * 1.0 / 16
+		CargoCapacity: 20,   // 10 * 2.0
+		Mass:          16,
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("CalculateShipTech with cargo:\nexpected %+v\ngot      %+v", expected, result)
+	}
+}
+
+func TestCalculateShipTech_DivisionBaseline(t *testing.T) {
+	division := &Division{TechAttack: 3, TechDefense: 2, TechEngines: 2, TechCargo: 1}
+
+	fleetBuild := &FleetBuild{
+		AttackResources:  100, // 3.0 + 1 => 4.0
+		BaseTechnologies: division.BaseTechnologies(),
+	}
+
+	shipModel := &ShipModel{
+		Guns:        1,
+		OneGunMass:  2,
+		DefenseMass: 4,
+		EngineMass:  10,
+	}
+
+	result := fleetBuild.CalculateShipTech(shipModel)
+
+	expected := ShipTech{
+		Guns:    1,
+		Attack:  8, // 2 * 4.0
+		Defense: 2, // 4 * 2.0 / sqrt(16)
+		Speed:   1.25,
+		Mass:    16,
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("CalculateShipTech with division baseline:\nexpected %+v\ngot      %+v", expected, result)
+	}
+
+	statistics := fleetBuild.CalculateStatistics(500)
+	expectedTech := &Technologies{Attack: 4, Defense: 2, Engine: 2, Cargo: 1}
+	if !reflect.DeepEqual(expectedTech, statistics.Technologies) {
+		t.Errorf("Statistics technologies: expected %+v, got %+v", expectedTech, statistics.Technologies)
+	}
+}
+
 func TestCalculateAllShipTech_Simple(t *testing.T) {
 	fleetBuild := &FleetBuild{
 		AssignedShipModels: []ShipModelAssignment{
@@ -3,10 +3,12 @@ package galaxy
 type FleetBuildToShipModel struct {
 	// stored to db
 
-	FleetBuildID string  `json:"fleet_build_id"`
-	ShipModelID  string  `json:"ship_model_id"`
-	Amount       int     `json:"amount"`
-	ResultMass   float64 `json:"result_mass"`
+	FleetBuildID string `json:"fleet_build_id"`
+	ShipModelID  string `json:"ship_model_id"`
+	// The assigned version of the ship model, the latest version is assigned when 0
+	ShipModelVersion int     `json:"ship_model_version"`
+	Amount           int     `json:"amount"`
+	ResultMass       float64 `json:"result_mass"`
 
 	// not stored to DB directly
 	ShipModel *ShipModel
@@ -0,0 +1,27 @@
+package galaxy
+
+// Fleet history event types
+const (
+	FLEET_EVENT_BUILT      = "built"
+	FLEET_EVENT_REINFORCED = "reinforced"
+	FLEET_EVENT_DEPLOYED   = "deployed"
+	FLEET_EVENT_MOVED      = "moved"
+	FLEET_EVENT_ARRIVED    = "arrived"
+	FLEET_EVENT_BATTLE     = "battle"
+	FLEET_EVENT_REPAIRED   = "repaired"
+	FLEET_EVENT_REFITTED   = "refitted"
+)
+
+// FleetEvent is an entry of the fleet history log.
+type FleetEvent struct {
+	FleetId string  `json:"fleet_id"`
+	Type    string  `json:"type"`
+	MapTime float64 `json:"map_time"`
+	// ships in the fleet after the event
+	Ships       int    `json:"ships"`
+	Added       int    `json:"added,omitempty"`
+	Lost        int    `json:"lost,omitempty"`
+	PlanetId    string `json:"planet_id,omitempty"`
+	BattleId    string `json:"battle_id,omitempty"`
+	Description string `json:"description,omitempty"`
+}
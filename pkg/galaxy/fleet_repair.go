@@ -0,0 +1,153 @@
+package galaxy
+
+import "errors"
+
+// Resources paid for one unit of the ship mass. A refit pays the added mass like a new ship
+// and the refit fee on the whole new mass.
+const (
+	BUILD_COST_PER_MASS  = 1.0
+	REPAIR_COST_PER_MASS = 0.5
+	REFIT_COST_PER_MASS  = 0.25
+)
+
+// Fleet work kinds
+const (
+	FLEET_WORK_REPAIR = "repair"
+	FLEET_WORK_REFIT  = "refit"
+)
+
+// ShipWork is the repair or the refit of one ship.
+type ShipWork struct {
+	ShipID      string   `json:"ship_id"`
+	Name        string   `json:"name"`
+	ShipModelID string   `json:"ship_model_id"`
+	FromVersion int      `json:"from_version"`
+	ToVersion   int      `json:"to_version"`
+	Tech        ShipTech `json:"tech"`
+	// tech of the refitted ship, the repaired ships keep their tech
+	NewTech *ShipTech `json:"new_tech,omitempty"`
+	Cost    float64   `json:"cost"`
+}
+
+// FleetWork is the repair or the refit of a fleet, the preview and the result of the work.
+type FleetWork struct {
+	FleetId   string      `json:"fleet_id"`
+	Kind      string      `json:"kind"`
+	Ships     []*ShipWork `json:"ships"`
+	TotalCost float64     `json:"total_cost"`
+	// budget balance of the race before the work
+	Balance    float64 `json:"balance"`
+	Affordable bool    `json:"affordable"`
+}
+
+// NewFleetWork sums the cost of the ship works.
+func NewFleetWork(fleet *Fleet, kind string, ships []*ShipWork, balance float64) *FleetWork {
+	total := 0.0
+	for _, ship := range ships {
+		total += ship.Cost
+	}
+
+	return &FleetWork{
+		FleetId:    fleet.ID,
+		Kind:       kind,
+		Ships:      ships,
+		TotalCost:  total,
+		Balance:    balance,
+		Affordable: total <= balance,
+	}
+}
+
+// PlanRepair returns the repair of every wreck of the fleet, the cost is proportional to the ship mass.
+func (fleet *Fleet) PlanRepair() []*ShipWork {
+	works := make([]*ShipWork, 0, len(fleet.Wrecks))
+	for _, wreck := range fleet.Wrecks {
+		works = append(works, &ShipWork{
+			ShipID:      wreck.ID,
+			Name:        wreck.Name,
+			ShipModelID: wreck.ShipModelID,
+			FromVersion: wreck.ShipModelVersion,
+			ToVersion:   wreck.ShipModelVersion,
+			Tech:        wreck.Tech,
+			Cost:        wreck.Tech.Mass * REPAIR_COST_PER_MASS,
+		})
+	}
+
+	return works
+}
+
+// Repair returns the wrecks to the fleet. Returns the number of repaired ships.
+func (fleet *Fleet) Repair() int {
+	repaired := make([]*Ship, 0, len(fleet.Wrecks))
+	for _, wreck := range fleet.Wrecks {
+		wreck.Destroyed = false
+		repaired = append(repaired, wreck)
+	}
+
+	fleet.Wrecks = nil
+	fleet.AddShips(repaired)
+
+	return len(repaired)
+}
+
+// PlanRefit returns the refit of the fleet ships to the given ship model versions and techs.
+// The ships which already have the version and the tech are skipped.
+func (fleet *Fleet) PlanRefit(shipModel func(ship *Ship) *ShipModel, shipTech func(shipModel *ShipModel) ShipTech) []*ShipWork {
+	works := []*ShipWork{}
+	for _, ship := range fleet.Ships {
+		target := shipModel(ship)
+		if target == nil {
+			continue
+		}
+
+		tech := shipTech(target)
+		if target.Version == ship.ShipModelVersion && tech.Equal(&ship.Tech) {
+			continue
+		}
+
+		works = append(works, &ShipWork{
+			ShipID:      ship.ID,
+			Name:        target.Name,
+			ShipModelID: target.ID,
+			FromVersion: ship.ShipModelVersion,
+			ToVersion:   target.Version,
+			Tech:        ship.Tech,
+			NewTech:     &tech,
+			Cost:        refitCost(ship.Tech.Mass, tech.Mass),
+		})
+	}
+
+	return works
+}
+
+// refitCost charges the added mass at the build price, a lighter version is not refunded.
+func refitCost(mass float64, newMass float64) float64 {
+	return max(newMass-mass, 0)*BUILD_COST_PER_MASS + newMass*REFIT_COST_PER_MASS
+}
+
+// CheckRefit checks the planned refits can be applied to the ships of the fleet.
+func (fleet *Fleet) CheckRefit(works []*ShipWork) error {
+	for _, work := range works {
+		ship := fleet.GetShipById(work.ShipID)
+		if ship == nil || work.NewTech == nil {
+			return errors.New("ship " + work.ShipID + " can not be refitted")
+		}
+	}
+
+	return nil
+}
+
+// Refit applies the planned refits to the ships of the fleet, no ship is changed when a refit can not be applied.
+func (fleet *Fleet) Refit(works []*ShipWork) error {
+	if err := fleet.CheckRefit(works); err != nil {
+		return err
+	}
+
+	for _, work := range works {
+		ship := fleet.GetShipById(work.ShipID)
+		ship.Name = work.Name
+		ship.ShipModelVersion = work.ToVersion
+		ship.Tech = *work.NewTech
+	}
+
+	return nil
+}
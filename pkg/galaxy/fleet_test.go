@@ -0,0 +1,141 @@
+package galaxy
+
+import "testing"
+
+func TestFleet_ApplyBattle(t *testing.T) {
+	tests := []struct {
+		name      string
+		destroyed []bool
+		wantShips []string
+		wantLost  int
+	}{
+		{name: "no losses", destroyed: []bool{false, false}, wantShips: []string{"s0", "s1"}, wantLost: 0},
+		{name: "one ship destroyed", destroyed: []bool{true, false}, wantShips: []string{"s1"}, wantLost: 1},
+		{name: "fleet destroyed", destroyed: []bool{true, true}, wantShips: []string{}, wantLost: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fleet := NewFleet([]*Ship{{ID: "s0", Owner: "a"}, {ID: "s1", Owner: "a"}})
+			fleet.ID = "f1"
+
+			post := fleet.Snapshot()
+			for i, destroyed := range tt.destroyed {
+				post.Ships[i].Destroyed = destroyed
+			}
+			if fleet.Ships[0].Destroyed {
+				t.Fatalf("snapshot must not share the ships with the fleet")
+			}
+
+			lost := fleet.ApplyBattle(post)
+			if lost != tt.wantLost || len(fleet.Wrecks) != tt.wantLost {
+				t.Errorf("ApplyBattle() lost = %d, wrecks %d, want %d", lost, len(fleet.Wrecks), tt.wantLost)
+			}
+			if len(fleet.Ships) != len(tt.wantShips) {
+				t.Fatalf("expected %d ships, got %d", len(tt.wantShips), len(fleet.Ships))
+			}
+			for i, id := range tt.wantShips {
+				if fleet.Ships[i].ID != id || fleet.GetShipById(id) == nil {
+					t.Errorf("expected ship %s at %d, got %s", id, i, fleet.Ships[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFleet_AddShips(t *testing.T) {
+	fleet := NewFleet([]*Ship{{ID: "s0"}})
+	fleet.AddShips([]*Ship{{ID: "s1"}, {ID: "s2"}})
+
+	if len(fleet.Ships) != 3 || fleet.GetShipById("s2") == nil {
+		t.Errorf("expected 3 ships, got %+v", fleet.Ships)
+	}
+}
+
+func TestFleet_Repair(t *testing.T) {
+	fleet := NewFleet([]*Ship{{ID: "s0", Tech: ShipTech{Mass: 10}}, {ID: "s1", Tech: ShipTech{Mass: 4}}})
+	post := fleet.Snapshot()
+	post.Ships[1].Destroyed = true
+	fleet.ApplyBattle(post)
+
+	works := fleet.PlanRepair()
+	if len(works) != 1 || works[0].ShipID != "s1" || works[0].Cost != 4*REPAIR_COST_PER_MASS {
+		t.Fatalf("unexpected repair plan %+v", works)
+	}
+
+	if repaired := fleet.Repair(); repaired != 1 {
+		t.Errorf("Repair() = %d, want 1", repaired)
+	}
+	if len(fleet.Ships) != 2 || len(fleet.Wrecks) != 0 || fleet.GetShipById("s1") == nil || fleet.GetShipById("s1").Destroyed {
+		t.Errorf("expected the wreck back in the fleet, got ships %+v, wrecks %+v", fleet.Ships, fleet.Wrecks)
+	}
+}
+
+func TestFleet_PlanRefit(t *testing.T) {
+	shipModel := &ShipModel{ID: "sm1", Version: 2, Name: "Fighter II"}
+	newTech := ShipTech{Attack: 2, Mass: 8}
+
+	tests := []struct {
+		name      string
+		ship      *Ship
+		wantRefit bool
+		wantCost  float64
+	}{
+		{name: "older version", ship: &Ship{ID: "s0", ShipModelID: "sm1", ShipModelVersion: 1, Tech: newTech}, wantRefit: true, wantCost: 8 * REFIT_COST_PER_MASS},
+		{name: "older tech", ship: &Ship{ID: "s0", ShipModelID: "sm1", ShipModelVersion: 2, Tech: ShipTech{Attack: 1, Mass: 8}}, wantRefit: true, wantCost: 8 * REFIT_COST_PER_MASS},
+		// the 5 added mass costs as much as building it
+		{name: "heavier version", ship: &Ship{ID: "s0", ShipModelID: "sm1", ShipModelVersion: 1, Tech: ShipTech{Attack: 1, Mass: 3}}, wantRefit: true, wantCost: 5*BUILD_COST_PER_MASS + 8*REFIT_COST_PER_MASS},
+		{name: "lighter version", ship: &Ship{ID: "s0", ShipModelID: "sm1", ShipModelVersion: 1, Tech: ShipTech{Attack: 1, Mass: 12}}, wantRefit: true, wantCost: 8 * REFIT_COST_PER_MASS},
+		{name: "up to date", ship: &Ship{ID: "s0", ShipModelID: "sm1", ShipModelVersion: 2, Tech: newTech}},
+		{name: "unknown ship model", ship: &Ship{ID: "s0", ShipModelID: "unknown", ShipModelVersion: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fleet := NewFleet([]*Ship{tt.ship})
+			works := fleet.PlanRefit(
+				func(ship *Ship) *ShipModel {
+					if ship.ShipModelID == shipModel.ID {
+						return shipModel
+					}
+					return nil
+				},
+				func(*ShipModel) ShipTech { return newTech },
+			)
+
+			if (len(works) == 1) != tt.wantRefit {
+				t.Fatalf("PlanRefit() = %+v, want refit %v", works, tt.wantRefit)
+			}
+			if !tt.wantRefit {
+				return
+			}
+			if works[0].Cost != tt.wantCost {
+				t.Errorf("unexpected refit cost %v", works[0].Cost)
+			}
+			if err := fleet.Refit(works); err != nil {
+				t.Fatalf("Refit() error = %v", err)
+			}
+			if ship := fleet.Ships[0]; ship.ShipModelVersion != 2 || ship.Name != "Fighter II" || !ship.Tech.Equal(&newTech) {
+				t.Errorf("unexpected refitted ship %+v", ship)
+			}
+		})
+	}
+}
+
+func TestFleet_CheckRefit(t *testing.T) {
+	fleet := NewFleet([]*Ship{{ID: "s0", ShipModelID: "sm1", ShipModelVersion: 1}})
+	works := []*ShipWork{
+		{ShipID: "s0", ToVersion: 2, NewTech: &ShipTech{Attack: 1}},
+		{ShipID: "missing", ToVersion: 2, NewTech: &ShipTech{Attack: 1}},
+	}
+
+	if err := fleet.CheckRefit(works); err == nil {
+		t.Fatalf("expected error for a ship which is not in the fleet")
+	}
+	if err := fleet.Refit(works); err == nil || fleet.Ships[0].ShipModelVersion != 1 {
+		t.Errorf("expected the failed refit to keep the ships, got %+v", fleet.Ships[0])
+	}
+	if err := fleet.CheckRefit(works[:1]); err != nil {
+		t.Errorf("CheckRefit() error = %v", err)
+	}
+}
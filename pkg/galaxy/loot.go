@@ -0,0 +1,38 @@
+package galaxy
+
+// SALVAGE_RATIO is the part of the destroyed ship mass which may be carried away as loot.
+const SALVAGE_RATIO = 0.5
+
+// CargoCapacity returns the total cargo capacity of the ships which are not destroyed.
+func (fleet *Fleet) CargoCapacity() float64 {
+	capacity := 0.0
+	for _, ship := range fleet.Ships {
+		if !ship.Destroyed {
+			capacity += ship.Tech.CargoCapacity
+		}
+	}
+
+	return capacity
+}
+
+// SalvageMass returns the mass which can be looted from the destroyed ships of the fleet.
+func (fleet *Fleet) SalvageMass() float64 {
+	salvage := 0.0
+	for _, ship := range fleet.Ships {
+		if ship.Destroyed {
+			salvage += ship.Tech.Mass * SALVAGE_RATIO
+		}
+	}
+
+	return salvage
+}
+
+// CalculateLoot returns the amount of resources the looting fleet captures from the destroyed
+// ships of the looted fleet. The loot is limited by the cargo capacity of the surviving looting ships.
+func CalculateLoot(looting *Fleet, looted *Fleet) float64 {
+	if looting == nil || looted == nil {
+		return 0
+	}
+
+	return min(looting.CargoCapacity(), looted.SalvageMass())
+}
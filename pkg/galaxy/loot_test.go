@@ -0,0 +1,60 @@
+package galaxy
+
+import "testing"
+
+func TestCalculateLoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		looting  *Fleet
+		looted   *Fleet
+		expected float64
+	}{
+		{
+			name: "loot is limited by the cargo capacity of surviving ships",
+			looting: &Fleet{Ships: []*Ship{
+				{ID: "a1", Tech: ShipTech{CargoCapacity: 5, Mass: 10}},
+				{ID: "a2", Tech: ShipTech{CargoCapacity: 20, Mass: 30}, Destroyed: true},
+			}},
+			looted: &Fleet{Ships: []*Ship{
+				{ID: "b1", Tech: ShipTech{Mass: 40}, Destroyed: true},
+			}},
+			expected: 5,
+		},
+		{
+			name: "loot is limited by the salvage of destroyed ships",
+			looting: &Fleet{Ships: []*Ship{
+				{ID: "a1", Tech: ShipTech{CargoCapacity: 100, Mass: 10}},
+			}},
+			looted: &Fleet{Ships: []*Ship{
+				{ID: "b1", Tech: ShipTech{Mass: 40}, Destroyed: true},
+				{ID: "b2", Tech: ShipTech{Mass: 60}},
+			}},
+			expected: 20, // 40 * SALVAGE_RATIO
+		},
+		{
+			name: "no cargo - no loot",
+			looting: &Fleet{Ships: []*Ship{
+				{ID: "a1", Tech: ShipTech{Mass: 10}},
+			}},
+			looted: &Fleet{Ships: []*Ship{
+				{ID: "b1", Tech: ShipTech{Mass: 40}, Destroyed: true},
+			}},
+			expected: 0,
+		},
+		{
+			name:     "nil fleet",
+			looting:  nil,
+			looted:   &Fleet{},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loot := CalculateLoot(tt.looting, tt.looted)
+			if loot != tt.expected {
+				t.Errorf("CalculateLoot() = %f; want %f", loot, tt.expected)
+			}
+		})
+	}
+}
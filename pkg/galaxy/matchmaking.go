@@ -0,0 +1,83 @@
+package galaxy
+
+import (
+	"fmt"
+	"time"
+)
+
+// Matchmaking queue entry statuses
+const (
+	QUEUE_STATUS_WAITING   = "waiting"
+	QUEUE_STATUS_MATCHED   = "matched"
+	QUEUE_STATUS_TIMED_OUT = "timed_out"
+	QUEUE_STATUS_CANCELLED = "cancelled"
+)
+
+// How the opponents are paired in the queue
+const (
+	MATCH_BY_RATING    = "rating"
+	MATCH_BY_RESOURCES = "resources"
+)
+
+// Race id of the fleets generated for the matches against the AI
+const AI_RACE_ID = "ai"
+
+// QueueEntry is a division fleet waiting in the matchmaking queue, and the result of its match.
+type QueueEntry struct {
+	ID         string `json:"id"`
+	DivisionId string `json:"division_id"`
+	RaceId     string `json:"race_id"`
+	FleetId    string `json:"fleet_id"`
+	MatchBy    string `json:"match_by"`
+	// rating of the race in the division when enqueued
+	Rating float64 `json:"rating"`
+	// resources used for the ships of the fleet
+	Resources float64 `json:"resources"`
+	// fight a generated opponent when nobody is available until the timeout
+	AllowAI    bool      `json:"allow_ai"`
+	Status     string    `json:"status"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	// set when matched
+	OpponentId string     `json:"opponent_id,omitempty"`
+	BattleId   string     `json:"battle_id,omitempty"`
+	Score      *float64   `json:"score,omitempty"`
+	MatchedAt  *time.Time `json:"matched_at,omitempty"`
+}
+
+func (entry *QueueEntry) Validate() error {
+	if entry.MatchBy != MATCH_BY_RATING && entry.MatchBy != MATCH_BY_RESOURCES {
+		return fmt.Errorf("unknown match_by %q", entry.MatchBy)
+	}
+
+	return nil
+}
+
+// Strength returns the value the opponents are compared by.
+func (entry *QueueEntry) Strength() float64 {
+	if entry.MatchBy == MATCH_BY_RESOURCES {
+		return entry.Resources
+	}
+
+	return entry.Rating
+}
+
+// FleetResources returns the resources used for the ships of the fleet, a ship costs its mass.
+func FleetResources(fleet *Fleet) float64 {
+	resources := 0.0
+	for _, ship := range fleet.Ships {
+		resources += ship.Tech.Mass
+	}
+
+	return resources
+}
+
+// Notification tells a race about an event, e.g. the result of its match.
+type Notification struct {
+	ID        string    `json:"id"`
+	RaceId    string    `json:"race_id"`
+	Message   string    `json:"message"`
+	BattleId  string    `json:"battle_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,116 @@
+package galaxy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Fleet build optimization job statuses
+const (
+	OPTIMIZATION_STATUS_RUNNING  = "running"
+	OPTIMIZATION_STATUS_FINISHED = "finished"
+	OPTIMIZATION_STATUS_FAILED   = "failed"
+)
+
+// Defaults of the optimization options left 0, and the limits of a single optimization
+const (
+	OPTIMIZATION_ITERATIONS     = 30
+	OPTIMIZATION_BATTLES        = 10
+	OPTIMIZATION_RESULTS        = 3
+	OPTIMIZATION_MAX_ITERATIONS = 1000
+	OPTIMIZATION_MAX_BATTLES    = 1000
+)
+
+// OptimizationOptions limit the search for the fleet builds beating an opponent fleet.
+type OptimizationOptions struct {
+	// resources of the fleet builds, at most the division resources which are used when 0
+	Budget int `json:"budget"`
+	// mutations tried from every starting archetype
+	Iterations int `json:"iterations"`
+	// seeded battles every candidate fights against the opponent
+	Battles int `json:"battles"`
+	// number of the best fleet builds returned
+	Results int `json:"results"`
+	// seed of the mutations and the battles, the same seed gives the same results
+	Seed uint64 `json:"seed"`
+}
+
+func (options *OptimizationOptions) Validate() error {
+	if options.Budget < 0 || options.Iterations < 0 || options.Battles < 0 || options.Results < 0 {
+		return errors.New("optimization options must not be negative")
+	}
+
+	if options.Iterations > OPTIMIZATION_MAX_ITERATIONS {
+		return errors.New("too many iterations")
+	}
+
+	if options.Battles > OPTIMIZATION_MAX_BATTLES {
+		return errors.New("too many battles")
+	}
+
+	return nil
+}
+
+// ValidateBudget checks the budget does not exceed the resources of the division,
+// the simulated fleets must stay within what the race can build.
+func (options *OptimizationOptions) ValidateBudget(division *Division) error {
+	if options.Budget > division.ResourcesAmount {
+		return &ValidationError{Violations: []Violation{{
+			Field:   "budget",
+			Code:    VIOLATION_INVALID,
+			Message: fmt.Sprintf("budget %d exceeds the division resources %d", options.Budget, division.ResourcesAmount),
+		}}}
+	}
+
+	return nil
+}
+
+// WithDefaults returns the options with the defaults instead of the zero values. Seed 0 is replaced by 1.
+func (options OptimizationOptions) WithDefaults() OptimizationOptions {
+	if options.Iterations == 0 {
+		options.Iterations = OPTIMIZATION_ITERATIONS
+	}
+	if options.Battles == 0 {
+		options.Battles = OPTIMIZATION_BATTLES
+	}
+	if options.Results == 0 {
+		options.Results = OPTIMIZATION_RESULTS
+	}
+	if options.Seed == 0 {
+		options.Seed = 1
+	}
+
+	return options
+}
+
+// OptimizedFleetBuild is a fleet build found by the optimizer with its results against the opponent.
+type OptimizedFleetBuild struct {
+	// the archetype the fleet build was generated from
+	Design     *Archetype  `json:"design"`
+	FleetBuild *FleetBuild `json:"fleet_build"`
+	Wins       int         `json:"wins"`
+	Draws      int         `json:"draws"`
+	Losses     int         `json:"losses"`
+	// share of the battles won
+	WinRate float64 `json:"win_rate"`
+	// average battle score, a draw counts half of a win
+	Score float64 `json:"score"`
+}
+
+// OptimizationJob is a fleet build optimization running in the background.
+type OptimizationJob struct {
+	ID              string              `json:"id"`
+	DivisionId      string              `json:"division_id"`
+	RaceId          string              `json:"race_id"`
+	OpponentFleetId string              `json:"opponent_fleet_id"`
+	Options         OptimizationOptions `json:"options"`
+	Status          string              `json:"status"`
+	// finished iterations of the options iterations
+	Iteration int `json:"iteration"`
+	// the best fleet builds found, set when finished
+	Results    []*OptimizedFleetBuild `json:"results,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+}
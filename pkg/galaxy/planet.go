@@ -0,0 +1,87 @@
+package galaxy
+
+import (
+	"errors"
+	"math"
+)
+
+// Planet is a location on the map of a division. Fleets are positioned at planets.
+type Planet struct {
+	ID         string  `json:"id"`
+	DivisionId string  `json:"division_id"`
+	Name       string  `json:"name"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+
+	// Economy: the owner race receives the production of the planet every turn
+	OwnerId    string  `json:"owner_id,omitempty"`
+	Population float64 `json:"population"`
+	Industry   float64 `json:"industry"`
+}
+
+func (planet *Planet) Validate() error {
+	if planet.ID == "" {
+		return errors.New("id must not be empty")
+	}
+
+	if math.IsNaN(planet.X) || math.IsInf(planet.X, 0) || math.IsNaN(planet.Y) || math.IsInf(planet.Y, 0) {
+		return errors.New("coordinates must be finite numbers")
+	}
+
+	if planet.Population < 0 || planet.Industry < 0 {
+		return errors.New("population and industry must not be negative")
+	}
+
+	return nil
+}
+
+// Distance returns the straight line distance between the planets.
+func (planet *Planet) Distance(other *Planet) float64 {
+	return math.Hypot(other.X-planet.X, other.Y-planet.Y)
+}
+
+// FleetMovement is a move order of a fleet flying between two planets.
+// Times are measured on the map clock of the division.
+type FleetMovement struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	DepartureTime float64 `json:"departure_time"`
+	ArrivalTime   float64 `json:"arrival_time"`
+}
+
+// Progress returns the travelled part of the route at the given time, from 0 to 1.
+func (movement *FleetMovement) Progress(time float64) float64 {
+	duration := movement.ArrivalTime - movement.DepartureTime
+	if duration <= 0 || time >= movement.ArrivalTime {
+		return 1
+	}
+
+	return max(0, (time-movement.DepartureTime)/duration)
+}
+
+// TravelTime returns the time the fleet needs to fly the given distance.
+func (fleet *Fleet) TravelTime(distance float64) (float64, error) {
+	if len(fleet.Ships) == 0 {
+		return 0, errors.New("fleet has no ships")
+	}
+
+	speed := fleet.Speed()
+	if speed <= 0 {
+		return 0, errors.New("fleet can not move, its speed is 0")
+	}
+
+	return distance / speed, nil
+}
+
+// IsHostile tells whether the fleets belong to different races.
+func (fleet *Fleet) IsHostile(other *Fleet) bool {
+	return fleet.Owner != other.Owner
+}
+
+// DivisionMap shows the planets and the fleets of a division at the current map time.
+type DivisionMap struct {
+	DivisionId string    `json:"division_id"`
+	Time       float64   `json:"time"`
+	Planets    []*Planet `json:"planets"`
+	Fleets     []*Fleet  `json:"fleets"`
+}
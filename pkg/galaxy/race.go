@@ -1,7 +1,14 @@
 package galaxy
 
+// Game masters may manage the game, e.g. advance the turns
+const ROLE_ADMIN = "admin"
+
 type Race struct {
 	ID   string
 	Name string
 	Role string
 }
+
+func (race *Race) IsAdmin() bool {
+	return race != nil && race.Role == ROLE_ADMIN
+}
@@ -0,0 +1,104 @@
+package galaxy
+
+import (
+	"math"
+	"time"
+)
+
+// Elo rating parameters
+const (
+	RATING_INITIAL = 1500.0
+	// the largest change of the rating after one battle
+	RATING_K_FACTOR = 32.0
+	// the rating difference which makes the stronger race 10 times more likely to win
+	RATING_SCALE = 400.0
+)
+
+// Scores of a battle result
+const (
+	SCORE_WIN  = 1.0
+	SCORE_DRAW = 0.5
+	SCORE_LOSS = 0.0
+)
+
+// Rating is the Elo rating of a race, in a division or global when the division is empty.
+type Rating struct {
+	RaceId     string    `json:"race_id"`
+	DivisionId string    `json:"division_id,omitempty"`
+	Rating     float64   `json:"rating"`
+	Games      int       `json:"games"`
+	Wins       int       `json:"wins"`
+	Losses     int       `json:"losses"`
+	Draws      int       `json:"draws"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewRating(divisionId, raceId string) *Rating {
+	return &Rating{RaceId: raceId, DivisionId: divisionId, Rating: RATING_INITIAL}
+}
+
+// RatingChange is an entry of the rating history of a race.
+type RatingChange struct {
+	RaceId     string    `json:"race_id"`
+	DivisionId string    `json:"division_id,omitempty"`
+	OpponentId string    `json:"opponent_id"`
+	BattleId   string    `json:"battle_id"`
+	Score      float64   `json:"score"`
+	Before     float64   `json:"before"`
+	After      float64   `json:"after"`
+	Time       time.Time `json:"time"`
+}
+
+// LeaderboardEntry is a row of the leaderboard.
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	*Rating
+}
+
+// ExpectedScore returns the probability of the race with the rating to win over the opponent, draws count as half.
+func ExpectedScore(rating, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/RATING_SCALE))
+}
+
+// BattleScore returns the score of the side A: a side wins when it destroyed the other side and kept
+// some ships, all the other results are draws.
+func BattleScore(battle *Battle) float64 {
+	survivorsA, survivorsB := battle.Survivors()
+	switch {
+	case survivorsA > 0 && survivorsB == 0:
+		return SCORE_WIN
+	case survivorsB > 0 && survivorsA == 0:
+		return SCORE_LOSS
+	default:
+		return SCORE_DRAW
+	}
+}
+
+// Apply updates the rating with the score of a battle against the opponent rating. Returns the rating before.
+func (rating *Rating) Apply(opponent float64, score float64, time time.Time) float64 {
+	before := rating.Rating
+	rating.Rating += RATING_K_FACTOR * (score - ExpectedScore(before, opponent))
+	rating.Games++
+	switch score {
+	case SCORE_WIN:
+		rating.Wins++
+	case SCORE_LOSS:
+		rating.Losses++
+	default:
+		rating.Draws++
+	}
+	rating.UpdatedAt = time
+
+	return before
+}
+
+// MatchCandidate is an opponent suggested by the matchmaking.
+type MatchCandidate struct {
+	RaceId  string  `json:"race_id"`
+	FleetId string  `json:"fleet_id"`
+	Rating  float64 `json:"rating"`
+	// absolute difference to the rating of the race looking for the match
+	RatingDifference float64 `json:"rating_difference"`
+	// chance of the race looking for the match to win
+	ExpectedScore float64 `json:"expected_score"`
+}
@@ -0,0 +1,85 @@
+package galaxy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExpectedScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		rating   float64
+		opponent float64
+		want     float64
+	}{
+		{name: "equal ratings", rating: 1500, opponent: 1500, want: 0.5},
+		{name: "stronger by the scale", rating: 1900, opponent: 1500, want: 10.0 / 11},
+		{name: "weaker by the scale", rating: 1500, opponent: 1900, want: 1.0 / 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpectedScore(tt.rating, tt.opponent); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ExpectedScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRating_Apply(t *testing.T) {
+	tests := []struct {
+		name       string
+		opponent   float64
+		score      float64
+		wantRating float64
+		wantWins   int
+		wantLosses int
+		wantDraws  int
+	}{
+		{name: "win against an equal", opponent: 1500, score: SCORE_WIN, wantRating: 1516, wantWins: 1},
+		{name: "loss against an equal", opponent: 1500, score: SCORE_LOSS, wantRating: 1484, wantLosses: 1},
+		{name: "draw against an equal", opponent: 1500, score: SCORE_DRAW, wantRating: 1500, wantDraws: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rating := NewRating("d1", "r1")
+			before := rating.Apply(tt.opponent, tt.score, time.Time{})
+
+			if before != RATING_INITIAL || rating.Rating != tt.wantRating || rating.Games != 1 ||
+				rating.Wins != tt.wantWins || rating.Losses != tt.wantLosses || rating.Draws != tt.wantDraws {
+				t.Errorf("unexpected rating %+v, before %v", rating, before)
+			}
+		})
+	}
+}
+
+func TestBattleScore(t *testing.T) {
+	alive := func(destroyed ...bool) *Fleet {
+		ships := make([]*Ship, len(destroyed))
+		for i, d := range destroyed {
+			ships[i] = &Ship{Destroyed: d}
+		}
+		return NewFleet(ships)
+	}
+
+	tests := []struct {
+		name string
+		a, b *Fleet
+		want float64
+	}{
+		{name: "side A wins", a: alive(false, true), b: alive(true), want: SCORE_WIN},
+		{name: "side B wins", a: alive(true), b: alive(false), want: SCORE_LOSS},
+		{name: "stalemate", a: alive(false), b: alive(false), want: SCORE_DRAW},
+		{name: "both destroyed", a: alive(true), b: alive(true), want: SCORE_DRAW},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BattleScore(&Battle{PostSideA: tt.a, PostSideB: tt.b}); got != tt.want {
+				t.Errorf("BattleScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
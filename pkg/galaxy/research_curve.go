@@ -0,0 +1,184 @@
+package galaxy
+
+import (
+	"fmt"
+	"glaktika.eu/galaktika/pkg/gamemath"
+	"math"
+)
+
+const (
+	RESEARCH_CURVE_LINEAR      = "linear"
+	RESEARCH_CURVE_EXPONENTIAL = "exponential"
+	RESEARCH_CURVE_PIECEWISE   = "piecewise"
+)
+
+const (
+	TECHNOLOGY_ATTACK  = "attack"
+	TECHNOLOGY_DEFENSE = "defense"
+	TECHNOLOGY_ENGINE  = "engine"
+	TECHNOLOGY_CARGO   = "cargo"
+)
+
+var TechnologyNames = []string{TECHNOLOGY_ATTACK, TECHNOLOGY_DEFENSE, TECHNOLOGY_ENGINE, TECHNOLOGY_CARGO}
+
+// ResearchCurve describes how many resources are needed to gain technology levels.
+type ResearchCurve struct {
+	Type string `json:"type"`
+
+	// linear, exponential: resources for the first level gain (ONE_TECH_RESOURCES when 0)
+	BaseCost float64 `json:"base_cost,omitempty"`
+	// exponential: every next level costs Growth times more than the previous one
+	Growth float64 `json:"growth,omitempty"`
+	// piecewise: resources -> level gain points, interpolated linearly.
+	// Resources beyond the last point do not give more levels.
+	Resources []float64 `json:"resources,omitempty"`
+	Levels    []float64 `json:"levels,omitempty"`
+}
+
+func (curve *ResearchCurve) baseCost() float64 {
+	if curve.BaseCost == 0 {
+		return ONE_TECH_RESOURCES
+	}
+
+	return curve.BaseCost
+}
+
+func (curve *ResearchCurve) piecewiseFunction() (*gamemath.ConfigurableFunction, error) {
+	return gamemath.NewConfigurableFunction(curve.Resources, curve.Levels)
+}
+
+func (curve *ResearchCurve) Validate() error {
+	switch curve.Type {
+	case "", RESEARCH_CURVE_LINEAR:
+		if curve.BaseCost < 0 {
+			return fmt.Errorf("base_cost must not be negative")
+		}
+	case RESEARCH_CURVE_EXPONENTIAL:
+		if curve.BaseCost < 0 {
+			return fmt.Errorf("base_cost must not be negative")
+		}
+		if curve.Growth < 1 {
+			return fmt.Errorf("growth must not be lower than 1")
+		}
+	case RESEARCH_CURVE_PIECEWISE:
+		f, err := curve.piecewiseFunction()
+		if err != nil {
+			return err
+		}
+		if curve.Resources[0] != 0 || curve.Levels[0] != 0 {
+			return fmt.Errorf("piecewise curve must start at 0 resources and 0 levels")
+		}
+		if _, err := f.CalculateInverse(0); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown research curve type %q", curve.Type)
+	}
+
+	return nil
+}
+
+// LevelGain returns the technology levels gained by spending the given resources.
+func (curve *ResearchCurve) LevelGain(resources float64) float64 {
+	if resources <= 0 {
+		return 0
+	}
+
+	switch curve.Type {
+	case RESEARCH_CURVE_EXPONENTIAL:
+		if curve.Growth == 1 {
+			return resources / curve.baseCost()
+		}
+		return math.Log(1+resources*(curve.Growth-1)/curve.baseCost()) / math.Log(curve.Growth)
+	case RESEARCH_CURVE_PIECEWISE:
+		f, err := curve.piecewiseFunction()
+		if err != nil {
+			return 0
+		}
+		return f.Calculate(resources)
+	default:
+		return resources / curve.baseCost()
+	}
+}
+
+// ResourcesForGain is the inverse of LevelGain: it returns the resources needed to gain the given levels.
+func (curve *ResearchCurve) ResourcesForGain(gain float64) (float64, error) {
+	if gain <= 0 {
+		return 0, nil
+	}
+
+	switch curve.Type {
+	case RESEARCH_CURVE_EXPONENTIAL:
+		if curve.Growth == 1 {
+			return gain * curve.baseCost(), nil
+		}
+		return curve.baseCost() * (math.Pow(curve.Growth, gain) - 1) / (curve.Growth - 1), nil
+	case RESEARCH_CURVE_PIECEWISE:
+		f, err := curve.piecewiseFunction()
+		if err != nil {
+			return 0, err
+		}
+		return f.CalculateInverse(gain)
+	default:
+		return gain * curve.baseCost(), nil
+	}
+}
+
+// ResearchRules holds the research curve of every technology.
+type ResearchRules struct {
+	Attack  ResearchCurve `json:"attack"`
+	Defense ResearchCurve `json:"defense"`
+	Engine  ResearchCurve `json:"engine"`
+	Cargo   ResearchCurve `json:"cargo"`
+}
+
+// NewLinearResearchRules returns rules where every level of every technology costs ONE_TECH_RESOURCES.
+func NewLinearResearchRules() *ResearchRules {
+	linear := ResearchCurve{Type: RESEARCH_CURVE_LINEAR, BaseCost: ONE_TECH_RESOURCES}
+
+	return &ResearchRules{
+		Attack:  linear,
+		Defense: linear,
+		Engine:  linear,
+		Cargo:   linear,
+	}
+}
+
+// Curve returns the research curve of the technology with the given name.
+func (rules *ResearchRules) Curve(technology string) (*ResearchCurve, error) {
+	switch technology {
+	case TECHNOLOGY_ATTACK:
+		return &rules.Attack, nil
+	case TECHNOLOGY_DEFENSE:
+		return &rules.Defense, nil
+	case TECHNOLOGY_ENGINE:
+		return &rules.Engine, nil
+	case TECHNOLOGY_CARGO:
+		return &rules.Cargo, nil
+	}
+
+	return nil, fmt.Errorf("unknown technology %q", technology)
+}
+
+func (rules *ResearchRules) Validate() error {
+	for _, technology := range TechnologyNames {
+		curve, _ := rules.Curve(technology)
+		if err := curve.Validate(); err != nil {
+			return fmt.Errorf("%s research curve: %w", technology, err)
+		}
+	}
+
+	return nil
+}
+
+// ResearchPreview shows what the next resources spent on a technology would give.
+type ResearchPreview struct {
+	Technology     string  `json:"technology"`
+	SpentResources float64 `json:"spent_resources"`
+	Level          float64 `json:"level"`
+	ExtraResources float64 `json:"extra_resources"`
+	PreviewLevel   float64 `json:"preview_level"`
+	MarginalGain   float64 `json:"marginal_gain"`
+	// nil when the next whole level can not be reached
+	ResourcesToNextLevel *float64 `json:"resources_to_next_level"`
+}
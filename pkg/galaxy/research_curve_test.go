@@ -0,0 +1,123 @@
+package galaxy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResearchCurveLevelGainAndInverse(t *testing.T) {
+	tests := []struct {
+		name         string
+		curve        ResearchCurve
+		resources    float64
+		expectedGain float64
+	}{
+		{
+			name:         "linear with default cost",
+			curve:        ResearchCurve{Type: RESEARCH_CURVE_LINEAR},
+			resources:    150,
+			expectedGain: 1.5,
+		},
+		{
+			name:         "exponential: levels cost 100, 200, 400",
+			curve:        ResearchCurve{Type: RESEARCH_CURVE_EXPONENTIAL, BaseCost: 100, Growth: 2},
+			resources:    700,
+			expectedGain: 3,
+		},
+		{
+			name:         "exponential with growth 1 is linear",
+			curve:        ResearchCurve{Type: RESEARCH_CURVE_EXPONENTIAL, BaseCost: 50, Growth: 1},
+			resources:    100,
+			expectedGain: 2,
+		},
+		{
+			name:         "piecewise",
+			curve:        ResearchCurve{Type: RESEARCH_CURVE_PIECEWISE, Resources: []float64{0, 100, 400}, Levels: []float64{0, 1, 2}},
+			resources:    250,
+			expectedGain: 1.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.curve.Validate(); err != nil {
+				t.Fatalf("Validate() error: %v", err)
+			}
+
+			gain := tt.curve.LevelGain(tt.resources)
+			if math.Abs(gain-tt.expectedGain) > 1e-9 {
+				t.Errorf("LevelGain(%v) = %v; want %v", tt.resources, gain, tt.expectedGain)
+			}
+
+			resources, err := tt.curve.ResourcesForGain(tt.expectedGain)
+			if err != nil {
+				t.Fatalf("ResourcesForGain() error: %v", err)
+			}
+			if math.Abs(resources-tt.resources) > 1e-9 {
+				t.Errorf("ResourcesForGain(%v) = %v; want %v", tt.expectedGain, resources, tt.resources)
+			}
+		})
+	}
+}
+
+func TestResearchCurveValidate(t *testing.T) {
+	invalid := []ResearchCurve{
+		{Type: "quadratic"},
+		{Type: RESEARCH_CURVE_LINEAR, BaseCost: -1},
+		{Type: RESEARCH_CURVE_EXPONENTIAL, BaseCost: 100, Growth: 0.5},
+		{Type: RESEARCH_CURVE_PIECEWISE, Resources: []float64{0, 100}, Levels: []float64{0, 0}},
+		{Type: RESEARCH_CURVE_PIECEWISE, Resources: []float64{10, 100}, Levels: []float64{0, 1}},
+	}
+
+	for _, curve := range invalid {
+		if err := curve.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", curve)
+		}
+	}
+}
+
+func TestPreviewResearch(t *testing.T) {
+	fleetBuild := &FleetBuild{
+		AttackResources: 100,
+		ResearchRules: &ResearchRules{
+			Attack:  ResearchCurve{Type: RESEARCH_CURVE_EXPONENTIAL, BaseCost: 100, Growth: 2},
+			Defense: ResearchCurve{Type: RESEARCH_CURVE_LINEAR},
+			Engine:  ResearchCurve{Type: RESEARCH_CURVE_LINEAR},
+			Cargo:   ResearchCurve{Type: RESEARCH_CURVE_LINEAR},
+		},
+	}
+
+	previews := fleetBuild.PreviewResearch(200)
+	if len(previews) != 4 {
+		t.Fatalf("expected 4 previews, got %d", len(previews))
+	}
+
+	attack := previews[0]
+	if attack.Technology != TECHNOLOGY_ATTACK || attack.Level != 2 || attack.PreviewLevel != 3 || attack.MarginalGain != 1 {
+		t.Errorf("unexpected attack preview %+v", attack)
+	}
+	if attack.ResourcesToNextLevel == nil || *attack.ResourcesToNextLevel != 200 {
+		t.Errorf("expected 200 resources to the next attack level, got %v", attack.ResourcesToNextLevel)
+	}
+
+	defense := previews[1]
+	if defense.Level != 1 || defense.PreviewLevel != 3 || defense.MarginalGain != 2 {
+		t.Errorf("unexpected defense preview %+v", defense)
+	}
+}
+
+func TestDivisionResourcesToReachLevel(t *testing.T) {
+	division := &Division{TechAttack: 2}
+
+	resources, err := division.ResourcesToReachLevel(TECHNOLOGY_ATTACK, 3.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resources != 150 {
+		t.Errorf("expected 150 resources, got %v", resources)
+	}
+
+	if _, err := division.ResourcesToReachLevel("shields", 2); err == nil {
+		t.Errorf("expected error for unknown technology")
+	}
+}
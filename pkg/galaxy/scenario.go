@@ -0,0 +1,221 @@
+package galaxy
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml"
+)
+
+// Violation codes of the scenario validation
+const (
+	VIOLATION_REQUIRED          = "required"
+	VIOLATION_DUPLICATE         = "duplicate"
+	VIOLATION_UNKNOWN_REFERENCE = "unknown_reference"
+	VIOLATION_INVALID           = "invalid"
+)
+
+// Scenario describes game data loaded at once, e.g. a situation prepared for testing.
+// The references between its parts are resolved by ids, so they may be written in any order.
+type Scenario struct {
+	Name string `json:"name"`
+	// The game data is cleared before the scenario is loaded
+	Reset       bool                  `json:"reset"`
+	Divisions   []*Division           `json:"divisions"`
+	Races       []*ScenarioRace       `json:"races"`
+	ShipModels  []*ShipModel          `json:"ship_models"`
+	FleetBuilds []*ScenarioFleetBuild `json:"fleet_builds"`
+}
+
+// ScenarioRace is a race which signs in with its token.
+type ScenarioRace struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Token string `json:"token"`
+}
+
+// ScenarioFleetBuild is a fleet build with its assigned ship models.
+type ScenarioFleetBuild struct {
+	ID               string                `json:"id"`
+	DivisionId       string                `json:"division_id"`
+	RaceId           string                `json:"race_id"`
+	AttackResources  float64               `json:"attack_resources"`
+	DefenseResources float64               `json:"defense_resources"`
+	EngineResources  float64               `json:"engine_resources"`
+	CargoResources   float64               `json:"cargo_resources"`
+	ResearchedNodes  []string              `json:"researched_nodes"`
+	ShipModels       []*ScenarioAssignment `json:"ship_models"`
+}
+
+// ScenarioAssignment assigns an amount of a ship model to a fleet build.
+type ScenarioAssignment struct {
+	ShipModelId string `json:"ship_model_id"`
+	// The latest version is assigned when 0
+	ShipModelVersion int `json:"ship_model_version"`
+	Amount           int `json:"amount"`
+}
+
+// ScenarioSummary counts the loaded data of a scenario.
+type ScenarioSummary struct {
+	Name        string `json:"name"`
+	Divisions   int    `json:"divisions"`
+	Races       int    `json:"races"`
+	ShipModels  int    `json:"ship_models"`
+	FleetBuilds int    `json:"fleet_builds"`
+	Assignments int    `json:"assignments"`
+}
+
+// ParseScenario reads a scenario written in YAML or JSON. Unknown fields are rejected,
+// so that a typo does not silently change the scenario.
+func ParseScenario(data []byte) (*Scenario, error) {
+	scenario := &Scenario{}
+	if err := yaml.UnmarshalWithOptions(data, scenario, yaml.Strict()); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %s", yaml.FormatError(err, false, true))
+	}
+
+	return scenario, nil
+}
+
+// FleetBuild returns the fleet build without its assignments.
+func (scenarioFleetBuild *ScenarioFleetBuild) FleetBuild() *FleetBuild {
+	return &FleetBuild{
+		ID:               scenarioFleetBuild.ID,
+		DivisionId:       scenarioFleetBuild.DivisionId,
+		RaceId:           scenarioFleetBuild.RaceId,
+		AttackResources:  scenarioFleetBuild.AttackResources,
+		DefenseResources: scenarioFleetBuild.DefenseResources,
+		EngineResources:  scenarioFleetBuild.EngineResources,
+		CargoResources:   scenarioFleetBuild.CargoResources,
+		ResearchedNodes:  scenarioFleetBuild.ResearchedNodes,
+	}
+}
+
+// Validate checks the parts of the scenario on their own. The references to the data
+// outside the scenario are checked when it is loaded.
+func (scenario *Scenario) Validate() error {
+	violations := []Violation{}
+	violate := func(field string, code string, message string) {
+		violations = append(violations, Violation{Field: field, Code: code, Message: message})
+	}
+
+	divisionIds := map[string]bool{}
+	for i, division := range scenario.Divisions {
+		field := fmt.Sprintf("divisions[%d]", i)
+		switch {
+		case division == nil || division.ID == "":
+			violate(field+".id", VIOLATION_REQUIRED, "division id is required")
+			continue
+		case divisionIds[division.ID]:
+			violate(field+".id", VIOLATION_DUPLICATE, "division "+division.ID+" is defined more than once")
+		}
+		divisionIds[division.ID] = true
+
+		if err := division.Validate(); err != nil {
+			violate(field, VIOLATION_INVALID, err.Error())
+		}
+	}
+
+	raceIds := map[string]bool{}
+	tokens := map[string]bool{}
+	for i, race := range scenario.Races {
+		field := fmt.Sprintf("races[%d]", i)
+		if race == nil || race.ID == "" {
+			violate(field+".id", VIOLATION_REQUIRED, "race id is required")
+			continue
+		}
+		if raceIds[race.ID] {
+			violate(field+".id", VIOLATION_DUPLICATE, "race "+race.ID+" is defined more than once")
+		}
+		raceIds[race.ID] = true
+
+		switch {
+		case race.Token == "":
+			violate(field+".token", VIOLATION_REQUIRED, "token of race "+race.ID+" is required")
+		case tokens[race.Token]:
+			violate(field+".token", VIOLATION_DUPLICATE, "token of race "+race.ID+" is used by another race")
+		}
+		tokens[race.Token] = true
+	}
+
+	shipModelIds := map[string]bool{}
+	for i, shipModel := range scenario.ShipModels {
+		field := fmt.Sprintf("ship_models[%d]", i)
+		if shipModel == nil || shipModel.ID == "" {
+			violate(field+".id", VIOLATION_REQUIRED, "ship model id is required")
+			continue
+		}
+		if shipModelIds[shipModel.ID] {
+			violate(field+".id", VIOLATION_DUPLICATE, "ship model "+shipModel.ID+" is defined more than once")
+		}
+		shipModelIds[shipModel.ID] = true
+
+		if shipModel.OwnerId == "" {
+			violate(field+".owner_id", VIOLATION_REQUIRED, "owner of ship model "+shipModel.ID+" is required")
+		}
+
+		// the stored ship models are migrated into modules, the scenario keeps the parsed design
+		migrated := *shipModel
+		migrated.MigrateModules()
+		var validationError *ValidationError
+		if errors.As(migrated.ValidateModel(nil, nil), &validationError) {
+			for _, violation := range validationError.Violations {
+				violate(field+"."+violation.Field, violation.Code, violation.Message)
+			}
+		}
+	}
+
+	fleetBuildIds := map[string]bool{}
+	for i, fleetBuild := range scenario.FleetBuilds {
+		field := fmt.Sprintf("fleet_builds[%d]", i)
+		if fleetBuild == nil || fleetBuild.ID == "" {
+			violate(field+".id", VIOLATION_REQUIRED, "fleet build id is required")
+			continue
+		}
+		if fleetBuildIds[fleetBuild.ID] {
+			violate(field+".id", VIOLATION_DUPLICATE, "fleet build "+fleetBuild.ID+" is defined more than once")
+		}
+		fleetBuildIds[fleetBuild.ID] = true
+
+		if fleetBuild.DivisionId == "" {
+			violate(field+".division_id", VIOLATION_REQUIRED, "division of fleet build "+fleetBuild.ID+" is required")
+		}
+		if fleetBuild.RaceId == "" {
+			violate(field+".race_id", VIOLATION_REQUIRED, "race of fleet build "+fleetBuild.ID+" is required")
+		}
+		for _, resources := range []float64{fleetBuild.AttackResources, fleetBuild.DefenseResources, fleetBuild.EngineResources, fleetBuild.CargoResources} {
+			if resources < 0 {
+				violate(field, VIOLATION_INVALID, "research resources of fleet build "+fleetBuild.ID+" must not be negative")
+				break
+			}
+		}
+		if err := fleetBuild.FleetBuild().ValidateResearchedNodes(); err != nil {
+			violate(field+".researched_nodes", VIOLATION_INVALID, err.Error())
+		}
+
+		assignedIds := map[string]bool{}
+		for j, assignment := range fleetBuild.ShipModels {
+			assignmentField := fmt.Sprintf("%s.ship_models[%d]", field, j)
+			switch {
+			case assignment == nil || assignment.ShipModelId == "":
+				violate(assignmentField+".ship_model_id", VIOLATION_REQUIRED, "ship model id is required")
+				continue
+			case assignedIds[assignment.ShipModelId]:
+				violate(assignmentField+".ship_model_id", VIOLATION_DUPLICATE, "ship model "+assignment.ShipModelId+" is assigned more than once")
+			}
+			assignedIds[assignment.ShipModelId] = true
+
+			if assignment.Amount <= 0 {
+				violate(assignmentField+".amount", VIOLATION_INVALID, "amount must be positive")
+			}
+			if assignment.ShipModelVersion < 0 {
+				violate(assignmentField+".ship_model_version", VIOLATION_INVALID, "version must not be negative")
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package galaxy
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScenario(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  *Scenario
+		wantError string
+	}{
+		{
+			name: "yaml",
+			data: `
+name: small
+divisions:
+  - id: d1
+    resources_amount: 100
+races:
+  - {id: r1, name: Red, token: t1}
+fleet_builds:
+  - id: b1
+    division_id: d1
+    race_id: r1
+    attack_resources: 5
+    ship_models:
+      - {ship_model_id: m1, amount: 2}
+`,
+			expected: &Scenario{
+				Name:      "small",
+				Divisions: []*Division{{ID: "d1", ResourcesAmount: 100}},
+				Races:     []*ScenarioRace{{ID: "r1", Name: "Red", Token: "t1"}},
+				FleetBuilds: []*ScenarioFleetBuild{{ID: "b1", DivisionId: "d1", RaceId: "r1", AttackResources: 5,
+					ShipModels: []*ScenarioAssignment{{ShipModelId: "m1", Amount: 2}}}},
+			},
+		},
+		{
+			name: "json",
+			data: `{"name": "small", "reset": true, "ship_models": [{"id": "m1", "owner_id": "r1", "modules": [{"type": "engine", "mass": 2}]}]}`,
+			expected: &Scenario{
+				Name:       "small",
+				Reset:      true,
+				ShipModels: []*ShipModel{{ID: "m1", OwnerId: "r1", Modules: []ShipModule{{Type: MODULE_ENGINE, Mass: 2}}}},
+			},
+		},
+		{
+			name:      "unknown field",
+			data:      "divisions:\n  - id: d1\n    resources: 100\n",
+			wantError: "unknown field \"resources\"",
+		},
+		{
+			name:      "wrong type",
+			data:      "divisions:\n  - id: d1\n    resources_amount: many\n",
+			wantError: "[3:23]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario, err := ParseScenario([]byte(tt.data))
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseScenario() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.expected, scenario) {
+				t.Errorf("expected %+v, got %+v", tt.expected, scenario)
+			}
+		})
+	}
+}
+
+func TestParseScenario_Example(t *testing.T) {
+	data, err := os.ReadFile("../../scenarios/duel.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		t.Fatalf("ParseScenario() error = %v", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestScenario_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario *Scenario
+		expected []string
+	}{
+		{
+			name: "valid",
+			scenario: &Scenario{
+				Divisions:   []*Division{{ID: "d1"}},
+				Races:       []*ScenarioRace{{ID: "r1", Token: "t1"}},
+				ShipModels:  []*ShipModel{{ID: "m1", OwnerId: "r1", Guns: 1, OneGunMass: 1, EngineMass: 1}},
+				FleetBuilds: []*ScenarioFleetBuild{{ID: "b1", DivisionId: "d1", RaceId: "r1", ShipModels: []*ScenarioAssignment{{ShipModelId: "m1", Amount: 1}}}},
+			},
+		},
+		{
+			name: "missing ids",
+			scenario: &Scenario{
+				Divisions:   []*Division{{}},
+				Races:       []*ScenarioRace{{ID: "r1"}},
+				ShipModels:  []*ShipModel{{ID: "m1"}},
+				FleetBuilds: []*ScenarioFleetBuild{{ID: "b1", ShipModels: []*ScenarioAssignment{{Amount: 1}}}},
+			},
+			expected: []string{"divisions[0].id", "races[0].token", "ship_models[0].owner_id",
+				"fleet_builds[0].division_id", "fleet_builds[0].race_id", "fleet_builds[0].ship_models[0].ship_model_id"},
+		},
+		{
+			name: "duplicates",
+			scenario: &Scenario{
+				Divisions: []*Division{{ID: "d1"}, {ID: "d1"}},
+				Races:     []*ScenarioRace{{ID: "r1", Token: "t1"}, {ID: "r2", Token: "t1"}},
+				FleetBuilds: []*ScenarioFleetBuild{{ID: "b1", DivisionId: "d1", RaceId: "r1",
+					ShipModels: []*ScenarioAssignment{{ShipModelId: "m1", Amount: 1}, {ShipModelId: "m1", Amount: 2}}}},
+			},
+			expected: []string{"divisions[1].id", "races[1].token", "fleet_builds[0].ship_models[1].ship_model_id"},
+		},
+		{
+			name: "invalid values",
+			scenario: &Scenario{
+				Divisions:  []*Division{{ID: "d1", ResourcesAmount: -1}},
+				ShipModels: []*ShipModel{{ID: "m1", OwnerId: "r1", EngineMass: -1}},
+				FleetBuilds: []*ScenarioFleetBuild{{ID: "b1", DivisionId: "d1", RaceId: "r1", CargoResources: -1, ResearchedNodes: []string{"unknown"},
+					ShipModels: []*ScenarioAssignment{{ShipModelId: "m1", Amount: 0}}}},
+			},
+			expected: []string{"divisions[0]", "ship_models[0].modules[0].mass", "fleet_builds[0]", "fleet_builds[0].researched_nodes",
+				"fleet_builds[0].ship_models[0].amount"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scenario.Validate()
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			var validationError *ValidationError
+			if !errors.As(err, &validationError) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			fields := []string{}
+			for _, violation := range validationError.Violations {
+				fields = append(fields, violation.Field)
+			}
+			if !reflect.DeepEqual(tt.expected, fields) {
+				t.Errorf("expected violations of %v, got %v", tt.expected, validationError.Violations)
+			}
+		})
+	}
+}
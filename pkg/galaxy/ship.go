@@ -7,6 +7,10 @@ type Ship struct {
 	Destroyed bool     `json:"destroyed"`
 	Name      string   `json:"name"`
 	Owner     string   `json:"owner"` // race owner id
+
+	// The ship model version the ship was built from
+	ShipModelID      string `json:"ship_model_id"`
+	ShipModelVersion int    `json:"ship_model_version"`
 }
 
 // EqualWithoutDamage compares two ships for equality, ignoring the Destroyed field
@@ -18,7 +22,7 @@ func (s *Ship) EqualWithoutDamage(other *Ship) bool {
 	return s.ID == other.ID &&
 		s.Name == other.Name &&
 		s.Owner == other.Owner &&
-		s.Tech == other.Tech
+		s.Tech.Equal(&other.Tech)
 }
 
 func (s *Ship) EqualFields(other *Ship) bool {
@@ -29,7 +33,7 @@ func (s *Ship) EqualFields(other *Ship) bool {
 	return s.ID == other.ID &&
 		s.Name == other.Name &&
 		s.Owner == other.Owner &&
-		s.Tech == other.Tech &&
+		s.Tech.Equal(&other.Tech) &&
 		s.Destroyed == other.Destroyed
 
 }
@@ -1,66 +1,212 @@
 package galaxy
 
 import (
-	"errors"
 	"glaktika.eu/galaktika/pkg/util"
 	"math"
+	"slices"
+)
+
+// Guns heavier than this require the heavy guns component
+const LIGHT_GUN_MAX_MASS = 10
+
+// Defense efficiency of the shield and armour mass compared to the plain defense mass
+const (
+	SHIELD_DEFENSE_FACTOR = 1.5
+	ARMOUR_DEFENSE_FACTOR = 1.25
 )
 
 type ShipModel struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
+	ID string `json:"id"`
+	// Every edit of the design creates a new version, the previous versions stay unchanged
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// Modules the ship is built from
+	Modules []ShipModule `json:"modules"`
+
+	// Summary of the modules. Designs without modules are migrated from these fields.
 	Guns        int     `json:"guns"`
 	OneGunMass  float64 `json:"one_gun_mass"`
 	DefenseMass float64 `json:"defense_mass"`
 	EngineMass  float64 `json:"engine_mass"`
 	CargoMass   float64 `json:"cargo_mass"`
+	ShieldMass  float64 `json:"shield_mass"` // requires the shields component
+	ArmourMass  float64 `json:"armour_mass"` // requires the armour plating component
 	OwnerId     string  `json:"owner_id"`
+}
+
+// Copy returns a copy of the ship model which can be changed without changing this version.
+func (shipModel *ShipModel) Copy() *ShipModel {
+	shipModelCopy := *shipModel
+	shipModelCopy.Modules = slices.Clone(shipModel.Modules)
 
-	validateError error
+	return &shipModelCopy
 }
 
-func (shipModel *ShipModel) CalculateTotalMass() float64 {
-	return float64(shipModel.Guns)*shipModel.OneGunMass +
-		shipModel.DefenseMass +
-		shipModel.EngineMass
+// legacyModules converts the fixed mass fields of the design into modules.
+func (shipModel *ShipModel) legacyModules() []ShipModule {
+	modules := []ShipModule{}
+	if shipModel.Guns > 0 || shipModel.OneGunMass > 0 {
+		modules = append(modules, ShipModule{Type: MODULE_WEAPON, Count: shipModel.Guns, Mass: shipModel.OneGunMass})
+	}
+
+	for _, module := range []ShipModule{
+		{Type: MODULE_DEFENSE, Mass: shipModel.DefenseMass},
+		{Type: MODULE_ENGINE, Mass: shipModel.EngineMass},
+		{Type: MODULE_CARGO, Mass: shipModel.CargoMass},
+		{Type: MODULE_SHIELD, Mass: shipModel.ShieldMass},
+		{Type: MODULE_ARMOUR, Mass: shipModel.ArmourMass},
+	} {
+		if module.Mass != 0 {
+			modules = append(modules, module)
+		}
+	}
+
+	return modules
 }
 
-func (shipModel *ShipModel) GetValidateError() error {
-	return shipModel.validateError
+// EffectiveModules returns the modules of the design, converting the fixed mass fields when there are none.
+func (shipModel *ShipModel) EffectiveModules() []ShipModule {
+	if len(shipModel.Modules) == 0 {
+		return shipModel.legacyModules()
+	}
+
+	return shipModel.Modules
 }
 
-func (shipModel *ShipModel) ValidateModel() bool {
-	if shipModel.OneGunMass > 0 && shipModel.OneGunMass < 1 {
-		shipModel.validateError = errors.New("OneGunMass must be equal to 0 or not lower than 1")
-		return false
+// MigrateModules converts a design described only by the fixed mass fields into modules.
+// The fixed mass fields of a design with modules are refreshed as the summary of its modules.
+func (shipModel *ShipModel) MigrateModules() {
+	if len(shipModel.Modules) == 0 {
+		shipModel.Modules = shipModel.legacyModules()
+		return
+	}
+
+	shipModel.Guns = 0
+	shipModel.OneGunMass = 0
+	shipModel.DefenseMass = 0
+	shipModel.EngineMass = 0
+	shipModel.CargoMass = 0
+	shipModel.ShieldMass = 0
+	shipModel.ArmourMass = 0
+
+	gunsMass := 0.0
+	for _, module := range shipModel.Modules {
+		switch module.Type {
+		case MODULE_WEAPON:
+			shipModel.Guns += module.Count
+			gunsMass += module.TotalMass()
+		case MODULE_DEFENSE:
+			shipModel.DefenseMass += module.Mass
+		case MODULE_ENGINE:
+			shipModel.EngineMass += module.Mass
+		case MODULE_CARGO:
+			shipModel.CargoMass += module.Mass
+		case MODULE_SHIELD:
+			shipModel.ShieldMass += module.Mass
+		case MODULE_ARMOUR:
+			shipModel.ArmourMass += module.Mass
+		}
 	}
 
-	if shipModel.DefenseMass > 0 && shipModel.DefenseMass < 1 {
-		shipModel.validateError = errors.New("DefenseMass must be equal to 0 or not lower than 1")
-		return false
+	if shipModel.Guns > 0 {
+		shipModel.OneGunMass = gunsMass / float64(shipModel.Guns)
 	}
+}
 
-	if shipModel.EngineMass > 0 && shipModel.EngineMass < 1 {
-		shipModel.validateError = errors.New("EngineMass must be equal to 0 or not lower than 1")
-		return false
+// moduleMass returns the total mass of the modules of the given type.
+func (shipModel *ShipModel) moduleMass(modules []ShipModule, moduleType string) float64 {
+	mass := 0.0
+	for _, module := range modules {
+		if module.Type == moduleType {
+			mass += module.TotalMass()
+		}
 	}
 
-	return true
+	return mass
+}
+
+func (shipModel *ShipModel) CalculateTotalMass() float64 {
+	mass := 0.0
+	for _, module := range shipModel.EffectiveModules() {
+		mass += module.TotalMass()
+	}
+
+	return mass
+}
+
+// RequiredComponents returns the tech tree components the ship model is built with.
+func (shipModel *ShipModel) RequiredComponents() []string {
+	var components []string
+	for _, module := range shipModel.EffectiveModules() {
+		component := module.RequiredComponent()
+		if component != "" && !slices.Contains(components, component) {
+			components = append(components, component)
+		}
+	}
+
+	return components
+}
+
+// ValidateModel checks the ship model against the rules and that all the components used by the model are unlocked.
+// Default rules are used when rules is nil. All the violations are returned as a *ValidationError.
+func (shipModel *ShipModel) ValidateModel(rules *ValidationRules, unlocked ComponentSet) error {
+	if rules == nil {
+		rules = DefaultValidationRules()
+	}
+
+	if violations := rules.Check(shipModel, unlocked); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
 }
 
 func (shipModel *ShipModel) CalculateShipTech(t *Technologies) ShipTech {
+	modules := shipModel.EffectiveModules()
 	mass := shipModel.CalculateTotalMass()
 
-	speed := shipModel.EngineMass * t.Engine / mass
-	defense := shipModel.DefenseMass * t.Defense / math.Sqrt(mass)
-	attack := shipModel.OneGunMass * t.Attack
+	speed := shipModel.moduleMass(modules, MODULE_ENGINE) * t.Engine / mass
+	defenseMass := shipModel.moduleMass(modules, MODULE_DEFENSE) +
+		shipModel.moduleMass(modules, MODULE_SHIELD)*SHIELD_DEFENSE_FACTOR +
+		shipModel.moduleMass(modules, MODULE_ARMOUR)*ARMOUR_DEFENSE_FACTOR
+	defense := defenseMass * t.Defense / math.Sqrt(mass)
+	cargoCapacity := shipModel.moduleMass(modules, MODULE_CARGO) * t.Cargo
+	sensors := 0.0
+	if mass > 0 {
+		sensors = shipModel.moduleMass(modules, MODULE_SENSOR) / mass
+	}
+
+	var batteries []WeaponBattery
+	guns := 0
+	for _, module := range modules {
+		if module.Type == MODULE_WEAPON {
+			batteries = append(batteries, WeaponBattery{Guns: module.Count, Attack: module.Mass * t.Attack})
+			guns += module.Count
+		}
+	}
+
+	attack := 0.0
+	switch {
+	case len(batteries) == 1:
+		attack = batteries[0].Attack
+		batteries = nil
+	case len(batteries) > 1 && guns > 0:
+		// average attack of one gun, the batteries keep the attack of every gun
+		for _, battery := range batteries {
+			attack += battery.Attack * float64(battery.Guns)
+		}
+		attack /= float64(guns)
+	}
 
 	return ShipTech{
-		Guns:    shipModel.Guns,
-		Speed:   speed,
-		Defense: defense,
-		Attack:  attack,
-		Mass:    mass,
+		Guns:          guns,
+		Speed:         speed,
+		Defense:       defense,
+		Attack:        attack,
+		CargoCapacity: cargoCapacity,
+		Sensors:       sensors,
+		Mass:          mass,
+		Batteries:     batteries,
 	}
 }
 
@@ -72,12 +218,14 @@ func (shipModel *ShipModel) GenerateShips(t *Technologies, amount int, generator
 	for i := 0; i < amount; i++ {
 		id := generator.NextId()
 		ships[i] = &Ship{
-			VersionID: id,
-			ID:        id,
-			Name:      shipModel.Name,
-			Tech:      shipTech,
-			Owner:     ownerId,
-			Destroyed: false,
+			VersionID:        id,
+			ID:               id,
+			Name:             shipModel.Name,
+			ShipModelID:      shipModel.ID,
+			ShipModelVersion: shipModel.Version,
+			Tech:             shipTech,
+			Owner:            ownerId,
+			Destroyed:        false,
 		}
 	}
 
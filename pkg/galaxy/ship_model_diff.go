@@ -0,0 +1,47 @@
+package galaxy
+
+import "fmt"
+
+// ShipModelChange is one difference between two ship model versions.
+type ShipModelChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// DiffShipModels lists the changes made to the design between the two versions.
+func DiffShipModels(from, to *ShipModel) []ShipModelChange {
+	changes := []ShipModelChange{}
+
+	if from.Name != to.Name {
+		changes = append(changes, ShipModelChange{Field: "name", From: from.Name, To: to.Name})
+	}
+	if from.OwnerId != to.OwnerId {
+		changes = append(changes, ShipModelChange{Field: "owner_id", From: from.OwnerId, To: to.OwnerId})
+	}
+
+	fromModules := from.EffectiveModules()
+	toModules := to.EffectiveModules()
+	for i := 0; i < max(len(fromModules), len(toModules)); i++ {
+		field := fmt.Sprintf("modules[%d]", i)
+		switch {
+		case i >= len(fromModules):
+			changes = append(changes, ShipModelChange{Field: field, From: nil, To: toModules[i]})
+		case i >= len(toModules):
+			changes = append(changes, ShipModelChange{Field: field, From: fromModules[i], To: nil})
+		default:
+			fromModule, toModule := fromModules[i], toModules[i]
+			if fromModule.Type != toModule.Type {
+				changes = append(changes, ShipModelChange{Field: field + ".type", From: fromModule.Type, To: toModule.Type})
+			}
+			if fromModule.Count != toModule.Count {
+				changes = append(changes, ShipModelChange{Field: field + ".count", From: fromModule.Count, To: toModule.Count})
+			}
+			if fromModule.Mass != toModule.Mass {
+				changes = append(changes, ShipModelChange{Field: field + ".mass", From: fromModule.Mass, To: toModule.Mass})
+			}
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,185 @@
+package galaxy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Violation codes of the ship model validation
+const (
+	VIOLATION_UNKNOWN_MODULE_TYPE = "unknown_module_type"
+	VIOLATION_NOT_FINITE          = "not_finite"
+	VIOLATION_NEGATIVE_VALUE      = "negative_value"
+	VIOLATION_MASS_TOO_LOW        = "mass_too_low"
+	VIOLATION_COUNT_NOT_ALLOWED   = "count_not_allowed"
+	VIOLATION_TOO_MANY_GUNS       = "too_many_guns"
+	VIOLATION_TOO_HEAVY           = "too_heavy"
+	VIOLATION_ENGINE_RATIO        = "engine_ratio_too_low"
+	VIOLATION_COMPONENT_LOCKED    = "component_locked"
+)
+
+// Violation is one broken validation rule of a ship model.
+type Violation struct {
+	// path of the invalid field, for example modules[1].mass
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError holds all the violations found by a validation.
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Field + ": " + violation.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// ValidationRules are the ship model rules of a division. Zero limits are not applied.
+type ValidationRules struct {
+	MaxGuns      int     `json:"max_guns,omitempty"`
+	MaxTotalMass float64 `json:"max_total_mass,omitempty"`
+	// minimal share of the engine mass in the total mass of the ship
+	MinEngineRatio float64 `json:"min_engine_ratio,omitempty"`
+	AllowNegative  bool    `json:"allow_negative,omitempty"`
+	// NaN and infinite values are rejected unless allowed
+	AllowNonFinite bool `json:"allow_non_finite,omitempty"`
+}
+
+// DefaultValidationRules returns the rules used when a division does not configure its own.
+func DefaultValidationRules() *ValidationRules {
+	return &ValidationRules{}
+}
+
+func (rules *ValidationRules) Validate() error {
+	if rules.MaxGuns < 0 {
+		return errors.New("max_guns must not be negative")
+	}
+
+	if rules.MaxTotalMass < 0 || math.IsNaN(rules.MaxTotalMass) {
+		return errors.New("max_total_mass must not be negative")
+	}
+
+	if rules.MinEngineRatio < 0 || rules.MinEngineRatio > 1 || math.IsNaN(rules.MinEngineRatio) {
+		return errors.New("min_engine_ratio must be between 0 and 1")
+	}
+
+	return nil
+}
+
+func (rules *ValidationRules) numberViolations(field string, value float64) []Violation {
+	if !rules.AllowNonFinite && (math.IsNaN(value) || math.IsInf(value, 0)) {
+		return []Violation{{Field: field, Code: VIOLATION_NOT_FINITE, Message: "must be a finite number"}}
+	}
+
+	if !rules.AllowNegative && value < 0 {
+		return []Violation{{Field: field, Code: VIOLATION_NEGATIVE_VALUE, Message: "must not be negative"}}
+	}
+
+	return nil
+}
+
+// moduleViolations checks one module, the field paths start with the given prefix.
+func (rules *ValidationRules) moduleViolations(prefix string, module ShipModule) []Violation {
+	var violations []Violation
+
+	known := false
+	for _, moduleType := range ModuleTypes {
+		known = known || module.Type == moduleType
+	}
+	if !known {
+		violations = append(violations, Violation{
+			Field:   prefix + ".type",
+			Code:    VIOLATION_UNKNOWN_MODULE_TYPE,
+			Message: fmt.Sprintf("unknown module type %q", module.Type),
+		})
+	}
+
+	massViolations := rules.numberViolations(prefix+".mass", module.Mass)
+	violations = append(violations, massViolations...)
+	if len(massViolations) == 0 && module.Mass > 0 && module.Mass < 1 {
+		violations = append(violations, Violation{
+			Field:   prefix + ".mass",
+			Code:    VIOLATION_MASS_TOO_LOW,
+			Message: "must be equal to 0 or not lower than 1",
+		})
+	}
+
+	if module.Type != MODULE_WEAPON && module.Count != 0 {
+		violations = append(violations, Violation{
+			Field:   prefix + ".count",
+			Code:    VIOLATION_COUNT_NOT_ALLOWED,
+			Message: fmt.Sprintf("%s module can not have count", module.Type),
+		})
+	} else if module.Count < 0 && !rules.AllowNegative {
+		violations = append(violations, Violation{Field: prefix + ".count", Code: VIOLATION_NEGATIVE_VALUE, Message: "must not be negative"})
+	}
+
+	return violations
+}
+
+// Check returns all the rule violations of the ship model. The components check
+// is skipped when unlocked is nil, as the components depend on the fleet build.
+func (rules *ValidationRules) Check(shipModel *ShipModel, unlocked ComponentSet) []Violation {
+	var violations []Violation
+
+	modules := shipModel.EffectiveModules()
+	for i, module := range modules {
+		violations = append(violations, rules.moduleViolations(fmt.Sprintf("modules[%d]", i), module)...)
+	}
+
+	guns := 0
+	for _, module := range modules {
+		if module.Type == MODULE_WEAPON {
+			guns += module.Count
+		}
+	}
+	if rules.MaxGuns > 0 && guns > rules.MaxGuns {
+		violations = append(violations, Violation{
+			Field:   "guns",
+			Code:    VIOLATION_TOO_MANY_GUNS,
+			Message: fmt.Sprintf("%d guns exceed the limit of %d", guns, rules.MaxGuns),
+		})
+	}
+
+	mass := shipModel.CalculateTotalMass()
+	if rules.MaxTotalMass > 0 && mass > rules.MaxTotalMass {
+		violations = append(violations, Violation{
+			Field:   "mass",
+			Code:    VIOLATION_TOO_HEAVY,
+			Message: fmt.Sprintf("total mass %g exceeds the limit of %g", mass, rules.MaxTotalMass),
+		})
+	}
+
+	if rules.MinEngineRatio > 0 && mass > 0 {
+		ratio := shipModel.moduleMass(modules, MODULE_ENGINE) / mass
+		if ratio < rules.MinEngineRatio {
+			violations = append(violations, Violation{
+				Field:   "engine_mass",
+				Code:    VIOLATION_ENGINE_RATIO,
+				Message: fmt.Sprintf("engine ratio %g is lower than %g", ratio, rules.MinEngineRatio),
+			})
+		}
+	}
+
+	if unlocked != nil {
+		for _, component := range shipModel.RequiredComponents() {
+			if !unlocked[component] {
+				violations = append(violations, Violation{
+					Field:   "modules",
+					Code:    VIOLATION_COMPONENT_LOCKED,
+					Message: fmt.Sprintf("component %s is not unlocked", component),
+				})
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,114 @@
+package galaxy
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func violationCodes(err error) []string {
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) {
+		return nil
+	}
+
+	codes := []string{}
+	for _, violation := range validationError.Violations {
+		codes = append(codes, violation.Field+":"+violation.Code)
+	}
+
+	return codes
+}
+
+func TestValidateModel_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    *ShipModel
+		rules    *ValidationRules
+		unlocked ComponentSet
+		expected []string
+	}{
+		{
+			name:     "valid with default rules",
+			model:    &ShipModel{Guns: 2, OneGunMass: 2, DefenseMass: 4, EngineMass: 4},
+			expected: nil,
+		},
+		{
+			name: "returns all the violations",
+			model: &ShipModel{Modules: []ShipModule{
+				{Type: MODULE_WEAPON, Count: 1, Mass: 0.5},
+				{Type: MODULE_DEFENSE, Mass: -2},
+				{Type: MODULE_ENGINE, Count: 2, Mass: math.Inf(1)},
+				{Type: "cloak", Mass: 3},
+			}},
+			expected: []string{
+				"modules[0].mass:mass_too_low",
+				"modules[1].mass:negative_value",
+				"modules[2].mass:not_finite",
+				"modules[2].count:count_not_allowed",
+				"modules[3].type:unknown_module_type",
+			},
+		},
+		{
+			name:     "negative values allowed by the division",
+			model:    &ShipModel{Modules: []ShipModule{{Type: MODULE_DEFENSE, Mass: -2}, {Type: MODULE_ENGINE, Mass: 4}}},
+			rules:    &ValidationRules{AllowNegative: true},
+			expected: nil,
+		},
+		{
+			name:     "NaN rejected",
+			model:    &ShipModel{Modules: []ShipModule{{Type: MODULE_ENGINE, Mass: math.NaN()}}},
+			expected: []string{"modules[0].mass:not_finite"},
+		},
+		{
+			name:     "division limits",
+			model:    &ShipModel{Guns: 6, OneGunMass: 2, DefenseMass: 10, EngineMass: 2},
+			rules:    &ValidationRules{MaxGuns: 4, MaxTotalMass: 20, MinEngineRatio: 0.25},
+			expected: []string{"guns:too_many_guns", "mass:too_heavy", "engine_mass:engine_ratio_too_low"},
+		},
+		{
+			name:     "division limits kept",
+			model:    &ShipModel{Guns: 4, OneGunMass: 2, DefenseMass: 2, EngineMass: 6},
+			rules:    &ValidationRules{MaxGuns: 4, MaxTotalMass: 20, MinEngineRatio: 0.25},
+			expected: nil,
+		},
+		{
+			name:     "locked components",
+			model:    &ShipModel{Guns: 1, OneGunMass: 12, ShieldMass: 2},
+			unlocked: ComponentSet{},
+			expected: []string{"modules:component_locked", "modules:component_locked"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := violationCodes(tt.model.ValidateModel(tt.rules, tt.unlocked))
+			if !reflect.DeepEqual(codes, tt.expected) {
+				t.Errorf("violations = %v; want %v", codes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidationRules_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   ValidationRules
+		wantErr bool
+	}{
+		{name: "empty rules", rules: ValidationRules{}},
+		{name: "all limits", rules: ValidationRules{MaxGuns: 10, MaxTotalMass: 100, MinEngineRatio: 0.1}},
+		{name: "negative max guns", rules: ValidationRules{MaxGuns: -1}, wantErr: true},
+		{name: "negative max mass", rules: ValidationRules{MaxTotalMass: -1}, wantErr: true},
+		{name: "engine ratio above 1", rules: ValidationRules{MinEngineRatio: 1.5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
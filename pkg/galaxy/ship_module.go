@@ -0,0 +1,48 @@
+package galaxy
+
+// Ship module types
+const (
+	MODULE_WEAPON  = "weapon"
+	MODULE_DEFENSE = "defense"
+	MODULE_SHIELD  = "shield" // requires the shields component
+	MODULE_ARMOUR  = "armour" // requires the armour plating component
+	MODULE_ENGINE  = "engine"
+	MODULE_CARGO   = "cargo"
+	MODULE_SENSOR  = "sensor"
+)
+
+var ModuleTypes = []string{MODULE_WEAPON, MODULE_DEFENSE, MODULE_SHIELD, MODULE_ARMOUR, MODULE_ENGINE, MODULE_CARGO, MODULE_SENSOR}
+
+// ShipModule is one part of a ship design.
+type ShipModule struct {
+	Type string `json:"type"`
+	// weapon: number of guns in the battery
+	Count int `json:"count,omitempty"`
+	// weapon: mass of one gun, other modules: mass of the module
+	Mass float64 `json:"mass"`
+}
+
+// TotalMass returns the mass the module adds to the ship.
+func (module ShipModule) TotalMass() float64 {
+	if module.Type == MODULE_WEAPON {
+		return float64(module.Count) * module.Mass
+	}
+
+	return module.Mass
+}
+
+// RequiredComponent returns the tech tree component needed to build the module, or an empty string.
+func (module ShipModule) RequiredComponent() string {
+	switch module.Type {
+	case MODULE_SHIELD:
+		return COMPONENT_SHIELDS
+	case MODULE_ARMOUR:
+		return COMPONENT_ARMOUR_PLATING
+	case MODULE_WEAPON:
+		if module.Mass > LIGHT_GUN_MAX_MASS {
+			return COMPONENT_HEAVY_GUNS
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,127 @@
+package galaxy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateModules_Legacy(t *testing.T) {
+	shipModel := &ShipModel{Guns: 2, OneGunMass: 3, DefenseMass: 4, EngineMass: 5, CargoMass: 1, ShieldMass: 2}
+
+	shipModel.MigrateModules()
+
+	expected := []ShipModule{
+		{Type: MODULE_WEAPON, Count: 2, Mass: 3},
+		{Type: MODULE_DEFENSE, Mass: 4},
+		{Type: MODULE_ENGINE, Mass: 5},
+		{Type: MODULE_CARGO, Mass: 1},
+		{Type: MODULE_SHIELD, Mass: 2},
+	}
+	if !reflect.DeepEqual(expected, shipModel.Modules) {
+		t.Errorf("expected modules %+v, got %+v", expected, shipModel.Modules)
+	}
+
+	// the migrated design has the same ship tech as the legacy one
+	legacy := &ShipModel{Guns: 2, OneGunMass: 3, DefenseMass: 4, EngineMass: 5, CargoMass: 1, ShieldMass: 2}
+	if !reflect.DeepEqual(legacy.CalculateShipTech(NewTechnologies()), shipModel.CalculateShipTech(NewTechnologies())) {
+		t.Errorf("migrated ship tech differs from the legacy ship tech")
+	}
+}
+
+func TestMigrateModules_SummaryOfModules(t *testing.T) {
+	shipModel := &ShipModel{
+		Guns: 100, // stale summary is refreshed
+		Modules: []ShipModule{
+			{Type: MODULE_WEAPON, Count: 2, Mass: 4},
+			{Type: MODULE_WEAPON, Count: 6, Mass: 1},
+			{Type: MODULE_ARMOUR, Mass: 3},
+			{Type: MODULE_SENSOR, Mass: 2},
+		},
+	}
+
+	shipModel.MigrateModules()
+
+	if shipModel.Guns != 8 || shipModel.OneGunMass != 1.75 || shipModel.ArmourMass != 3 {
+		t.Errorf("unexpected summary %+v", shipModel)
+	}
+}
+
+func TestCalculateShipTech_MultipleBatteries(t *testing.T) {
+	shipModel := &ShipModel{
+		Modules: []ShipModule{
+			{Type: MODULE_WEAPON, Count: 1, Mass: 6},
+			{Type: MODULE_WEAPON, Count: 3, Mass: 2},
+			{Type: MODULE_ENGINE, Mass: 4},
+			{Type: MODULE_SENSOR, Mass: 4},
+		},
+	}
+
+	tech := shipModel.CalculateShipTech(NewTechnologies())
+
+	expected := ShipTech{
+		Attack:  3, // (6 + 3*2) / 4 guns
+		Guns:    4,
+		Speed:   0.2,
+		Sensors: 0.2,
+		Mass:    20,
+		Batteries: []WeaponBattery{
+			{Guns: 1, Attack: 6},
+			{Guns: 3, Attack: 2},
+		},
+	}
+	if !reflect.DeepEqual(expected, tech) {
+		t.Errorf("expected %+v, got %+v", expected, tech)
+	}
+
+	for shot, attack := range []float64{6, 2, 2, 2} {
+		if tech.GunAttack(shot) != attack {
+			t.Errorf("GunAttack(%d) = %v; want %v", shot, tech.GunAttack(shot), attack)
+		}
+	}
+}
+
+func TestValidateModel_Modules(t *testing.T) {
+	tests := []struct {
+		name     string
+		modules  []ShipModule
+		unlocked ComponentSet
+		valid    bool
+	}{
+		{
+			name:    "light weapons",
+			modules: []ShipModule{{Type: MODULE_WEAPON, Count: 2, Mass: 10}, {Type: MODULE_ENGINE, Mass: 2}},
+			valid:   true,
+		},
+		{
+			name:     "heavy weapon battery is locked",
+			modules:  []ShipModule{{Type: MODULE_WEAPON, Count: 1, Mass: 11}},
+			unlocked: ComponentSet{},
+			valid:    false,
+		},
+		{
+			name:     "heavy weapon battery unlocked",
+			modules:  []ShipModule{{Type: MODULE_WEAPON, Count: 1, Mass: 11}},
+			unlocked: ComponentSet{COMPONENT_HEAVY_GUNS: true},
+			valid:    true,
+		},
+		{
+			name:    "unknown module type",
+			modules: []ShipModule{{Type: "cloak", Mass: 5}},
+			valid:   false,
+		},
+		{
+			name:    "too light module",
+			modules: []ShipModule{{Type: MODULE_SENSOR, Mass: 0.5}},
+			valid:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shipModel := &ShipModel{Modules: tt.modules}
+			if err := shipModel.ValidateModel(nil, tt.unlocked); (err == nil) != tt.valid {
+				t.Errorf("ValidateModel() = %v; want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -1,10 +1,44 @@
 package galaxy
 
+import "slices"
+
+// WeaponBattery holds the attack of the guns of one weapon module.
+type WeaponBattery struct {
+	Guns   int     `json:"guns"`
+	Attack float64 `json:"attack"`
+}
+
 type ShipTech struct {
 	Attack        float64 `json:"attack"`
 	Guns          int     `json:"guns"`
 	Defense       float64 `json:"defense"`
 	Speed         float64 `json:"speed"`
 	CargoCapacity float64 `json:"cargo_capacity"`
+	Sensors       float64 `json:"sensors"`
 	Mass          float64 `json:"mass"`
+	// Set only when the ship carries more than one weapon battery, Attack is the average of the guns then.
+	Batteries []WeaponBattery `json:"batteries,omitempty"`
+}
+
+// GunAttack returns the attack of the gun making the shot with the given index within one salvo.
+func (tech *ShipTech) GunAttack(shot int) float64 {
+	for _, battery := range tech.Batteries {
+		if shot < battery.Guns {
+			return battery.Attack
+		}
+		shot -= battery.Guns
+	}
+
+	return tech.Attack
+}
+
+func (tech *ShipTech) Equal(other *ShipTech) bool {
+	return tech.Attack == other.Attack &&
+		tech.Guns == other.Guns &&
+		tech.Defense == other.Defense &&
+		tech.Speed == other.Speed &&
+		tech.CargoCapacity == other.CargoCapacity &&
+		tech.Sensors == other.Sensors &&
+		tech.Mass == other.Mass &&
+		slices.Equal(tech.Batteries, other.Batteries)
 }
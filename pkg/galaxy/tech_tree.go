@@ -0,0 +1,146 @@
+package galaxy
+
+import "fmt"
+
+// Ship components which must be unlocked in the tech tree before a ship model may use them
+const (
+	COMPONENT_SHIELDS        = "shields"
+	COMPONENT_ARMOUR_PLATING = "armour_plating"
+	COMPONENT_HEAVY_GUNS     = "heavy_guns"
+)
+
+// ComponentSet is a set of unlocked ship components
+type ComponentSet map[string]bool
+
+// TechNode is a named technology of the tech tree. Researching it costs resources
+// and requires all the prerequisite nodes to be researched too.
+type TechNode struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Cost          float64  `json:"cost"`
+	Prerequisites []string `json:"prerequisites"`
+	Unlocks       []string `json:"unlocks"` // ship components
+}
+
+type TechTree struct {
+	Nodes []*TechNode `json:"nodes"`
+
+	nodeMap map[string]*TechNode
+}
+
+// NewTechTree creates a tech tree and checks that node ids are unique,
+// prerequisites are known and do not form cycles.
+func NewTechTree(nodes []*TechNode) (*TechTree, error) {
+	nodeMap := make(map[string]*TechNode)
+	for _, node := range nodes {
+		if _, exists := nodeMap[node.ID]; exists {
+			return nil, fmt.Errorf("duplicate tech node %q", node.ID)
+		}
+		nodeMap[node.ID] = node
+	}
+
+	for _, node := range nodes {
+		for _, prerequisite := range node.Prerequisites {
+			if _, exists := nodeMap[prerequisite]; !exists {
+				return nil, fmt.Errorf("tech node %q has unknown prerequisite %q", node.ID, prerequisite)
+			}
+		}
+	}
+
+	tree := &TechTree{Nodes: nodes, nodeMap: nodeMap}
+
+	for _, node := range nodes {
+		if tree.dependsOn(node, node.ID, make(map[string]bool)) {
+			return nil, fmt.Errorf("tech node %q depends on itself", node.ID)
+		}
+	}
+
+	return tree, nil
+}
+
+func (tree *TechTree) dependsOn(node *TechNode, id string, visited map[string]bool) bool {
+	for _, prerequisite := range node.Prerequisites {
+		if prerequisite == id {
+			return true
+		}
+		if visited[prerequisite] {
+			continue
+		}
+		visited[prerequisite] = true
+		if tree.dependsOn(tree.nodeMap[prerequisite], id, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefaultTechTree returns the tech tree used by all divisions.
+func DefaultTechTree() *TechTree {
+	tree, err := NewTechTree([]*TechNode{
+		{ID: "metallurgy", Name: "Metallurgy", Cost: 40, Prerequisites: []string{}, Unlocks: []string{COMPONENT_ARMOUR_PLATING}},
+		{ID: "energy_fields", Name: "Energy Fields", Cost: 60, Prerequisites: []string{}, Unlocks: []string{}},
+		{ID: "deflector_shields", Name: "Deflector Shields", Cost: 80, Prerequisites: []string{"energy_fields"}, Unlocks: []string{COMPONENT_SHIELDS}},
+		{ID: "heavy_ordnance", Name: "Heavy Ordnance", Cost: 80, Prerequisites: []string{"metallurgy"}, Unlocks: []string{COMPONENT_HEAVY_GUNS}},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return tree
+}
+
+func (tree *TechTree) Get(id string) *TechNode {
+	return tree.nodeMap[id]
+}
+
+// ValidateResearched checks that all the researched nodes exist, are not repeated
+// and have their prerequisites researched.
+func (tree *TechTree) ValidateResearched(researched []string) error {
+	researchedSet := make(map[string]bool)
+	for _, id := range researched {
+		if tree.Get(id) == nil {
+			return fmt.Errorf("unknown tech node %q", id)
+		}
+		if researchedSet[id] {
+			return fmt.Errorf("tech node %q is researched more than once", id)
+		}
+		researchedSet[id] = true
+	}
+
+	for _, id := range researched {
+		for _, prerequisite := range tree.Get(id).Prerequisites {
+			if !researchedSet[prerequisite] {
+				return fmt.Errorf("tech node %q requires %q to be researched", id, prerequisite)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResearchCost returns the resources needed to research the given nodes.
+func (tree *TechTree) ResearchCost(researched []string) float64 {
+	cost := 0.0
+	for _, id := range researched {
+		if node := tree.Get(id); node != nil {
+			cost += node.Cost
+		}
+	}
+
+	return cost
+}
+
+// UnlockedComponents returns the ship components unlocked by the researched nodes.
+func (tree *TechTree) UnlockedComponents(researched []string) ComponentSet {
+	unlocked := make(ComponentSet)
+	for _, id := range researched {
+		if node := tree.Get(id); node != nil {
+			for _, component := range node.Unlocks {
+				unlocked[component] = true
+			}
+		}
+	}
+
+	return unlocked
+}
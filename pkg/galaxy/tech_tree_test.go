@@ -0,0 +1,131 @@
+package galaxy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewTechTreeValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []*TechNode
+	}{
+		{
+			name:  "duplicate node",
+			nodes: []*TechNode{{ID: "a"}, {ID: "a"}},
+		},
+		{
+			name:  "unknown prerequisite",
+			nodes: []*TechNode{{ID: "a", Prerequisites: []string{"b"}}},
+		},
+		{
+			name: "cycle",
+			nodes: []*TechNode{
+				{ID: "a", Prerequisites: []string{"c"}},
+				{ID: "b", Prerequisites: []string{"a"}},
+				{ID: "c", Prerequisites: []string{"b"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := NewTechTree(tt.nodes)
+			if err == nil {
+				t.Errorf("expected error, got tree %+v", tree)
+			}
+		})
+	}
+}
+
+func TestTechTreeResearch(t *testing.T) {
+	tree := DefaultTechTree()
+
+	tests := []struct {
+		name       string
+		researched []string
+		wantError  bool
+		cost       float64
+		unlocked   ComponentSet
+	}{
+		{
+			name:       "nothing researched",
+			researched: nil,
+			cost:       0,
+			unlocked:   ComponentSet{},
+		},
+		{
+			name:       "node with prerequisite",
+			researched: []string{"metallurgy", "heavy_ordnance"},
+			cost:       120,
+			unlocked:   ComponentSet{COMPONENT_ARMOUR_PLATING: true, COMPONENT_HEAVY_GUNS: true},
+		},
+		{
+			name:       "missing prerequisite",
+			researched: []string{"deflector_shields"},
+			wantError:  true,
+		},
+		{
+			name:       "unknown node",
+			researched: []string{"warp_drive"},
+			wantError:  true,
+		},
+		{
+			name:       "repeated node",
+			researched: []string{"metallurgy", "metallurgy"},
+			wantError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tree.ValidateResearched(tt.researched)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ValidateResearched() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if cost := tree.ResearchCost(tt.researched); cost != tt.cost {
+				t.Errorf("ResearchCost() = %v; want %v", cost, tt.cost)
+			}
+			if unlocked := tree.UnlockedComponents(tt.researched); !reflect.DeepEqual(unlocked, tt.unlocked) {
+				t.Errorf("UnlockedComponents() = %v; want %v", unlocked, tt.unlocked)
+			}
+		})
+	}
+}
+
+func TestValidateModelComponents(t *testing.T) {
+	shipModel := &ShipModel{Guns: 1, OneGunMass: 12, DefenseMass: 2, ShieldMass: 4, EngineMass: 2}
+
+	if err := shipModel.ValidateModel(nil, ComponentSet{COMPONENT_SHIELDS: true}); err == nil {
+		t.Errorf("expected heavy guns to be rejected")
+	}
+
+	if err := shipModel.ValidateModel(nil, ComponentSet{COMPONENT_SHIELDS: true, COMPONENT_HEAVY_GUNS: true}); err != nil {
+		t.Errorf("expected model to be valid, got %v", err)
+	}
+
+	fleetBuild := &FleetBuild{ResearchedNodes: []string{"energy_fields", "deflector_shields", "metallurgy", "heavy_ordnance"}}
+	if err := fleetBuild.ValidateShipModel(shipModel); err != nil {
+		t.Errorf("expected model to be valid for the fleet build, got %v", err)
+	}
+	if statistics := fleetBuild.CalculateStatistics(1000); statistics.UsedResourcesForTechnologies != 260 {
+		t.Errorf("expected 260 resources used for technologies, got %d", statistics.UsedResourcesForTechnologies)
+	}
+}
+
+func TestCalculateShipTech_ShieldsAndArmour(t *testing.T) {
+	shipModel := &ShipModel{DefenseMass: 2, ShieldMass: 4, ArmourMass: 4, EngineMass: 6}
+
+	result := shipModel.CalculateShipTech(NewTechnologies())
+
+	// defense = (2 + 4*1.5 + 4*1.25) / sqrt(16) = 13 / 4
+	if result.Defense != 3.25 {
+		t.Errorf("expected defense 3.25, got %v", result.Defense)
+	}
+	if result.Mass != 16 {
+		t.Errorf("expected mass 16, got %v", result.Mass)
+	}
+}
@@ -24,3 +24,33 @@ func (t *Technologies) Research(attackResources, defenseResources, engineResourc
 	t.Engine += engineResources / ONE_TECH_RESOURCES
 	t.Cargo += cargoResources / ONE_TECH_RESOURCES
 }
+
+// ResearchWithRules raises the technologies according to the research curves of the rules.
+// Linear research is used when the rules are nil.
+func (t *Technologies) ResearchWithRules(rules *ResearchRules, attackResources, defenseResources, engineResources, cargoResources float64) {
+	if rules == nil {
+		t.Research(attackResources, defenseResources, engineResources, cargoResources)
+		return
+	}
+
+	t.Attack += rules.Attack.LevelGain(attackResources)
+	t.Defense += rules.Defense.LevelGain(defenseResources)
+	t.Engine += rules.Engine.LevelGain(engineResources)
+	t.Cargo += rules.Cargo.LevelGain(cargoResources)
+}
+
+// Level returns the level of the technology with the given name.
+func (t *Technologies) Level(technology string) float64 {
+	switch technology {
+	case TECHNOLOGY_ATTACK:
+		return t.Attack
+	case TECHNOLOGY_DEFENSE:
+		return t.Defense
+	case TECHNOLOGY_ENGINE:
+		return t.Engine
+	case TECHNOLOGY_CARGO:
+		return t.Cargo
+	}
+
+	return 0
+}
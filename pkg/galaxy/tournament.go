@@ -0,0 +1,241 @@
+package galaxy
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tournament formats
+const (
+	TOURNAMENT_ROUND_ROBIN        = "round_robin"
+	TOURNAMENT_SINGLE_ELIMINATION = "single_elimination"
+)
+
+// Tournament statuses
+const (
+	TOURNAMENT_STATUS_SCHEDULED = "scheduled"
+	TOURNAMENT_STATUS_FINISHED  = "finished"
+)
+
+// How the winner of a match was decided
+const (
+	MATCH_DECIDED_BY_DESTRUCTION = "destruction" // the other fleet was destroyed
+	MATCH_DECIDED_BY_SURVIVORS   = "survivors"   // more ships survived the stalemate
+	MATCH_DECIDED_BY_SEED        = "seed"        // the better seed advances after a draw in the bracket
+	MATCH_DECIDED_BY_BYE         = "bye"
+)
+
+// Points of the standings
+const (
+	TOURNAMENT_POINTS_WIN  = 3
+	TOURNAMENT_POINTS_DRAW = 1
+)
+
+// TournamentParticipant is a race competing with the fleet it has in the division.
+type TournamentParticipant struct {
+	RaceId  string `json:"race_id"`
+	FleetId string `json:"fleet_id"`
+	// position in the bracket, 1 is the best
+	Seed int `json:"seed"`
+}
+
+// TournamentMatch is one battle of two participants. RaceB is empty for a bye.
+type TournamentMatch struct {
+	Round    int    `json:"round"`
+	RaceA    string `json:"race_a"`
+	RaceB    string `json:"race_b,omitempty"`
+	BattleId string `json:"battle_id,omitempty"`
+	Played   bool   `json:"played"`
+	// empty for a draw
+	WinnerId  string `json:"winner_id,omitempty"`
+	DecidedBy string `json:"decided_by,omitempty"`
+	// ships of each side which survived the battle
+	SurvivorsA int `json:"survivors_a"`
+	SurvivorsB int `json:"survivors_b"`
+	// ships of each side before the battle
+	ShipsA int `json:"ships_a"`
+	ShipsB int `json:"ships_b"`
+}
+
+func (match *TournamentMatch) IsBye() bool {
+	return match.RaceB == ""
+}
+
+// Standing is a row of the tournament standings table.
+type Standing struct {
+	Rank   int    `json:"rank"`
+	RaceId string `json:"race_id"`
+	Played int    `json:"played"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+	Points int    `json:"points"`
+	// tiebreakers: enemy ships destroyed minus own ships lost, then enemy ships destroyed
+	ShipsDestroyed int `json:"ships_destroyed"`
+	ShipsLost      int `json:"ships_lost"`
+}
+
+func (standing *Standing) ShipDifference() int {
+	return standing.ShipsDestroyed - standing.ShipsLost
+}
+
+type Tournament struct {
+	ID         string `json:"id"`
+	DivisionId string `json:"division_id"`
+	Name       string `json:"name"`
+	Format     string `json:"format"`
+	// seed of the battles and of the bracket
+	Seed         uint64                   `json:"seed"`
+	Status       string                   `json:"status"`
+	Participants []*TournamentParticipant `json:"participants"`
+	Matches      []*TournamentMatch       `json:"matches"`
+	Standings    []*Standing              `json:"standings"`
+	// the winner of the bracket, the leader of the round-robin standings
+	WinnerId   string     `json:"winner_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func (tournament *Tournament) Validate() error {
+	if strings.TrimSpace(tournament.Name) == "" {
+		return errors.New("name is required")
+	}
+
+	if tournament.Format != TOURNAMENT_ROUND_ROBIN && tournament.Format != TOURNAMENT_SINGLE_ELIMINATION {
+		return fmt.Errorf("unknown tournament format %q", tournament.Format)
+	}
+
+	if len(tournament.Participants) < 2 {
+		return errors.New("tournament needs at least 2 participants")
+	}
+
+	return nil
+}
+
+// ScheduleRoundRobin pairs every participant with every other once, the circle method spreads
+// the matches over the rounds so that nobody plays twice in a round.
+func ScheduleRoundRobin(participants []*TournamentParticipant) []*TournamentMatch {
+	races := make([]string, len(participants))
+	for i, participant := range participants {
+		races[i] = participant.RaceId
+	}
+	if len(races)%2 == 1 {
+		races = append(races, "")
+	}
+
+	matches := []*TournamentMatch{}
+	n := len(races)
+	for round := 1; round < n; round++ {
+		for i := 0; i < n/2; i++ {
+			a, b := races[i], races[n-1-i]
+			if a == "" || b == "" {
+				continue
+			}
+			matches = append(matches, &TournamentMatch{Round: round, RaceA: a, RaceB: b})
+		}
+
+		// the first race stays, the others rotate
+		races = append([]string{races[0], races[n-1]}, races[1:n-1]...)
+	}
+
+	return matches
+}
+
+// ScheduleBracket returns the first round of the single elimination bracket. The best seeds meet
+// the worst seeds, the best seeds get the byes when the participants do not fill the bracket.
+func ScheduleBracket(participants []*TournamentParticipant) []*TournamentMatch {
+	seeded := slices.Clone(participants)
+	slices.SortFunc(seeded, func(a, b *TournamentParticipant) int {
+		return cmp.Compare(a.Seed, b.Seed)
+	})
+
+	size := 1
+	for size < len(seeded) {
+		size *= 2
+	}
+
+	matches := make([]*TournamentMatch, 0, size/2)
+	for i := 0; i < size/2; i++ {
+		match := &TournamentMatch{Round: 1, RaceA: seeded[i].RaceId}
+		if opponent := size - 1 - i; opponent < len(seeded) {
+			match.RaceB = seeded[opponent].RaceId
+		}
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+// NextBracketRound pairs the winners of the matches of the finished round, the winners
+// of the neighbouring matches meet. Returns nil when the round was the final.
+func NextBracketRound(round []*TournamentMatch) []*TournamentMatch {
+	if len(round) < 2 {
+		return nil
+	}
+
+	next := make([]*TournamentMatch, 0, len(round)/2)
+	for i := 0; i+1 < len(round); i += 2 {
+		next = append(next, &TournamentMatch{Round: round[i].Round + 1, RaceA: round[i].WinnerId, RaceB: round[i+1].WinnerId})
+	}
+
+	return next
+}
+
+// CalculateStandings sums the played matches of the participants. The standings are ordered by the points,
+// the ship difference, the destroyed ships, then by the race ID.
+func CalculateStandings(participants []*TournamentParticipant, matches []*TournamentMatch) []*Standing {
+	standings := make([]*Standing, len(participants))
+	byRace := make(map[string]*Standing, len(participants))
+	for i, participant := range participants {
+		standings[i] = &Standing{RaceId: participant.RaceId}
+		byRace[participant.RaceId] = standings[i]
+	}
+
+	for _, match := range matches {
+		if !match.Played || match.IsBye() {
+			continue
+		}
+
+		a, b := byRace[match.RaceA], byRace[match.RaceB]
+		a.Played++
+		b.Played++
+		a.ShipsDestroyed += match.ShipsB - match.SurvivorsB
+		a.ShipsLost += match.ShipsA - match.SurvivorsA
+		b.ShipsDestroyed += match.ShipsA - match.SurvivorsA
+		b.ShipsLost += match.ShipsB - match.SurvivorsB
+
+		switch match.WinnerId {
+		case match.RaceA:
+			a.Wins++
+			b.Losses++
+		case match.RaceB:
+			b.Wins++
+			a.Losses++
+		default:
+			a.Draws++
+			b.Draws++
+		}
+	}
+
+	for _, standing := range standings {
+		standing.Points = standing.Wins*TOURNAMENT_POINTS_WIN + standing.Draws*TOURNAMENT_POINTS_DRAW
+	}
+
+	slices.SortFunc(standings, func(a, b *Standing) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.ShipDifference(), a.ShipDifference()),
+			cmp.Compare(b.ShipsDestroyed, a.ShipsDestroyed),
+			strings.Compare(a.RaceId, b.RaceId),
+		)
+	})
+	for i, standing := range standings {
+		standing.Rank = i + 1
+	}
+
+	return standings
+}
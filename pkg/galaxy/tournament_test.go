@@ -0,0 +1,136 @@
+package galaxy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestParticipants(n int) []*TournamentParticipant {
+	participants := make([]*TournamentParticipant, n)
+	for i := range participants {
+		participants[i] = &TournamentParticipant{RaceId: fmt.Sprintf("r%d", i+1), Seed: i + 1}
+	}
+
+	return participants
+}
+
+func TestScheduleRoundRobin(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 6} {
+		t.Run(fmt.Sprintf("%d participants", n), func(t *testing.T) {
+			matches := ScheduleRoundRobin(newTestParticipants(n))
+			if len(matches) != n*(n-1)/2 {
+				t.Fatalf("expected %d matches, got %d", n*(n-1)/2, len(matches))
+			}
+
+			pairs := map[string]bool{}
+			inRound := map[string]bool{}
+			for _, match := range matches {
+				pair := match.RaceA + "-" + match.RaceB
+				if match.RaceB < match.RaceA {
+					pair = match.RaceB + "-" + match.RaceA
+				}
+				if pairs[pair] || match.RaceA == match.RaceB {
+					t.Errorf("unexpected match %s", pair)
+				}
+				pairs[pair] = true
+
+				for _, race := range []string{match.RaceA, match.RaceB} {
+					key := fmt.Sprintf("%d/%s", match.Round, race)
+					if inRound[key] {
+						t.Errorf("race %s plays twice in round %d", race, match.Round)
+					}
+					inRound[key] = true
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleBracket(t *testing.T) {
+	tests := []struct {
+		name         string
+		participants int
+		wantMatches  []string
+	}{
+		{name: "full bracket", participants: 4, wantMatches: []string{"r1-r4", "r2-r3"}},
+		{name: "best seeds get byes", participants: 5, wantMatches: []string{"r1-", "r2-", "r3-", "r4-r5"}},
+		{name: "final only", participants: 2, wantMatches: []string{"r1-r2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := ScheduleBracket(newTestParticipants(tt.participants))
+			if len(matches) != len(tt.wantMatches) {
+				t.Fatalf("expected %d matches, got %d", len(tt.wantMatches), len(matches))
+			}
+			for i, match := range matches {
+				if got := match.RaceA + "-" + match.RaceB; got != tt.wantMatches[i] {
+					t.Errorf("match %d: got %s, want %s", i, got, tt.wantMatches[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextBracketRound(t *testing.T) {
+	round := []*TournamentMatch{
+		{Round: 1, RaceA: "r1", RaceB: "r4", WinnerId: "r4"},
+		{Round: 1, RaceA: "r2", RaceB: "r3", WinnerId: "r2"},
+	}
+
+	next := NextBracketRound(round)
+	if len(next) != 1 || next[0].Round != 2 || next[0].RaceA != "r4" || next[0].RaceB != "r2" {
+		t.Errorf("unexpected next round %+v", next)
+	}
+	if NextBracketRound(next) != nil {
+		t.Errorf("expected no round after the final")
+	}
+}
+
+func TestCalculateStandings_Tiebreakers(t *testing.T) {
+	participants := newTestParticipants(3)
+	// every race wins once, the ship difference decides
+	matches := []*TournamentMatch{
+		{RaceA: "r1", RaceB: "r2", Played: true, WinnerId: "r1", ShipsA: 2, ShipsB: 2, SurvivorsA: 2},
+		{RaceA: "r2", RaceB: "r3", Played: true, WinnerId: "r2", ShipsA: 5, ShipsB: 5, SurvivorsA: 1},
+		{RaceA: "r3", RaceB: "r1", Played: true, WinnerId: "r3", ShipsA: 4, ShipsB: 1, SurvivorsA: 4},
+	}
+
+	standings := CalculateStandings(participants, matches)
+
+	// differences: r1 3-1=2, r3 5-5=0, r2 5-6=-1
+	for i, raceId := range []string{"r1", "r3", "r2"} {
+		if standings[i].RaceId != raceId || standings[i].Points != TOURNAMENT_POINTS_WIN {
+			t.Errorf("standing %d: got %+v, want %s", i, standings[i], raceId)
+		}
+	}
+}
+
+func TestCalculateStandings(t *testing.T) {
+	participants := newTestParticipants(3)
+	matches := []*TournamentMatch{
+		{RaceA: "r1", RaceB: "r2", Played: true, WinnerId: "r1", ShipsA: 3, ShipsB: 3, SurvivorsA: 1},
+		{RaceA: "r2", RaceB: "r3", Played: true, ShipsA: 3, ShipsB: 3, SurvivorsA: 2, SurvivorsB: 2},
+		{RaceA: "r3", RaceB: "r1", Played: true, ShipsA: 3, ShipsB: 3, SurvivorsA: 3, SurvivorsB: 3},
+		{RaceA: "r1", RaceB: "r3", Played: false},
+	}
+
+	standings := CalculateStandings(participants, matches)
+
+	want := []struct {
+		raceId                string
+		points, wins, losses  int
+		draws, shipDifference int
+	}{
+		{"r1", 4, 1, 0, 1, 1},
+		{"r3", 2, 0, 0, 2, 0},
+		{"r2", 1, 0, 1, 1, -1},
+	}
+	for i, w := range want {
+		s := standings[i]
+		if s.Rank != i+1 || s.RaceId != w.raceId || s.Points != w.points || s.Wins != w.wins || s.Losses != w.losses ||
+			s.Draws != w.draws || s.ShipDifference() != w.shipDifference {
+			t.Errorf("standing %d: got %+v, want %+v", i, s, w)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package galaxy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Order types. The orders are resolved in this order at the end of the turn.
+const (
+	ORDER_MOVE      = "move"      // deploy or move a fleet to a planet
+	ORDER_BUILD     = "build"     // build the fleet of a fleet build
+	ORDER_REINFORCE = "reinforce" // add the ships of a fleet build to the existing fleet
+	ORDER_RESEARCH  = "research"  // spend resources on a technology or research a tech tree node
+)
+
+var OrderTypes = []string{ORDER_MOVE, ORDER_BUILD, ORDER_REINFORCE, ORDER_RESEARCH}
+
+const (
+	ORDER_STATUS_PENDING = "pending"
+	ORDER_STATUS_DONE    = "done"
+	ORDER_STATUS_FAILED  = "failed"
+)
+
+// Order is submitted by a race during a turn and resolved when the turn ends.
+type Order struct {
+	ID         string `json:"id"`
+	DivisionId string `json:"division_id"`
+	RaceId     string `json:"race_id"`
+	Turn       int    `json:"turn"`
+	Type       string `json:"type"`
+
+	// move
+	FleetId  string `json:"fleet_id,omitempty"`
+	PlanetId string `json:"planet_id,omitempty"`
+	// build, research
+	FleetBuildId string `json:"fleet_build_id,omitempty"`
+	// research: technology name or tech tree node id
+	Technology string  `json:"technology,omitempty"`
+	Resources  float64 `json:"resources,omitempty"`
+
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (order *Order) Validate() error {
+	switch order.Type {
+	case ORDER_MOVE:
+		if order.FleetId == "" || order.PlanetId == "" {
+			return errors.New("move order requires fleet_id and planet_id")
+		}
+	case ORDER_BUILD, ORDER_REINFORCE:
+		if order.FleetBuildId == "" {
+			return fmt.Errorf("%s order requires fleet_build_id", order.Type)
+		}
+	case ORDER_RESEARCH:
+		if order.FleetBuildId == "" || order.Technology == "" {
+			return errors.New("research order requires fleet_build_id and technology")
+		}
+		if slices.Contains(TechnologyNames, order.Technology) && order.Resources <= 0 {
+			return errors.New("research order requires positive resources")
+		}
+	default:
+		return fmt.Errorf("unknown order type %q", order.Type)
+	}
+
+	return nil
+}
+
+// SortOrders sorts the orders into the resolution order: by type, then by race and id.
+func SortOrders(orders []*Order) {
+	slices.SortFunc(orders, func(a, b *Order) int {
+		if a.Type != b.Type {
+			return slices.Index(OrderTypes, a.Type) - slices.Index(OrderTypes, b.Type)
+		}
+		if a.RaceId != b.RaceId {
+			return strings.Compare(a.RaceId, b.RaceId)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// TurnState is the current turn of a division.
+type TurnState struct {
+	DivisionId string    `json:"division_id"`
+	Number     int       `json:"number"`
+	StartedAt  time.Time `json:"started_at"`
+	// nil when the turns of the division are advanced only manually
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+}
+
+// TurnReport is the archived result of a resolved turn.
+type TurnReport struct {
+	DivisionId string    `json:"division_id"`
+	Turn       int       `json:"turn"`
+	ResolvedAt time.Time `json:"resolved_at"`
+	MapTime    float64   `json:"map_time"`
+	Orders     []*Order  `json:"orders"`
+	Battles    []*Battle `json:"battles"`
+}
@@ -68,3 +68,32 @@ func (cf *ConfigurableFunction) CalculateRatio(numerator, denominator float64) f
 
 	return cf.Calculate(ratio)
 }
+
+// CalculateInverse finds x such that Calculate(x) == y.
+// Only functions with strictly increasing values can be inverted.
+func (cf *ConfigurableFunction) CalculateInverse(y float64) (float64, error) {
+	for i := 1; i < len(cf.values); i++ {
+		if cf.values[i] <= cf.values[i-1] {
+			return 0, fmt.Errorf("values must be strictly increasing to calculate the inverse")
+		}
+	}
+
+	if y < cf.values[0] || y > cf.values[len(cf.values)-1] {
+		return 0, fmt.Errorf("value %v is out of the function range [%v, %v]", y, cf.values[0], cf.values[len(cf.values)-1])
+	}
+
+	if y == cf.values[0] {
+		return cf.keys[0], nil
+	}
+
+	for i := 1; i < len(cf.values); i++ {
+		if y <= cf.values[i] {
+			deltaY := y - cf.values[i-1]
+			cotangent := (cf.keys[i] - cf.keys[i-1]) / (cf.values[i] - cf.values[i-1])
+
+			return cf.keys[i-1] + deltaY*cotangent, nil
+		}
+	}
+
+	return cf.keys[len(cf.keys)-1], nil
+}
@@ -182,3 +182,51 @@ func TestCalculateRatio(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateInverse(t *testing.T) {
+	cf, err := NewConfigurableFunction([]float64{0, 100, 300}, []float64{0, 1, 2})
+	if err != nil {
+		t.Fatalf("Failed to create ConfigurableFunction: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		y         float64
+		expected  float64
+		wantError bool
+	}{
+		{"start of range", 0, 0, false},
+		{"first segment", 0.5, 50, false},
+		{"key point", 1, 100, false},
+		{"second segment", 1.5, 200, false},
+		{"end of range", 2, 300, false},
+		{"below range", -1, 0, true},
+		{"above range", 2.5, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := cf.CalculateInverse(tt.y)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if math.Abs(x-tt.expected) > 1e-10 {
+				t.Errorf("got %v, want %v", x, tt.expected)
+			}
+			if math.Abs(cf.Calculate(x)-tt.y) > 1e-10 {
+				t.Errorf("Calculate(CalculateInverse(%v)) = %v", tt.y, cf.Calculate(x))
+			}
+		})
+	}
+
+	decreasing, _ := NewConfigurableFunction([]float64{0.25, 1, 4}, []float64{1, 0.5, 0})
+	if _, err := decreasing.CalculateInverse(0.5); err == nil {
+		t.Errorf("Expected error for decreasing function")
+	}
+}
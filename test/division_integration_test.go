@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"glaktika.eu/galaktika/internal/config"
 	"glaktika.eu/galaktika/internal/di"
 	"glaktika.eu/galaktika/pkg/galaxy"
 )
@@ -18,7 +19,11 @@ func setupTestServer() *httptest.Server {
 	router := gin.Default()
 
 	apiRoute := router.Group("/api")
-	di.CreateSingletons("test")
+	cfg := config.Default()
+	cfg.Environment = config.ENV_TEST
+	if err := di.CreateSingletons(cfg); err != nil {
+		panic(err)
+	}
 	di.RegisterRoutes(apiRoute)
 
 	return httptest.NewServer(router)
@@ -10,7 +10,8 @@ import (
 
 func createDivision(t *testing.T, baseURL string, id string) {
 	t.Helper()
-	resp, err := makeRequest("POST", baseURL+"/divisions", map[string]interface{}{"id": id, "name": id})
+	// the research of the fleet builds is paid from the division resources
+	resp, err := makeRequest("POST", baseURL+"/divisions", map[string]interface{}{"id": id, "name": id, "resources_amount": 2000})
 	if err != nil {
 		t.Fatalf("Failed to create division %s: %v", id, err)
 	}